@@ -2,22 +2,46 @@ package grpcmock
 
 import (
 	"context"
+	goErrors "errors"
 	"fmt"
+	"io"
 	"net"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/nhatthm/grpcmock/errors"
+	grpcReflect "github.com/nhatthm/grpcmock/reflect"
+	"github.com/nhatthm/grpcmock/service"
 	"github.com/nhatthm/grpcmock/stream"
 )
 
 var methodRegex = regexp.MustCompile(`/?[^/]+/[^/]+$`)
 
+// passthroughScheme is the prefix of the targets that are dialed without resolving the address.
+const passthroughScheme = "passthrough:///"
+
+var (
+	defaultInvokeOptions   []InvokeOption
+	defaultInvokeOptionsMu sync.RWMutex
+)
+
 // ContextDialer is to set up the dialer.
 type ContextDialer = func(context.Context, string) (net.Conn, error)
 
@@ -33,16 +57,263 @@ func (h ClientStreamHandler) Handle(s grpc.ClientStream) error {
 	return h(s)
 }
 
+// streamError wraps an error occurred while working with a stream with the method and the operation.
+type streamError struct {
+	method string
+	op     string
+	err    error
+}
+
+func newStreamError(method, op string, err error) error {
+	return &streamError{method: method, op: op, err: err}
+}
+
+// Error returns the error string.
+func (e *streamError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.method, e.op, e.err.Error())
+}
+
+// Unwrap returns the original error.
+func (e *streamError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus returns the status of the original error so that status.Code() and status.FromError() still work.
+func (e *streamError) GRPCStatus() *status.Status {
+	if st, ok := status.FromError(e.err); ok {
+		return st
+	}
+
+	if goErrors.Is(e.err, context.Canceled) || goErrors.Is(e.err, context.DeadlineExceeded) {
+		return status.FromContextError(e.err)
+	}
+
+	return status.New(codes.Unknown, e.Error())
+}
+
+// ErrorDetails returns the details of the status of the error, for example the *errdetails.BadRequest sent by the
+// server. It returns nil if the error does not carry a status.
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/CreateItem", in, out)
+//
+//    details, err := grpcmock.ErrorDetails(err)
+func ErrorDetails(err error) ([]proto.Message, error) {
+	st, ok := status.FromError(err)
+	if !ok || st == nil {
+		return nil, nil
+	}
+
+	result := make([]proto.Message, 0, len(st.Details()))
+
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case error:
+			return nil, d
+
+		case proto.Message:
+			result = append(result, d)
+		}
+	}
+
+	return result, nil
+}
+
+// HasCode tells whether the error has the given gRPC code. A nil error only has codes.OK.
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", in, out)
+//
+//    if grpcmock.HasCode(err, codes.NotFound) {
+//    	// The item does not exist.
+//    }
+func HasCode(err error, code codes.Code) bool {
+	return status.Code(err) == code
+}
+
+// AssertCode asserts that the error has the given gRPC code. A nil error only has codes.OK.
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", in, out)
+//
+//    grpcmock.AssertCode(t, err, codes.NotFound)
+func AssertCode(t assert.TestingT, err error, want codes.Code, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	if HasCode(err, want) {
+		return true
+	}
+
+	return assert.Fail(t, fmt.Sprintf("unexpected gRPC code, got %s, want %s, error: %v", status.Code(err), want, err), msgAndArgs...)
+}
+
+const (
+	// StreamErrorNone indicates that there is no error.
+	StreamErrorNone StreamErrorKind = ""
+	// StreamErrorEOF indicates that the stream ended with io.EOF.
+	StreamErrorEOF StreamErrorKind = "EOF"
+	// StreamErrorStatus indicates that the stream failed with a gRPC status.
+	StreamErrorStatus StreamErrorKind = "Status"
+	// StreamErrorContext indicates that the stream failed because the context was canceled or its deadline was exceeded.
+	StreamErrorContext StreamErrorKind = "Context"
+	// StreamErrorTransport indicates that the stream failed without a gRPC status, for example a network error.
+	StreamErrorTransport StreamErrorKind = "Transport"
+)
+
+// StreamErrorKind is the kind of error occurred while working with a stream.
+type StreamErrorKind string
+
+// ClassifyStreamError tells the kind of error returned by SendMsg() or RecvMsg() of a stream, so that a test could
+// branch on it instead of matching the error string. The Canceled and DeadlineExceeded statuses, which gRPC returns
+// when the context of the call is done, are classified as StreamErrorContext.
+//
+//    err := grpcmock.InvokeServerStream(ctx, "grpctest.ItemService/ListItems", in, grpcmock.RecvAll(&out))
+//
+//    if grpcmock.ClassifyStreamError(err) == grpcmock.StreamErrorContext {
+//    	// The call was canceled.
+//    }
+func ClassifyStreamError(err error) StreamErrorKind {
+	if err == nil {
+		return StreamErrorNone
+	}
+
+	// The stream error always has a status, classify the original error instead.
+	var se *streamError
+	if goErrors.As(err, &se) {
+		return ClassifyStreamError(se.err)
+	}
+
+	if goErrors.Is(err, io.EOF) {
+		return StreamErrorEOF
+	}
+
+	if goErrors.Is(err, context.Canceled) || goErrors.Is(err, context.DeadlineExceeded) {
+		return StreamErrorContext
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return StreamErrorTransport
+	}
+
+	switch st.Code() {
+	case codes.Canceled, codes.DeadlineExceeded:
+		return StreamErrorContext
+	}
+
+	return StreamErrorStatus
+}
+
+// insecurePerRPCCredentials allows sending the credentials over an insecure connection.
+type insecurePerRPCCredentials struct {
+	credentials.PerRPCCredentials
+}
+
+// RequireTransportSecurity satisfies credentials.PerRPCCredentials.
+func (insecurePerRPCCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
 type invokeConfig struct {
-	header   map[string]string
-	dialOpts []grpc.DialOption
-	callOpts []grpc.CallOption
+	header       map[string]string
+	appendHeader []string
+	dialOpts     []grpc.DialOption
+	callOpts     []grpc.CallOption
+	inputType    interface{}
+
+	streamHeader     *metadata.MD
+	typeCheckService interface{}
+	drainCheck       bool
+	streamDesc       *grpc.StreamDesc
+	messageTap       *messageTap
+	freshConnection  bool
+	idleTimeout      time.Duration
+
+	callOptionFuncs []func(attempt int) []grpc.CallOption
+	retryAttempts   int
+	retryCodes      []codes.Code
+
+	timing func(d time.Duration)
+	logger Logger
+
+	// codec is set by WithCodec() and contentSubtype is set by WithContentSubtype(), they are checked when the call is
+	// invoked.
+	codec          encoding.Codec
+	hasCodec       bool
+	contentSubtype string
+}
+
+// attemptCallOptions returns the call options of an attempt, the first attempt is 0.
+func (c invokeConfig) attemptCallOptions(attempt int) []grpc.CallOption {
+	if len(c.callOptionFuncs) == 0 && !c.hasCodec && c.contentSubtype == "" {
+		return c.callOpts
+	}
+
+	opts := make([]grpc.CallOption, 0, len(c.callOpts)+2)
+	opts = append(opts, c.callOpts...)
+
+	if c.contentSubtype != "" {
+		opts = append(opts, grpc.CallContentSubtype(c.contentSubtype))
+	}
+
+	if c.hasCodec {
+		opts = append(opts, grpc.ForceCodec(c.codec))
+	}
+
+	for _, fn := range c.callOptionFuncs {
+		opts = append(opts, fn(attempt)...)
+	}
+
+	return opts
+}
+
+// checkCodec checks whether the codecs set by WithCodec() and WithContentSubtype() are registered, so the server could
+// decode the request.
+func (c invokeConfig) checkCodec() error {
+	if c.contentSubtype != "" && encoding.GetCodec(strings.ToLower(c.contentSubtype)) == nil {
+		return fmt.Errorf("%w: %s", errors.ErrCodecNotRegistered, c.contentSubtype)
+	}
+
+	if !c.hasCodec {
+		return nil
+	}
+
+	if c.codec == nil {
+		return fmt.Errorf("%w: <nil>", errors.ErrCodecNotRegistered)
+	}
+
+	if encoding.GetCodec(strings.ToLower(c.codec.Name())) == nil {
+		return fmt.Errorf("%w: %s", errors.ErrCodecNotRegistered, c.codec.Name())
+	}
+
+	return nil
+}
+
+// shouldRetry checks whether the error of an attempt could be retried, the first attempt is 0.
+func (c invokeConfig) shouldRetry(attempt int, err error) bool {
+	if attempt+1 >= c.retryAttempts {
+		return false
+	}
+
+	code := status.Code(err)
+
+	for _, retryCode := range c.retryCodes {
+		if retryCode == code {
+			return true
+		}
+	}
+
+	return false
 }
 
 // InvokeOption sets invoker config.
 type InvokeOption func(c *invokeConfig)
 
-// InvokeUnary invokes a unary method.
+// InvokeUnary invokes a unary method. The out could be nil if the response is not needed, for example, when the method
+// returns google.protobuf.Empty. The response is then decoded into a zero value of the output type of the method if it
+// is known by WithTypeCheck(), or into a throwaway *emptypb.Empty otherwise. Passing an *emptypb.Empty as out is still
+// fine.
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/DeleteItem", in, nil)
 func InvokeUnary(
 	ctx context.Context,
 	method string,
@@ -50,74 +321,130 @@ func InvokeUnary(
 	out interface{},
 	opts ...InvokeOption,
 ) error {
-	ctx, conn, method, callOpts, err := prepInvoke(ctx, method, opts...)
+	addr, method, err := parseMethod(method)
 	if err != nil {
-		return err
+		return fmt.Errorf("coulld not parse method url: %w", err)
 	}
 
-	return conn.Invoke(ctx, method, in, out, callOpts...)
+	return withInvoker(ctx, addr, opts, func(i *Invoker) error {
+		return i.Unary(ctx, method, in, out)
+	})
 }
 
-// InvokeServerStream invokes a server-stream method.
-func InvokeServerStream(
+// InvokeUnaryMethod invokes a unary method using the address, the service and the method separately. The address is
+// used as the dial target as is, without parsing.
+//
+//    err := grpcmock.InvokeUnaryMethod(ctx, "localhost:9090", "grpctest.ItemService", "GetItem", in, out,
+//    	grpcmock.WithInsecure(),
+//    )
+func InvokeUnaryMethod(
 	ctx context.Context,
+	addr string,
+	service string,
 	method string,
 	in interface{},
-	handle ClientStreamHandler,
+	out interface{},
+	opts ...InvokeOption,
+) error {
+	fullMethod := fmt.Sprintf("/%s/%s", strings.Trim(service, "/"), strings.Trim(method, "/"))
+
+	return withInvoker(ctx, addr, opts, func(i *Invoker) error {
+		return i.Unary(ctx, fullMethod, in, out)
+	})
+}
+
+// InvokeUnaryJSON invokes a unary method with a JSON input. The input type must be provided by using WithInputType().
+//
+//    out := &grpctest.Item{}
+//
+//    err := grpcmock.InvokeUnaryJSON(ctx, "grpctest.ItemService/GetItem", `{"id": 42}`, out,
+//    	grpcmock.WithInputType(&grpctest.GetItemRequest{}),
+//    	grpcmock.WithInsecure(),
+//    )
+func InvokeUnaryJSON(
+	ctx context.Context,
+	method string,
+	inJSON string,
+	out interface{},
 	opts ...InvokeOption,
 ) error {
-	ctx, conn, method, callOpts, err := prepInvoke(ctx, method, opts...)
+	addr, method, err := parseMethod(method)
 	if err != nil {
-		return err
+		return fmt.Errorf("could not parse method url: %w", err)
 	}
 
-	desc := &grpc.StreamDesc{ServerStreams: true}
+	// The defaults are read once, so the input and the call see the same options.
+	defaults := getDefaultInvokeOptions()
 
-	s, err := conn.NewStream(ctx, desc, method, callOpts...)
+	in, err := newInputFromJSON(buildInvokeConfig(defaults, opts), inJSON)
 	if err != nil {
 		return err
 	}
 
-	if err := s.SendMsg(in); err != nil {
-		return err
-	}
+	return withInvokerDefaults(ctx, addr, defaults, opts, func(i *Invoker) error {
+		return i.Unary(ctx, method, in, out)
+	})
+}
 
-	if err := s.CloseSend(); err != nil {
-		return err
+// InvokeUnaryRaw invokes a unary method with a pre-marshaled input, the bytes are sent as is and the raw bytes of the
+// response are returned. It is handy for testing how the server handles a malformed input.
+//
+//    out, err := grpcmock.InvokeUnaryRaw(ctx, "grpctest.ItemService/GetItem", []byte{0xff, 0xff},
+//    	grpcmock.WithInsecure(),
+//    )
+//
+// See: WithRawBytesPayload().
+func InvokeUnaryRaw(
+	ctx context.Context,
+	method string,
+	in []byte,
+	opts ...InvokeOption,
+) ([]byte, error) {
+	var out []byte
+
+	opts = append(append(make([]InvokeOption, 0, len(opts)+1), opts...), WithRawBytesPayload())
+
+	if err := InvokeUnary(ctx, method, in, &out, opts...); err != nil {
+		return nil, err
 	}
 
-	return handle.Handle(s)
+	return out, nil
 }
 
-// InvokeClientStream invokes a client-stream method.
-func InvokeClientStream(
+// InvokeServerStream invokes a server-stream method.
+func InvokeServerStream(
 	ctx context.Context,
 	method string,
+	in interface{},
 	handle ClientStreamHandler,
-	out interface{},
 	opts ...InvokeOption,
 ) error {
-	ctx, conn, method, callOpts, err := prepInvoke(ctx, method, opts...)
+	addr, method, err := parseMethod(method)
 	if err != nil {
-		return err
+		return fmt.Errorf("coulld not parse method url: %w", err)
 	}
 
-	desc := &grpc.StreamDesc{ClientStreams: true}
+	return withInvoker(ctx, addr, opts, func(i *Invoker) error {
+		return i.ServerStream(ctx, method, in, handle)
+	})
+}
 
-	s, err := conn.NewStream(ctx, desc, method, callOpts...)
+// InvokeClientStream invokes a client-stream method.
+func InvokeClientStream(
+	ctx context.Context,
+	method string,
+	handle ClientStreamHandler,
+	out interface{},
+	opts ...InvokeOption,
+) error {
+	addr, method, err := parseMethod(method)
 	if err != nil {
-		return err
+		return fmt.Errorf("coulld not parse method url: %w", err)
 	}
 
-	if err := handle.Handle(s); err != nil {
-		return err
-	}
-
-	if err := s.CloseSend(); err != nil {
-		return err
-	}
-
-	return s.RecvMsg(out)
+	return withInvoker(ctx, addr, opts, func(i *Invoker) error {
+		return i.ClientStream(ctx, method, handle, out)
+	})
 }
 
 // InvokeBidirectionalStream invokes a bidirectional-stream method.
@@ -127,67 +454,292 @@ func InvokeBidirectionalStream(
 	handle ClientStreamHandler,
 	opts ...InvokeOption,
 ) error {
-	ctx, conn, method, callOpts, err := prepInvoke(ctx, method, opts...)
+	addr, method, err := parseMethod(method)
 	if err != nil {
-		return err
+		return fmt.Errorf("coulld not parse method url: %w", err)
+	}
+
+	return withInvoker(ctx, addr, opts, func(i *Invoker) error {
+		return i.Bidi(ctx, method, handle)
+	})
+}
+
+// parseMethod splits a method url into the address and the method, for example "localhost:9090/grpctest.ItemService/GetItem".
+// A target of the passthrough scheme is dialed as is.
+//
+// See: parsePassthroughMethod().
+func parseMethod(method string) (string, string, error) {
+	if strings.HasPrefix(method, passthroughScheme) {
+		return parsePassthroughMethod(method)
+	}
+
+	if !methodRegex.MatchString(method) {
+		return "", "", errors.ErrMalformedMethod
+	}
+
+	addr := methodRegex.ReplaceAllString(method, "")
+
+	method = normalizeMethod(strings.Replace(method, addr, "", 1))
+
+	return addr, method, nil
+}
+
+// parsePassthroughMethod parses a method url of the passthrough scheme, for example
+// "passthrough:///127.0.0.1:9090/grpctest.ItemService/GetItem". By convention, the last two segments of the path are the
+// service and the method, and the rest is the endpoint, which is passed to the dialer without resolving.
+func parsePassthroughMethod(method string) (string, string, error) {
+	endpoint := strings.TrimPrefix(method, passthroughScheme)
+
+	loc := methodRegex.FindStringIndex(endpoint)
+	if loc == nil || loc[0] == 0 {
+		return "", "", errors.ErrMalformedMethod
+	}
+
+	return passthroughScheme + endpoint[:loc[0]], normalizeMethod(endpoint[loc[0]:]), nil
+}
+
+// newUnaryOutput creates the output of a unary method when the caller does not provide one. The output type is known
+// only if the service is provided by WithTypeCheck(), otherwise the response is discarded into an *emptypb.Empty.
+func newUnaryOutput(cfg invokeConfig, method string) interface{} {
+	if cfg.typeCheckService != nil {
+		if m, err := findServiceMethod(cfg.typeCheckService, method); err == nil {
+			return grpcReflect.New(m.Output)
+		}
 	}
 
-	desc := &grpc.StreamDesc{
-		ClientStreams: true,
-		ServerStreams: true,
+	return &emptypb.Empty{}
+}
+
+// checkMethodTypes checks whether the input and the output match the method of the service provided by WithTypeCheck().
+func checkMethodTypes(cfg invokeConfig, method string, in, out interface{}) error {
+	if cfg.typeCheckService == nil {
+		return nil
 	}
 
-	s, err := conn.NewStream(ctx, desc, method, callOpts...)
+	m, err := findServiceMethod(cfg.typeCheckService, method)
 	if err != nil {
-		return err
+		if goErrors.Is(err, errors.ErrMethodNotFound) {
+			return err
+		}
+
+		// The error will be reported when preparing the invocation.
+		return nil // nolint: nilerr
+	}
+
+	method = m.FullName()
+
+	if grpcReflect.UnwrapType(in) != grpcReflect.UnwrapType(m.Input) {
+		return fmt.Errorf("%w: input of %s, got %T, want %T", errors.ErrTypeMismatch, method, in, m.Input)
+	}
+
+	if grpcReflect.UnwrapType(out) != grpcReflect.UnwrapType(m.Output) {
+		return fmt.Errorf("%w: output of %s, got %T, want %T", errors.ErrTypeMismatch, method, out, m.Output)
 	}
 
-	return handle.Handle(s)
+	return nil
 }
 
-func prepInvoke(ctx context.Context, method string, opts ...InvokeOption) (context.Context, *grpc.ClientConn, string, []grpc.CallOption, error) {
-	addr, method, err := parseMethod(method)
+// findServiceMethod finds a method, for example "grpctest.ItemService/CreateItems", in the service. Both the service
+// and the method must match, so a method of another service with the same name is not found.
+func findServiceMethod(svc interface{}, method string) (service.Method, error) {
+	_, method, err := parseMethod(method)
 	if err != nil {
-		return ctx, nil, "", nil, fmt.Errorf("coulld not parse method url: %w", err)
+		return service.Method{}, err
+	}
+
+	sep := strings.LastIndex(method, "/")
+	serviceName, methodName := strings.Trim(method[:sep], "/"), method[sep+1:]
+
+	if !isServiceOf(svc, serviceName) {
+		return service.Method{}, fmt.Errorf("%w: %s", errors.ErrMethodNotFound, method)
+	}
+
+	for _, m := range grpcReflect.FindServiceMethods(svc) {
+		if m.Name == methodName {
+			return service.Method{
+				ServiceName: serviceName,
+				MethodName:  m.Name,
+				MethodType:  service.ToType(m.IsClientStream, m.IsServerStream),
+				Input:       m.Input,
+				Output:      m.Output,
+			}, nil
+		}
+	}
+
+	return service.Method{}, fmt.Errorf("%w: %s", errors.ErrMethodNotFound, method)
+}
+
+// isServiceOf checks whether the server interface, for example grpctest.ItemServiceServer, is generated for the service,
+// for example "grpctest.ItemService". The package of the service is not known from the interface, so only the short
+// name is compared. An interface that does not follow the naming of the generated code matches every service.
+func isServiceOf(svc interface{}, serviceName string) bool {
+	typeName := grpcReflect.UnwrapType(svc).Name()
+
+	if !strings.HasSuffix(typeName, "Server") {
+		return true
+	}
+
+	return strings.TrimSuffix(typeName, "Server") == serviceName[strings.LastIndex(serviceName, ".")+1:]
+}
+
+// checkStreamDrained checks whether the stream is fully consumed if it is requested by WithDrainCheck().
+func checkStreamDrained(cfg invokeConfig, s grpc.ClientStream) error {
+	if !cfg.drainCheck {
+		return nil
+	}
+
+	// The message is discarded, so any message type is fine.
+	err := s.RecvMsg(&emptypb.Empty{})
+
+	if err == nil {
+		return errors.ErrStreamNotDrained
 	}
 
-	ctx, dialOpts, callOpts := invokeOptions(ctx, opts...)
+	if goErrors.Is(err, io.EOF) {
+		return nil
+	}
+
+	return err
+}
+
+// newStreamDesc creates a grpc.StreamDesc for the stream invokes, it is overridden by WithStreamDesc().
+func newStreamDesc(cfg invokeConfig, clientStreams, serverStreams bool) *grpc.StreamDesc {
+	desc := grpc.StreamDesc{}
+
+	if cfg.streamDesc != nil {
+		desc = *cfg.streamDesc
+	}
 
-	conn, err := grpc.DialContext(ctx, addr, dialOpts...)
+	desc.ClientStreams = desc.ClientStreams || clientStreams
+	desc.ServerStreams = desc.ServerStreams || serverStreams
+
+	return &desc
+}
+
+// captureStreamHeader reads the header metadata of the stream if it is requested by WithStreamHeaderCapture(). The
+// stream.Header() blocks until the server sends the header or the first response, so it must be called after all the
+// messages are sent and the send direction is closed.
+func captureStreamHeader(cfg invokeConfig, s grpc.ClientStream) error {
+	if cfg.streamHeader == nil {
+		return nil
+	}
+
+	md, err := s.Header()
 	if err != nil {
-		return ctx, nil, "", nil, err
+		return err
 	}
 
-	return ctx, conn, method, callOpts, err
+	*cfg.streamHeader = md
+
+	return nil
 }
 
-func parseMethod(method string) (string, string, error) {
-	if !methodRegex.MatchString(method) {
-		return "", "", errors.ErrMalformedMethod
+func newInputFromJSON(cfg invokeConfig, inJSON string) (proto.Message, error) {
+	if cfg.inputType == nil {
+		return nil, errors.ErrMissingInputType
 	}
 
-	addr := methodRegex.ReplaceAllString(method, "")
+	in, ok := grpcReflect.New(cfg.inputType).(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: got %T, want proto.Message", errors.ErrUnsupportedDataType, cfg.inputType)
+	}
 
-	method = strings.Replace(method, addr, "", 1)
-	method = fmt.Sprintf("/%s", strings.TrimLeft(method, "/"))
+	if err := protojson.Unmarshal([]byte(inJSON), in); err != nil {
+		return nil, fmt.Errorf("could not unmarshal input: %w", err)
+	}
 
-	return addr, method, nil
+	return in, nil
+}
+
+// DefaultInvokeOptions sets the options that are applied before the options of every invoke, so the explicit options
+// override the defaults. Calling it again replaces the defaults.
+//
+//    grpcmock.DefaultInvokeOptions(
+//    	grpcmock.WithBufConnDialer(buf),
+//    	grpcmock.WithInsecure(),
+//    )
+//    defer grpcmock.ResetDefaultInvokeOptions()
+//
+// See: ResetDefaultInvokeOptions().
+func DefaultInvokeOptions(opts ...InvokeOption) {
+	defaultInvokeOptionsMu.Lock()
+	defer defaultInvokeOptionsMu.Unlock()
+
+	defaultInvokeOptions = append([]InvokeOption(nil), opts...)
+}
+
+// ResetDefaultInvokeOptions removes the options set by DefaultInvokeOptions().
+func ResetDefaultInvokeOptions() {
+	DefaultInvokeOptions()
+}
+
+func getDefaultInvokeOptions() []InvokeOption {
+	defaultInvokeOptionsMu.RLock()
+	defer defaultInvokeOptionsMu.RUnlock()
+
+	return defaultInvokeOptions
+}
+
+func newInvokeConfig(opts ...InvokeOption) invokeConfig {
+	return buildInvokeConfig(getDefaultInvokeOptions(), opts)
 }
 
-func invokeOptions(ctx context.Context, opts ...InvokeOption) (context.Context, []grpc.DialOption, []grpc.CallOption) {
+// buildInvokeConfig applies the given defaults and then the options. The defaults are passed in, so the callers that
+// snapshot them once do not see a change made by DefaultInvokeOptions() in the middle of a call.
+func buildInvokeConfig(defaults, opts []InvokeOption) invokeConfig {
 	cfg := invokeConfig{
 		header: map[string]string{},
 	}
 
+	for _, o := range defaults {
+		o(&cfg)
+	}
+
 	for _, o := range opts {
 		o(&cfg)
 	}
 
-	if len(cfg.header) > 0 {
-		ctx = metadata.NewOutgoingContext(ctx, metadata.New(cfg.header))
+	return cfg
+}
+
+// startTiming starts measuring an invoke, the returned function stops measuring and reports the duration.
+func (c invokeConfig) startTiming() func() {
+	if c.timing == nil {
+		return func() {}
 	}
 
-	return ctx, cfg.dialOpts, cfg.callOpts
+	start := time.Now()
+
+	return func() {
+		c.timing(time.Since(start))
+	}
+}
+
+// logAttempt starts logging an attempt of a call, the returned function logs the result of the attempt. It does nothing
+// if there is no logger.
+func (c invokeConfig) logAttempt(method string, attempt int) func(err error) {
+	if c.logger == nil {
+		return func(error) {}
+	}
+
+	start := time.Now()
+
+	return func(err error) {
+		c.logger.Log("method", method, "attempt", attempt, "code", status.Code(err).String(), "duration", time.Since(start))
+	}
+}
+
+// outgoingContext puts the headers into the outgoing metadata of the context.
+func (c invokeConfig) outgoingContext(ctx context.Context) context.Context {
+	if len(c.header) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(c.header))
+	}
+
+	if len(c.appendHeader) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, c.appendHeader...)
+	}
+
+	return ctx
 }
 
 // WithHeader sets request header.
@@ -206,6 +758,22 @@ func WithHeaders(header map[string]string) InvokeOption {
 	}
 }
 
+// WithAppendHeaders appends the headers to the outgoing metadata of the context. Unlike WithHeaders(), the metadata
+// that is already in the context is kept.
+//
+//    ctx = metadata.AppendToOutgoingContext(ctx, "x-request-id", "42")
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", in, out,
+//    	grpcmock.WithAppendHeaders(map[string]string{"locale": "en-US"}),
+//    )
+func WithAppendHeaders(header map[string]string) InvokeOption {
+	return func(c *invokeConfig) {
+		for k, v := range header {
+			c.appendHeader = append(c.appendHeader, k, v)
+		}
+	}
+}
+
 // WithContextDialer sets a context dialer to create connections.
 //
 // See:
@@ -224,11 +792,156 @@ func WithBufConnDialer(l *bufconn.Listener) InvokeOption {
 	})
 }
 
+// WithResolver uses a resolver for the connection, so the address could use the scheme of the resolver, for example
+// "manual:///mocks/grpctest.ItemService/GetItem". The resolver is only used by the connection, it is not registered
+// globally.
+//
+//    r := manual.NewBuilderWithScheme("mocks")
+//    r.InitialState(resolver.State{Addresses: []resolver.Address{{Addr: srv1.Address()}, {Addr: srv2.Address()}}})
+//
+//    i, err := grpcmock.NewInvoker("mocks:///items",
+//    	grpcmock.WithResolver(r),
+//    	grpcmock.WithBalancer(roundrobin.Name),
+//    	grpcmock.WithInsecure(),
+//    )
+//
+// See: WithBalancer().
+func WithResolver(builder resolver.Builder) InvokeOption {
+	return WithDialOptions(grpc.WithResolvers(builder))
+}
+
+// WithBalancer sets the load balancing policy of the connection, for example "round_robin" or "pick_first".
+//
+// See: WithResolver().
+func WithBalancer(policy string) InvokeOption {
+	return WithDialOptions(grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig": [{%q: {}}]}`, policy)))
+}
+
 // WithInsecure disables transport security for the connections.
 func WithInsecure() InvokeOption {
 	return WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials()))
 }
 
+// WithPerRPCCredentials sets the credentials that attach security information to every call, for example an oauth
+// token.
+//
+// See: WithInsecurePerRPCCredentials().
+func WithPerRPCCredentials(creds credentials.PerRPCCredentials) InvokeOption {
+	return WithDialOptions(grpc.WithPerRPCCredentials(creds))
+}
+
+// WithInsecurePerRPCCredentials is the same as WithPerRPCCredentials() but the credentials are also sent over an
+// insecure connection, which is handy for testing.
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", in, out,
+//    	grpcmock.WithInsecure(),
+//    	grpcmock.WithInsecurePerRPCCredentials(oauth.NewOauthAccess(token)),
+//    )
+func WithInsecurePerRPCCredentials(creds credentials.PerRPCCredentials) InvokeOption {
+	return WithPerRPCCredentials(insecurePerRPCCredentials{PerRPCCredentials: creds})
+}
+
+// WithAuthority sets the authority of the calls, which is sent as the ":authority" pseudo-header.
+func WithAuthority(authority string) InvokeOption {
+	return WithDialOptions(grpc.WithAuthority(authority))
+}
+
+// WithInitialWindowSize sets the initial flow-control window size of a stream. The values below 64KB are ignored by
+// gRPC.
+//
+// See: WithInitialConnWindowSize(), InitialWindowSize().
+func WithInitialWindowSize(n int32) InvokeOption {
+	return WithDialOptions(grpc.WithInitialWindowSize(n))
+}
+
+// WithInitialConnWindowSize sets the initial flow-control window size of a connection. The values below 64KB are
+// ignored by gRPC.
+//
+// See: WithInitialWindowSize(), InitialConnWindowSize().
+func WithInitialConnWindowSize(n int32) InvokeOption {
+	return WithDialOptions(grpc.WithInitialConnWindowSize(n))
+}
+
+// WithGzip compresses the requests with gzip.
+func WithGzip() InvokeOption {
+	return WithCallOptions(grpc.UseCompressor(gzip.Name))
+}
+
+// WithRawBytesPayload sends the []byte inputs as is, without marshaling, which is handy for sending payloads that are
+// not proto messages.
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", []byte("opaque blob"), out,
+//    	grpcmock.WithRawBytesPayload(),
+//    )
+//
+// See: WithRawBytesCodec().
+func WithRawBytesPayload() InvokeOption {
+	return WithCallOptions(grpc.ForceCodec(rawBytesCodec{}))
+}
+
+// WithFreshConnection dials a new connection for the call, even when the Invoker holds one, and closes it after the
+// call. This is useful for testing the dial-time behaviors, such as the resolver or the handshake, but every call pays
+// the cost of dialing, so it should not be used when the connection is irrelevant. The dial options of the call are
+// used for dialing.
+//
+//    err := i.Unary(ctx, "grpctest.ItemService/GetItem", in, out, grpcmock.WithFreshConnection())
+//
+// See: NewInvoker().
+func WithFreshConnection() InvokeOption {
+	return func(c *invokeConfig) {
+		c.freshConnection = true
+	}
+}
+
+// WithContentSubtype sets the content-subtype of the call, for example "json" for "application/grpc+json". The messages
+// are encoded with the codec registered for the subtype, so the codec must be registered with encoding.RegisterCodec(),
+// otherwise the call returns errors.ErrCodecNotRegistered.
+//
+//    encoding.RegisterCodec(jsonCodec{})
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", in, out,
+//    	grpcmock.WithContentSubtype("json"),
+//    )
+//
+// See: WithCodec().
+func WithContentSubtype(subtype string) InvokeOption {
+	return func(c *invokeConfig) {
+		c.contentSubtype = subtype
+	}
+}
+
+// WithIdleTimeout re-dials the connection of the Invoker before a call if the connection has been idle longer than the
+// timeout, so a long test suite with idle gaps does not use a stale connection. The connection is not re-dialed while
+// there is an active call. The free functions ignore the option because they dial a connection for every call.
+//
+//    i, err := grpcmock.NewInvoker("localhost:9090",
+//    	grpcmock.WithIdleTimeout(time.Minute),
+//    	grpcmock.WithInsecure(),
+//    )
+//
+// See: NewInvoker(), WithFreshConnection().
+func WithIdleTimeout(d time.Duration) InvokeOption {
+	return func(c *invokeConfig) {
+		c.idleTimeout = d
+	}
+}
+
+// WithCodec encodes the messages of the call with the codec, the name of the codec is sent as the content-subtype, so
+// the server uses the same codec for decoding the request and encoding the response. The codec must be registered with
+// encoding.RegisterCodec() for the server to find it, otherwise the call returns errors.ErrCodecNotRegistered.
+//
+//    encoding.RegisterCodec(myCodec{})
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", in, out,
+//    	grpcmock.WithCodec(myCodec{}),
+//    )
+func WithCodec(codec encoding.Codec) InvokeOption {
+	return func(c *invokeConfig) {
+		c.codec = codec
+		c.hasCodec = true
+	}
+}
+
 // WithDialOptions sets dial options.
 func WithDialOptions(opts ...grpc.DialOption) InvokeOption {
 	return func(c *invokeConfig) {
@@ -243,6 +956,148 @@ func WithCallOptions(opts ...grpc.CallOption) InvokeOption {
 	}
 }
 
+// WithCallOptionFunc sets the call options that vary per attempt, the first attempt is 0. The options are added after
+// the ones set by WithCallOptions(). The streams are not retried, so they always use the options of the first attempt.
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", in, out,
+//    	grpcmock.WithRetry(3),
+//    	grpcmock.WithCallOptionFunc(func(attempt int) []grpc.CallOption {
+//    		return []grpc.CallOption{grpc.MaxCallRecvMsgSize((attempt + 1) * 4 << 20)}
+//    	}),
+//    )
+//
+// See: WithRetry().
+func WithCallOptionFunc(fn func(attempt int) []grpc.CallOption) InvokeOption {
+	return func(c *invokeConfig) {
+		c.callOptionFuncs = append(c.callOptionFuncs, fn)
+	}
+}
+
+// WithRetry retries a unary call until it succeeds or the number of attempts, including the first one, is reached. Only
+// the errors with the given codes are retried, codes.Unavailable is retried if no code is given. The call is not retried
+// once the context is done.
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", in, out,
+//    	grpcmock.WithRetry(3, codes.Unavailable, codes.ResourceExhausted),
+//    )
+//
+// See: WithCallOptionFunc().
+func WithRetry(attempts int, retryCodes ...codes.Code) InvokeOption {
+	if len(retryCodes) == 0 {
+		retryCodes = []codes.Code{codes.Unavailable}
+	}
+
+	return func(c *invokeConfig) {
+		c.retryAttempts = attempts
+		c.retryCodes = retryCodes
+	}
+}
+
+// WithHeaderCapture captures the header metadata sent by the server in InvokeUnary(). For the streams, use
+// WithStreamHeaderCapture() instead.
+//
+//    var header metadata.MD
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", in, out,
+//    	grpcmock.WithHeaderCapture(&header),
+//    )
+func WithHeaderCapture(md *metadata.MD) InvokeOption {
+	return WithCallOptions(grpc.Header(md))
+}
+
+// WithTrailerCapture captures the trailer metadata sent by the server. For the streams, the trailer is available after
+// the stream is finished.
+func WithTrailerCapture(md *metadata.MD) InvokeOption {
+	return WithCallOptions(grpc.Trailer(md))
+}
+
+// WithInputType sets the input type for decoding the JSON input.
+//
+// See:
+// 	- grpcmock.InvokeUnaryJSON()
+func WithInputType(v interface{}) InvokeOption {
+	return func(c *invokeConfig) {
+		c.inputType = v
+	}
+}
+
+// WithStreamHeaderCapture captures the header metadata sent by the server in InvokeServerStream() and
+// InvokeClientStream().
+//
+//    var header metadata.MD
+//
+//    err := grpcmock.InvokeServerStream(ctx, "grpctest.ItemService/ListItems", in, grpcmock.RecvAll(&out),
+//    	grpcmock.WithStreamHeaderCapture(&header),
+//    	grpcmock.WithInsecure(),
+//    )
+func WithStreamHeaderCapture(md *metadata.MD) InvokeOption {
+	return func(c *invokeConfig) {
+		c.streamHeader = md
+	}
+}
+
+// WithDrainCheck checks whether the handler of InvokeServerStream() reads all the messages, it returns
+// errors.ErrStreamNotDrained if there is still a message in the stream.
+func WithDrainCheck() InvokeOption {
+	return func(c *invokeConfig) {
+		c.drainCheck = true
+	}
+}
+
+// WithTiming measures how long an invoke takes and calls fn with the duration. For a unary method, it measures the call
+// including the retries, for a stream, it measures the whole lifetime of the stream until the handler returns.
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", in, out,
+//    	grpcmock.WithTiming(func(d time.Duration) {
+//    		t.Logf("GetItem took %s", d)
+//    	}),
+//    )
+func WithTiming(fn func(d time.Duration)) InvokeOption {
+	return func(c *invokeConfig) {
+		c.timing = fn
+	}
+}
+
+// WithLogger logs every attempt of an invoke with the method, the attempt, the status code and the duration. A stream
+// is logged once when the handler returns.
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", in, out,
+//    	grpcmock.WithLogger(logger),
+//    )
+//
+// See: WithServerLogger().
+func WithLogger(l Logger) InvokeOption {
+	return func(c *invokeConfig) {
+		c.logger = l
+	}
+}
+
+// WithStreamDesc overrides the grpc.StreamDesc of the stream invokes. The ServerStreams and ClientStreams flags that
+// are required by the method type are always set, so only the other fields, for example StreamName, take effect.
+// Misusing it could break the RPC.
+//
+//    err := grpcmock.InvokeServerStream(ctx, "grpctest.ItemService/ListItems", in, grpcmock.RecvAll(&out),
+//    	grpcmock.WithStreamDesc(&grpc.StreamDesc{StreamName: "ListItems"}),
+//    )
+func WithStreamDesc(desc *grpc.StreamDesc) InvokeOption {
+	return func(c *invokeConfig) {
+		c.streamDesc = desc
+	}
+}
+
+// WithTypeCheck checks the input and the output of InvokeUnary() against the method of the given service before
+// dialing. It returns errors.ErrTypeMismatch if the types do not match.
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", in, out,
+//    	grpcmock.WithTypeCheck((*grpctest.ItemServiceServer)(nil)),
+//    	grpcmock.WithInsecure(),
+//    )
+func WithTypeCheck(svc interface{}) InvokeOption {
+	return func(c *invokeConfig) {
+		c.typeCheckService = svc
+	}
+}
+
 // SendAll sends everything to the stream.
 func SendAll(in interface{}) ClientStreamHandler {
 	return func(s grpc.ClientStream) error {
@@ -250,14 +1105,92 @@ func SendAll(in interface{}) ClientStreamHandler {
 	}
 }
 
-// RecvAll reads everything from the stream and put into the output.
+// SendAllOf sends everything to the stream, like SendAll(). Before sending, it finds the method in the service and
+// checks that every message matches the input type of the method, it returns stream.ErrSendTypeMismatch if one does not.
+//
+//    err := grpcmock.InvokeClientStream(ctx, "grpctest.ItemService/CreateItems",
+//    	grpcmock.SendAllOf((*grpctest.ItemServiceServer)(nil), "grpctest.ItemService/CreateItems", items),
+//    	out,
+//    	grpcmock.WithInsecure(),
+//    )
+func SendAllOf(svc interface{}, method string, in interface{}) ClientStreamHandler {
+	return func(s grpc.ClientStream) error {
+		m, err := findServiceMethod(svc, method)
+		if err != nil {
+			return err
+		}
+
+		return stream.SendAllOf(s, m, in)
+	}
+}
+
+// SendAllStrict sends everything to the stream. Unlike SendAll(), it keeps sending the rest of the messages if one of
+// them fails, and returns all the errors.
+func SendAllStrict(in interface{}) ClientStreamHandler {
+	return func(s grpc.ClientStream) error {
+		return stream.SendAllStrict(s, in)
+	}
+}
+
+// SendAllT sends everything of type T to the stream.
+//
+//    err := grpcmock.InvokeClientStream(ctx, "grpctest.ItemService/CreateItems",
+//    	grpcmock.SendAllT([]*grpctest.Item{{Id: 42}}),
+//    	out,
+//    	grpcmock.WithInsecure(),
+//    )
+func SendAllT[T any](in []T) ClientStreamHandler {
+	return func(s grpc.ClientStream) error {
+		return stream.SendAllT(s, in)
+	}
+}
+
+// SendAllWithDelay sends everything to the stream and waits for a duration between each message.
+func SendAllWithDelay(in interface{}, delay time.Duration) ClientStreamHandler {
+	return func(s grpc.ClientStream) error {
+		return stream.SendAllWithDelay(s.Context(), s, in, delay)
+	}
+}
+
+// RecvAll reads everything from the stream and put into the output. It stops and returns the context error when the
+// context of the invocation is done.
 func RecvAll(out interface{}) ClientStreamHandler {
 	return func(s grpc.ClientStream) error {
-		return stream.RecvAll(s, out)
+		return stream.RecvAllWithContext(s.Context(), s, out)
 	}
 }
 
-// SendAndRecvAll sends and receives messages to and from grpc server in turn until server sends the io.EOF.
+// RecvAllT reads everything of type T from the stream. It returns the handler and a pointer to the slice that holds the
+// received messages once the handler finishes. Like RecvAll(), the messages received before an error are still kept.
+//
+//    handler, out := grpcmock.RecvAllT[grpctest.Item]()
+//
+//    err := grpcmock.InvokeServerStream(ctx, "grpctest.ItemService/ListItems", in, handler, grpcmock.WithInsecure())
+//
+//    // *out is a []*grpctest.Item.
+func RecvAllT[T any]() (ClientStreamHandler, *[]*T) {
+	out := make([]*T, 0)
+
+	return func(s grpc.ClientStream) error {
+		msgs, err := stream.RecvAllT[T](s)
+
+		out = msgs
+
+		return err
+	}, &out
+}
+
+// SendAndRecvAll sends the messages from the input slice and receives the messages into the output slice concurrently.
+// The stream is closed for sending once all the messages are sent, and the messages are received until the server sends
+// the io.EOF. The first error of either direction is returned, so the status of the server is returned even if it ends
+// the stream while the client is still sending.
+//
+//    out := make([]*grpctest.Item, 0)
+//
+//    err := grpcmock.InvokeBidirectionalStream(ctx, "grpctest.ItemService/TransformItems",
+//    	grpcmock.SendAndRecvAll(in, &out),
+//    	grpcmock.WithInsecure(),
+//    )
 func SendAndRecvAll(in interface{}, out interface{}) ClientStreamHandler {
 	return func(s grpc.ClientStream) error {
 		return stream.SendAndRecvAll(s, in, out)
@@ -0,0 +1,153 @@
+package grpcmock
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	goErrors "errors"
+	"fmt"
+	"io"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/nhatthm/grpcmock/errors"
+)
+
+// RecordStreamToFile reads everything of type T from the stream and writes it to a file, so that the output of a stream
+// could be compared with a golden file. The messages are written in protojson, each is prefixed by its length as a
+// varint. The JSON is compacted, so the same messages are always written the same way.
+//
+//    err := grpcmock.InvokeServerStream(ctx, "grpctest.ItemService/ListItems", in,
+//    	grpcmock.RecordStreamToFile[grpctest.Item]("resources/fixtures/items.golden"),
+//    	grpcmock.WithInsecure(),
+//    )
+//
+// See: ReplayStreamFromFile().
+func RecordStreamToFile[T any](path string) ClientStreamHandler {
+	return func(s grpc.ClientStream) (err error) {
+		if _, ok := interface{}(new(T)).(proto.Message); !ok {
+			return fmt.Errorf("%w: got %T, want proto.Message", errors.ErrUnsupportedDataType, new(T))
+		}
+
+		f, err := os.Create(path) // nolint: gosec
+		if err != nil {
+			return fmt.Errorf("could not create file: %w", err)
+		}
+
+		defer func() {
+			if closeErr := f.Close(); err == nil && closeErr != nil {
+				err = fmt.Errorf("could not close file: %w", closeErr)
+			}
+		}()
+
+		w := bufio.NewWriter(f)
+
+		for i := 0; ; i++ {
+			msg := interface{}(new(T)).(proto.Message) // nolint: errcheck
+
+			if err := s.RecvMsg(msg); err != nil {
+				if goErrors.Is(err, io.EOF) {
+					break
+				}
+
+				return err
+			}
+
+			if err := writeDelimitedJSON(w, msg); err != nil {
+				return fmt.Errorf("could not write message #%d: %w", i, err)
+			}
+		}
+
+		return w.Flush()
+	}
+}
+
+// ReplayStreamFromFile reads the messages of type T from a file that is written by RecordStreamToFile() and sends them
+// to the stream.
+//
+//    err := grpcmock.InvokeClientStream(ctx, "grpctest.ItemService/CreateItems",
+//    	grpcmock.ReplayStreamFromFile[grpctest.Item]("resources/fixtures/items.golden"),
+//    	out,
+//    	grpcmock.WithInsecure(),
+//    )
+//
+// See: RecordStreamToFile().
+func ReplayStreamFromFile[T any](path string) ClientStreamHandler {
+	return func(s grpc.ClientStream) error {
+		if _, ok := interface{}(new(T)).(proto.Message); !ok {
+			return fmt.Errorf("%w: got %T, want proto.Message", errors.ErrUnsupportedDataType, new(T))
+		}
+
+		f, err := os.Open(path) // nolint: gosec
+		if err != nil {
+			return fmt.Errorf("could not open file: %w", err)
+		}
+
+		defer f.Close() // nolint: errcheck
+
+		r := bufio.NewReader(f)
+
+		for i := 0; ; i++ {
+			msg := interface{}(new(T)).(proto.Message) // nolint: errcheck
+
+			if err := readDelimitedJSON(r, msg); err != nil {
+				if goErrors.Is(err, io.EOF) {
+					return nil
+				}
+
+				return fmt.Errorf("could not read message #%d: %w", i, err)
+			}
+
+			if err := s.SendMsg(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeDelimitedJSON(w io.Writer, msg proto.Message) error {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	// protojson randomizes the whitespaces, compacting removes them.
+	var buf bytes.Buffer
+
+	if err := json.Compact(&buf, data); err != nil {
+		return err
+	}
+
+	size := make([]byte, binary.MaxVarintLen64)
+
+	if _, err := w.Write(size[:binary.PutUvarint(size, uint64(buf.Len()))]); err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf.Bytes())
+
+	return err
+}
+
+func readDelimitedJSON(r *bufio.Reader, msg proto.Message) error {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return err
+	}
+
+	data := make([]byte, size)
+
+	if _, err := io.ReadFull(r, data); err != nil {
+		if goErrors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+
+		return err
+	}
+
+	return protojson.Unmarshal(data, msg)
+}
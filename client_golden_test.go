@@ -0,0 +1,124 @@
+package grpcmock_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nhatthm/grpcmock"
+	grpcAssert "github.com/nhatthm/grpcmock/assert"
+	"github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestRecordStreamToFile_ReplayStreamFromFile(t *testing.T) {
+	t.Parallel()
+
+	items := []*grpctest.Item{
+		{Id: 41, Name: "Item #41"},
+		{Id: 42, Name: "Item #42", Locale: "en-US"},
+	}
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectServerStream(grpcTestServiceListItems).
+			Twice().
+			Return(items)
+
+		s.ExpectClientStream(grpcTestServiceCreateItems).
+			WithPayloads(matcher.SequenceEq(items[0], items[1])).
+			Return(&grpctest.CreateItemsResponse{NumItems: 2})
+	})
+
+	record := func(path string) {
+		err := grpcmock.InvokeServerStream(context.Background(), grpcTestServiceListItems,
+			&grpctest.ListItemsRequest{},
+			grpcmock.RecordStreamToFile[grpctest.Item](path),
+			grpcmock.WithContextDialer(d),
+			grpcmock.WithInsecure(),
+		)
+
+		require.NoError(t, err)
+	}
+
+	dir := t.TempDir()
+	golden := filepath.Join(dir, "items.golden")
+	actual := filepath.Join(dir, "items.actual")
+
+	record(golden)
+	record(actual)
+
+	// The same stream is always recorded the same way.
+	expectedData, err := os.ReadFile(filepath.Clean(golden))
+	require.NoError(t, err)
+
+	actualData, err := os.ReadFile(filepath.Clean(actual))
+	require.NoError(t, err)
+
+	assert.Equal(t, expectedData, actualData)
+
+	out := &grpctest.CreateItemsResponse{}
+	err = grpcmock.InvokeClientStream(context.Background(), grpcTestServiceCreateItems,
+		grpcmock.ReplayStreamFromFile[grpctest.Item](golden),
+		out,
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	require.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.CreateItemsResponse{NumItems: 2}, out)
+}
+
+func TestRecordStreamToFile_NotProtoMessage(t *testing.T) {
+	t.Parallel()
+
+	err := grpcmock.RecordStreamToFile[string](filepath.Join(t.TempDir(), "out.golden"))(nil)
+
+	assert.EqualError(t, err, "unsupported data type: got *string, want proto.Message")
+}
+
+func TestReplayStreamFromFile_Error(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	truncated := filepath.Join(dir, "truncated.golden")
+
+	// The record says 10 bytes, but there are only 2.
+	require.NoError(t, os.WriteFile(truncated, []byte{10, '{', '}'}, 0o600))
+
+	testCases := []struct {
+		scenario      string
+		handler       grpcmock.ClientStreamHandler
+		expectedError string
+	}{
+		{
+			scenario:      "not a proto message",
+			handler:       grpcmock.ReplayStreamFromFile[string](truncated),
+			expectedError: "unsupported data type: got *string, want proto.Message",
+		},
+		{
+			scenario:      "file not found",
+			handler:       grpcmock.ReplayStreamFromFile[grpctest.Item](filepath.Join(dir, "unknown.golden")),
+			expectedError: "could not open file: open " + filepath.Join(dir, "unknown.golden") + ": no such file or directory",
+		},
+		{
+			scenario:      "truncated record",
+			handler:       grpcmock.ReplayStreamFromFile[grpctest.Item](truncated),
+			expectedError: "could not read message #0: unexpected EOF",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.handler(nil)
+
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
@@ -69,6 +69,28 @@ func TestParseMethod(t *testing.T) {
 			expectedAddr:   "localhost:9090",
 			expectedMethod: "/server/GetItem",
 		},
+		{
+			scenario:       "passthrough with ip and port",
+			method:         "passthrough:///127.0.0.1:50051/grpctest.ItemService/GetItem",
+			expectedAddr:   "passthrough:///127.0.0.1:50051",
+			expectedMethod: "/grpctest.ItemService/GetItem",
+		},
+		{
+			scenario:       "passthrough with hostname",
+			method:         "passthrough:///bufnet/grpctest.ItemService/GetItem",
+			expectedAddr:   "passthrough:///bufnet",
+			expectedMethod: "/grpctest.ItemService/GetItem",
+		},
+		{
+			scenario:      "passthrough without endpoint",
+			method:        "passthrough:///grpctest.ItemService/GetItem",
+			expectedError: "malformed method",
+		},
+		{
+			scenario:      "passthrough without method",
+			method:        "passthrough:///127.0.0.1:50051",
+			expectedError: "malformed method",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -89,3 +111,67 @@ func TestParseMethod(t *testing.T) {
 		})
 	}
 }
+
+func TestMethodPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario       string
+		method         string
+		expectedMethod string
+		expectedError  string
+	}{
+		{
+			scenario:       "valid",
+			method:         "grpctest.ItemService/GetItem",
+			expectedMethod: "/grpctest.ItemService/GetItem",
+		},
+		{
+			scenario:       "valid with leading slashes",
+			method:         "//grpctest.ItemService/GetItem",
+			expectedMethod: "/grpctest.ItemService/GetItem",
+		},
+		{
+			scenario:      "empty",
+			method:        "",
+			expectedError: `invalid method path: want "service/method", got "/"`,
+		},
+		{
+			scenario:      "single segment",
+			method:        "/GetItem",
+			expectedError: `invalid method path: want "service/method", got "/GetItem"`,
+		},
+		{
+			scenario:      "triple segments",
+			method:        "localhost/grpctest.ItemService/GetItem",
+			expectedError: `invalid method path: want "service/method", got "/localhost/grpctest.ItemService/GetItem"`,
+		},
+		{
+			scenario:      "trailing slash",
+			method:        "//GetItem/",
+			expectedError: `invalid method path: want "service/method", got "/GetItem/"`,
+		},
+		{
+			scenario:      "empty method",
+			method:        "grpctest.ItemService/",
+			expectedError: `invalid method path: want "service/method", got "/grpctest.ItemService/"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			method, err := methodPath(tc.method)
+
+			assert.Equal(t, tc.expectedMethod, method)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
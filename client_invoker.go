@@ -0,0 +1,391 @@
+package grpcmock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/nhatthm/grpcmock/errors"
+	grpcReflect "github.com/nhatthm/grpcmock/reflect"
+)
+
+// Invoker invokes grpc methods using a persistent connection, so the connection is not dialed for every call.
+//
+//    i, err := grpcmock.NewInvoker("localhost:9090", grpcmock.WithInsecure())
+//    if err != nil {
+//    	return err
+//    }
+//
+//    defer i.Close() // nolint: errcheck
+//
+//    err = i.Unary(ctx, "grpctest.ItemService/GetItem", in, out)
+type Invoker struct {
+	addr     string
+	conn     *grpc.ClientConn
+	opts     []InvokeOption
+	dialOpts []grpc.DialOption
+
+	// defaults is the snapshot of DefaultInvokeOptions() taken when the invoker is created.
+	defaults []InvokeOption
+
+	// fresh is true when the connection is dialed for a single call.
+	fresh bool
+
+	// mu guards the connection, the last use and the number of active calls when the connection could be re-dialed
+	// after an idle period.
+	mu       sync.Mutex
+	lastUsed time.Time
+	active   int
+}
+
+// NewInvoker creates a new Invoker and dials the address. The options are applied to every call, the dial options are
+// only used for dialing. The options set by DefaultInvokeOptions() are read once, when the invoker is created.
+func NewInvoker(addr string, opts ...InvokeOption) (*Invoker, error) {
+	return newInvoker(context.Background(), addr, getDefaultInvokeOptions(), opts...)
+}
+
+func newInvoker(ctx context.Context, addr string, defaults []InvokeOption, opts ...InvokeOption) (*Invoker, error) {
+	cfg := buildInvokeConfig(defaults, opts)
+
+	conn, err := grpc.DialContext(ctx, addr, cfg.dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Invoker{
+		addr:     addr,
+		conn:     conn,
+		opts:     opts,
+		dialOpts: cfg.dialOpts,
+		defaults: defaults,
+		lastUsed: time.Now(),
+	}, nil
+}
+
+// withInvoker creates a throwaway Invoker for the free functions.
+func withInvoker(ctx context.Context, addr string, opts []InvokeOption, fn func(i *Invoker) error) error {
+	return withInvokerDefaults(ctx, addr, getDefaultInvokeOptions(), opts, fn)
+}
+
+// withInvokerDefaults creates a throwaway Invoker with the defaults that were already read by the caller.
+func withInvokerDefaults(
+	ctx context.Context,
+	addr string,
+	defaults, opts []InvokeOption,
+	fn func(i *Invoker) error,
+) error {
+	i, err := newInvoker(ctx, addr, defaults, opts...)
+	if err != nil {
+		return err
+	}
+
+	defer i.Close() // nolint: errcheck
+
+	i.fresh = true
+
+	return fn(i)
+}
+
+// Close closes the connection.
+func (i *Invoker) Close() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.conn.Close()
+}
+
+// Unary invokes a unary method. The dial options in opts are ignored, unless the call asks for a fresh connection. The
+// out could be nil if the response is not needed.
+//
+// See: InvokeUnary().
+//
+//    err := i.Unary(ctx, "grpctest.ItemService/GetItem", in, out, grpcmock.WithHeader("locale", "en-US"))
+func (i *Invoker) Unary(ctx context.Context, method string, in interface{}, out interface{}, opts ...InvokeOption) error {
+	method, err := methodPath(method)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := i.callConfig(opts...)
+	if err != nil {
+		return err
+	}
+
+	if grpcReflect.IsNil(out) {
+		out = newUnaryOutput(cfg, method)
+	}
+
+	if err := checkMethodTypes(cfg, method, in, out); err != nil {
+		return err
+	}
+
+	conn, closeConn, err := i.clientConn(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	defer closeConn()
+
+	ctx = cfg.outgoingContext(ctx)
+	tap := cfg.messageTap
+
+	tap.send(in)
+
+	defer cfg.startTiming()()
+
+	for attempt := 0; ; attempt++ {
+		logDone := cfg.logAttempt(method, attempt)
+
+		err := conn.Invoke(ctx, method, in, out, cfg.attemptCallOptions(attempt)...)
+
+		logDone(err)
+
+		if err == nil {
+			break
+		}
+
+		if ctx.Err() != nil || !cfg.shouldRetry(attempt, err) {
+			return err
+		}
+	}
+
+	tap.recv(out)
+
+	return nil
+}
+
+// ServerStream invokes a server-stream method. The dial options in opts are ignored.
+func (i *Invoker) ServerStream(ctx context.Context, method string, in interface{}, handle ClientStreamHandler, opts ...InvokeOption) (err error) {
+	method, err = methodPath(method)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := i.callConfig(opts...)
+	if err != nil {
+		return err
+	}
+
+	conn, closeConn, err := i.clientConn(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	defer closeConn()
+
+	logDone := cfg.logAttempt(method, 0)
+
+	defer func() {
+		logDone(err)
+	}()
+
+	defer cfg.startTiming()()
+
+	desc := newStreamDesc(cfg, false, true)
+
+	s, err := conn.NewStream(cfg.outgoingContext(ctx), desc, method, cfg.attemptCallOptions(0)...)
+	if err != nil {
+		return err
+	}
+
+	s = tapClientStream(cfg, s)
+
+	if err := s.SendMsg(in); err != nil {
+		return newStreamError(method, "send", err)
+	}
+
+	if err := s.CloseSend(); err != nil {
+		return newStreamError(method, "close send", err)
+	}
+
+	if err := captureStreamHeader(cfg, s); err != nil {
+		return newStreamError(method, "recv header", err)
+	}
+
+	if err := handle.Handle(s); err != nil {
+		return newStreamError(method, "recv response", err)
+	}
+
+	if err := checkStreamDrained(cfg, s); err != nil {
+		return newStreamError(method, "drain check", err)
+	}
+
+	return nil
+}
+
+// ClientStream invokes a client-stream method. The dial options in opts are ignored.
+func (i *Invoker) ClientStream(ctx context.Context, method string, handle ClientStreamHandler, out interface{}, opts ...InvokeOption) (err error) {
+	method, err = methodPath(method)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := i.callConfig(opts...)
+	if err != nil {
+		return err
+	}
+
+	conn, closeConn, err := i.clientConn(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	defer closeConn()
+
+	logDone := cfg.logAttempt(method, 0)
+
+	defer func() {
+		logDone(err)
+	}()
+
+	defer cfg.startTiming()()
+
+	desc := newStreamDesc(cfg, true, false)
+
+	s, err := conn.NewStream(cfg.outgoingContext(ctx), desc, method, cfg.attemptCallOptions(0)...)
+	if err != nil {
+		return err
+	}
+
+	s = tapClientStream(cfg, s)
+
+	if err := handle.Handle(s); err != nil {
+		return newStreamError(method, "send", err)
+	}
+
+	if err := s.CloseSend(); err != nil {
+		return newStreamError(method, "close send", err)
+	}
+
+	if err := captureStreamHeader(cfg, s); err != nil {
+		return newStreamError(method, "recv header", err)
+	}
+
+	if err := s.RecvMsg(out); err != nil {
+		return newStreamError(method, "recv response", err)
+	}
+
+	return nil
+}
+
+// Bidi invokes a bidirectional-stream method. The dial options in opts are ignored.
+func (i *Invoker) Bidi(ctx context.Context, method string, handle ClientStreamHandler, opts ...InvokeOption) (err error) {
+	method, err = methodPath(method)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := i.callConfig(opts...)
+	if err != nil {
+		return err
+	}
+
+	conn, closeConn, err := i.clientConn(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	defer closeConn()
+
+	logDone := cfg.logAttempt(method, 0)
+
+	defer func() {
+		logDone(err)
+	}()
+
+	defer cfg.startTiming()()
+
+	desc := newStreamDesc(cfg, true, true)
+
+	s, err := conn.NewStream(cfg.outgoingContext(ctx), desc, method, cfg.attemptCallOptions(0)...)
+	if err != nil {
+		return err
+	}
+
+	s = tapClientStream(cfg, s)
+
+	return handle.Handle(s)
+}
+
+// clientConn returns the connection for a call. A new connection is dialed if the call asks for a fresh one, the
+// returned function closes it after the call.
+func (i *Invoker) clientConn(ctx context.Context, cfg invokeConfig) (*grpc.ClientConn, func(), error) {
+	if i.fresh {
+		return i.conn, func() {}, nil
+	}
+
+	if !cfg.freshConnection {
+		return i.persistentConn(ctx, cfg.idleTimeout)
+	}
+
+	conn, err := grpc.DialContext(ctx, i.addr, cfg.dialOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conn, func() {
+		_ = conn.Close() // nolint: errcheck
+	}, nil
+}
+
+// persistentConn returns the connection of the invoker. The connection is re-dialed if it has been idle longer than the
+// timeout and there is no active call, the returned function marks the end of the call.
+func (i *Invoker) persistentConn(ctx context.Context, idleTimeout time.Duration) (*grpc.ClientConn, func(), error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if idleTimeout > 0 && i.active == 0 && time.Since(i.lastUsed) > idleTimeout {
+		conn, err := grpc.DialContext(ctx, i.addr, i.dialOpts...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		_ = i.conn.Close() // nolint: errcheck
+
+		i.conn = conn
+	}
+
+	i.active++
+	i.lastUsed = time.Now()
+
+	return i.conn, func() {
+		i.mu.Lock()
+		defer i.mu.Unlock()
+
+		i.active--
+		i.lastUsed = time.Now()
+	}, nil
+}
+
+// callConfig builds the config of a call from the default options, the options of the invoker and the options of the
+// call. It is built once per call and passed around, so the options are not applied again.
+func (i *Invoker) callConfig(opts ...InvokeOption) (invokeConfig, error) {
+	result := make([]InvokeOption, 0, len(i.opts)+len(opts))
+
+	result = append(result, i.opts...)
+	result = append(result, opts...)
+
+	cfg := buildInvokeConfig(i.defaults, result)
+
+	return cfg, cfg.checkCodec()
+}
+
+func normalizeMethod(method string) string {
+	return fmt.Sprintf("/%s", strings.TrimLeft(method, "/"))
+}
+
+// methodPath normalizes the method to "/service/method" and checks that both the service and the method are present.
+func methodPath(method string) (string, error) {
+	method = normalizeMethod(method)
+
+	parts := strings.Split(method[1:], "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("%w: want \"service/method\", got %q", errors.ErrInvalidMethodPath, method)
+	}
+
+	return method, nil
+}
@@ -0,0 +1,330 @@
+package grpcmock_test
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer/roundrobin"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+
+	"github.com/nhatthm/grpcmock"
+	grpcAssert "github.com/nhatthm/grpcmock/assert"
+	"github.com/nhatthm/grpcmock/test"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestInvoker_ReuseConnection(t *testing.T) {
+	t.Parallel()
+
+	var numDials int64
+
+	dialer := test.StartServer(t,
+		test.GetItem(func(_ context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+			return &grpctest.Item{Id: request.Id}, nil
+		}),
+		test.ListItems(func(_ *grpctest.ListItemsRequest, srv grpctest.ItemService_ListItemsServer) error {
+			return srv.Send(&grpctest.Item{Id: 42})
+		}),
+		test.CreateItems(func(srv grpctest.ItemService_CreateItemsServer) error {
+			return srv.SendAndClose(&grpctest.CreateItemsResponse{NumItems: 1})
+		}),
+		test.TransformItems(func(srv grpctest.ItemService_TransformItemsServer) error {
+			return srv.Send(&grpctest.Item{Id: 42})
+		}),
+	)
+
+	i, err := grpcmock.NewInvoker("bufconn",
+		grpcmock.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			atomic.AddInt64(&numDials, 1)
+
+			return dialer(ctx, addr)
+		}),
+		grpcmock.WithInsecure(),
+	)
+	require.NoError(t, err)
+
+	defer i.Close() // nolint: errcheck
+
+	for id := int32(1); id <= 3; id++ {
+		out := &grpctest.Item{}
+
+		err := i.Unary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: id}, out)
+
+		assert.NoError(t, err)
+		grpcAssert.EqualMessage(t, &grpctest.Item{Id: id}, out)
+	}
+
+	var items []*grpctest.Item
+
+	err = i.ServerStream(context.Background(), "/grpctest.ItemService/ListItems", &grpctest.ListItemsRequest{}, grpcmock.RecvAll(&items))
+
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	resp := &grpctest.CreateItemsResponse{}
+
+	err = i.ClientStream(context.Background(), "grpctest.ItemService/CreateItems", grpcmock.SendAll([]*grpctest.Item{{Id: 42}}), resp)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), resp.NumItems)
+
+	err = i.Bidi(context.Background(), "grpctest.ItemService/TransformItems", func(s grpc.ClientStream) error {
+		return s.RecvMsg(&grpctest.Item{})
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&numDials))
+}
+
+func TestInvoker_WithIdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	var numDials int64
+
+	dialer := test.StartServer(t,
+		test.GetItem(func(_ context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+			return &grpctest.Item{Id: request.Id}, nil
+		}),
+	)
+
+	i, err := grpcmock.NewInvoker("bufconn",
+		grpcmock.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			atomic.AddInt64(&numDials, 1)
+
+			return dialer(ctx, addr)
+		}),
+		grpcmock.WithIdleTimeout(50*time.Millisecond),
+		grpcmock.WithInsecure(),
+	)
+	require.NoError(t, err)
+
+	defer i.Close() // nolint: errcheck
+
+	getItem := func(id int32) {
+		out := &grpctest.Item{}
+
+		err := i.Unary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: id}, out)
+
+		require.NoError(t, err)
+		grpcAssert.EqualMessage(t, &grpctest.Item{Id: id}, out)
+	}
+
+	// The connection is not idle.
+	getItem(1)
+	getItem(2)
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&numDials))
+
+	// The connection is re-dialed after the idle period.
+	time.Sleep(100 * time.Millisecond)
+
+	getItem(3)
+
+	assert.Equal(t, int64(2), atomic.LoadInt64(&numDials))
+}
+
+func TestInvoker_WithFreshConnection(t *testing.T) {
+	t.Parallel()
+
+	var numDials int64
+
+	dialer := test.StartServer(t,
+		test.GetItem(func(_ context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+			return &grpctest.Item{Id: request.Id}, nil
+		}),
+	)
+
+	i, err := grpcmock.NewInvoker("bufconn",
+		grpcmock.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			atomic.AddInt64(&numDials, 1)
+
+			return dialer(ctx, addr)
+		}),
+		grpcmock.WithInsecure(),
+	)
+	require.NoError(t, err)
+
+	defer i.Close() // nolint: errcheck
+
+	// The connection of the invoker.
+	err = i.Unary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 1}, &grpctest.Item{})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&numDials))
+
+	for id := int32(2); id <= 3; id++ {
+		out := &grpctest.Item{}
+
+		err := i.Unary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: id}, out,
+			grpcmock.WithFreshConnection(),
+		)
+
+		assert.NoError(t, err)
+		grpcAssert.EqualMessage(t, &grpctest.Item{Id: id}, out)
+	}
+
+	assert.Equal(t, int64(3), atomic.LoadInt64(&numDials))
+
+	// The connection of the invoker is still usable.
+	err = i.Unary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 4}, &grpctest.Item{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&numDials))
+}
+
+func TestInvokeUnary_WithFreshConnection(t *testing.T) {
+	t.Parallel()
+
+	var numDials int64
+
+	dialer := test.StartServer(t,
+		test.GetItem(func(_ context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+			return &grpctest.Item{Id: request.Id}, nil
+		}),
+	)
+
+	out := &grpctest.Item{}
+
+	err := grpcmock.InvokeUnary(context.Background(), "bufconn/grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, out,
+		grpcmock.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			atomic.AddInt64(&numDials, 1)
+
+			return dialer(ctx, addr)
+		}),
+		grpcmock.WithInsecure(),
+		grpcmock.WithFreshConnection(),
+	)
+
+	require.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, out)
+
+	// The free functions always dial a new connection, so it is not dialed twice.
+	assert.Equal(t, int64(1), atomic.LoadInt64(&numDials))
+}
+
+func BenchmarkInvokeUnary(b *testing.B) {
+	srv, opts := grpcmock.NewInProcess(grpctest.RegisterItemServiceServer)
+	defer srv.Close() // nolint: errcheck
+
+	srv.ExpectUnary("grpctest.ItemService/GetItem").UnlimitedTimes().
+		Return(&grpctest.Item{Id: 42})
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, &grpctest.Item{}, opts...)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInvoker_Unary(b *testing.B) {
+	srv, opts := grpcmock.NewInProcess(grpctest.RegisterItemServiceServer)
+	defer srv.Close() // nolint: errcheck
+
+	srv.ExpectUnary("grpctest.ItemService/GetItem").UnlimitedTimes().
+		Return(&grpctest.Item{Id: 42})
+
+	i, err := grpcmock.NewInvoker("bufconn", opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	defer i.Close() // nolint: errcheck
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		if err := i.Unary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, &grpctest.Item{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestInvoker_WithResolver_RoundRobin(t *testing.T) {
+	t.Parallel()
+
+	var calls [2]int64
+
+	newServer := func(i int) grpcmock.ContextDialer {
+		return test.StartServer(t, test.GetItem(func(_ context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+			atomic.AddInt64(&calls[i], 1)
+
+			return &grpctest.Item{Id: request.Id}, nil
+		}))
+	}
+
+	dialers := map[string]grpcmock.ContextDialer{
+		"mock-1": newServer(0),
+		"mock-2": newServer(1),
+	}
+
+	r := manual.NewBuilderWithScheme("grpcmock-round-robin")
+	r.InitialState(resolver.State{Addresses: []resolver.Address{{Addr: "mock-1"}, {Addr: "mock-2"}}})
+
+	i, err := grpcmock.NewInvoker("grpcmock-round-robin:///items",
+		grpcmock.WithResolver(r),
+		grpcmock.WithBalancer(roundrobin.Name),
+		grpcmock.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialers[addr](ctx, addr)
+		}),
+		grpcmock.WithInsecure(),
+	)
+
+	require.NoError(t, err)
+
+	defer i.Close() // nolint: errcheck
+
+	for n := 0; n < 10; n++ {
+		err := i.Unary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, &grpctest.Item{})
+
+		require.NoError(t, err)
+	}
+
+	// The first calls could go to the first ready server, but the calls are distributed once both are ready.
+	assert.Positive(t, atomic.LoadInt64(&calls[0]))
+	assert.Positive(t, atomic.LoadInt64(&calls[1]))
+	assert.Equal(t, int64(10), atomic.LoadInt64(&calls[0])+atomic.LoadInt64(&calls[1]))
+}
+
+// nolint: paralleltest // The default invoke options are global.
+func TestInvoker_DefaultInvokeOptions_Snapshot(t *testing.T) {
+	dialer := test.StartServer(t, test.GetItem(func(ctx context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+		var locale string
+
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("locale"); len(values) > 0 {
+				locale = values[0]
+			}
+		}
+
+		return &grpctest.Item{Id: request.Id, Locale: locale}, nil
+	}))
+
+	grpcmock.DefaultInvokeOptions(grpcmock.WithHeader("locale", "en-US"))
+
+	defer grpcmock.ResetDefaultInvokeOptions()
+
+	i, err := grpcmock.NewInvoker("", grpcmock.WithContextDialer(dialer), grpcmock.WithInsecure())
+	require.NoError(t, err)
+
+	defer i.Close() // nolint: errcheck
+
+	// The defaults changed after the invoker was created are not applied.
+	grpcmock.DefaultInvokeOptions(grpcmock.WithHeader("locale", "fr-FR"))
+
+	actual := &grpctest.Item{}
+	err = i.Unary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, actual)
+
+	require.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42, Locale: "en-US"}, actual)
+}
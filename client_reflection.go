@@ -0,0 +1,146 @@
+package grpcmock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/nhatthm/grpcmock/errors"
+)
+
+// InvokeByReflection invokes a unary method without knowing its types. The method descriptor is resolved by using the
+// reflection service of the remote server, the input is decoded from JSON and the output is returned as JSON.
+//
+//    out, err := grpcmock.InvokeByReflection(ctx, "localhost:9000", "grpctest.ItemService/GetItem", `{"id": 42}`,
+//    	grpcmock.WithInsecure(),
+//    )
+func InvokeByReflection(
+	ctx context.Context,
+	target string,
+	method string,
+	inJSON string,
+	opts ...InvokeOption,
+) (string, error) {
+	path, err := methodPath(method)
+	if err != nil {
+		return "", fmt.Errorf("could not parse method url: %w", err)
+	}
+
+	serviceName, methodName, _ := strings.Cut(path[1:], "/")
+
+	cfg := newInvokeConfig(opts...)
+	if err := cfg.checkCodec(); err != nil {
+		return "", err
+	}
+
+	ctx = cfg.outgoingContext(ctx)
+
+	conn, err := grpc.DialContext(ctx, target, cfg.dialOpts...)
+	if err != nil {
+		return "", err
+	}
+
+	defer conn.Close() // nolint: errcheck
+
+	desc, err := resolveMethodDescriptor(ctx, conn, serviceName, methodName)
+	if err != nil {
+		return "", err
+	}
+
+	if desc.IsStreamingClient() || desc.IsStreamingServer() {
+		return "", fmt.Errorf("%w: %s", errors.ErrMethodNotUnary, method)
+	}
+
+	in := dynamicpb.NewMessage(desc.Input())
+
+	if err := protojson.Unmarshal([]byte(inJSON), in); err != nil {
+		return "", fmt.Errorf("could not unmarshal input: %w", err)
+	}
+
+	out := dynamicpb.NewMessage(desc.Output())
+
+	if err := conn.Invoke(ctx, fmt.Sprintf("/%s/%s", serviceName, methodName), in, out, cfg.attemptCallOptions(0)...); err != nil {
+		return "", err
+	}
+
+	result, err := protojson.Marshal(out)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal output: %w", err)
+	}
+
+	return string(result), nil
+}
+
+func resolveMethodDescriptor(ctx context.Context, conn *grpc.ClientConn, serviceName, methodName string) (protoreflect.MethodDescriptor, error) {
+	s, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer s.CloseSend() // nolint: errcheck
+
+	req := &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: serviceName,
+		},
+	}
+
+	if err := s.Send(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	if e := resp.GetErrorResponse(); e != nil {
+		return nil, status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+	}
+
+	fds := &descriptorpb.FileDescriptorSet{}
+
+	for _, b := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		fd := &descriptorpb.FileDescriptorProto{}
+
+		if err := proto.Unmarshal(b, fd); err != nil {
+			return nil, fmt.Errorf("could not read file descriptor: %w", err)
+		}
+
+		fds.File = append(fds.File, fd)
+	}
+
+	files, err := protodesc.NewFiles(fds)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file descriptor: %w", err)
+	}
+
+	d, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errors.ErrServiceNotFound, serviceName)
+	}
+
+	svc, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errors.ErrServiceNotFound, serviceName)
+	}
+
+	m := svc.Methods().ByName(protoreflect.Name(methodName))
+	if m == nil {
+		return nil, fmt.Errorf("%w: /%s/%s", errors.ErrMethodNotFound, serviceName, methodName)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,116 @@
+package grpcmock_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nhatthm/grpcmock"
+	"github.com/nhatthm/grpcmock/errors"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestInvokeByReflection_MalformedMethod(t *testing.T) {
+	t.Parallel()
+
+	out, err := grpcmock.InvokeByReflection(context.Background(), "", "GetItem", `{}`)
+
+	assert.Empty(t, out)
+	assert.ErrorIs(t, err, errors.ErrInvalidMethodPath)
+	assert.EqualError(t, err, `could not parse method url: invalid method path: want "service/method", got "/GetItem"`)
+}
+
+func TestInvokeByReflection_ReflectionIsNotEnabled(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(grpcmock.NoOpT())
+
+	out, err := grpcmock.InvokeByReflection(context.Background(), "", grpcTestServiceGetItem, `{"id": 42}`,
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	expected := "rpc error: code = Unimplemented desc = unknown service grpc.reflection.v1alpha.ServerReflection"
+
+	assert.Empty(t, out)
+	assert.EqualError(t, err, expected)
+}
+
+func TestInvokeByReflection_Error(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		method        string
+		in            string
+		expectedError string
+	}{
+		{
+			scenario:      "service not found",
+			method:        "grpctest.UnknownService/GetItem",
+			in:            `{}`,
+			expectedError: "rpc error: code = NotFound",
+		},
+		{
+			scenario:      "method not found",
+			method:        "grpctest.ItemService/UnknownMethod",
+			in:            `{}`,
+			expectedError: "method not found: /grpctest.ItemService/UnknownMethod",
+		},
+		{
+			scenario:      "method is not unary",
+			method:        grpcTestServiceListItems,
+			in:            `{}`,
+			expectedError: "method is not unary: grpctest.ItemService/ListItems",
+		},
+		{
+			scenario:      "invalid input",
+			method:        grpcTestServiceGetItem,
+			in:            `{"id": "foobar"}`,
+			expectedError: "could not unmarshal input:",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			_, d := mockItemServiceServer(grpcmock.NoOpT(), grpcmock.WithReflection())
+
+			out, err := grpcmock.InvokeByReflection(context.Background(), "", tc.method, tc.in,
+				grpcmock.WithContextDialer(d),
+				grpcmock.WithInsecure(),
+			)
+
+			assert.Empty(t, out)
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestInvokeByReflection_Success(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t,
+		grpcmock.WithReflection(),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary(grpcTestServiceGetItem).
+				WithHeader("locale", "en-US").
+				WithPayload(&grpctest.GetItemRequest{Id: 42}).
+				Return(&grpctest.Item{Id: 42, Locale: "en-US", Name: "Foobar"})
+		},
+	)
+
+	out, err := grpcmock.InvokeByReflection(context.Background(), "", grpcTestServiceGetItem, `{"id": 42}`,
+		grpcmock.WithHeader("locale", "en-US"),
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	expected := `{"id": 42, "locale": "en-US", "name": "Foobar"}`
+
+	assert.JSONEq(t, expected, out)
+	assert.NoError(t, err)
+}
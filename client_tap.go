@@ -0,0 +1,89 @@
+package grpcmock
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// messageTap observes the messages that are sent and received by the invokes.
+type messageTap struct {
+	onSend func(interface{})
+	onRecv func(interface{})
+}
+
+func (t *messageTap) send(v interface{}) {
+	if t == nil || t.onSend == nil {
+		return
+	}
+
+	t.onSend(tapValue(v))
+}
+
+func (t *messageTap) recv(v interface{}) {
+	if t == nil || t.onRecv == nil {
+		return
+	}
+
+	t.onRecv(tapValue(v))
+}
+
+// tappedClientStream calls the tap for every message that goes through the stream.
+type tappedClientStream struct {
+	grpc.ClientStream
+
+	tap *messageTap
+}
+
+// SendMsg satisfies grpc.ClientStream.
+func (s *tappedClientStream) SendMsg(m interface{}) error {
+	s.tap.send(m)
+
+	return s.ClientStream.SendMsg(m)
+}
+
+// RecvMsg satisfies grpc.ClientStream.
+func (s *tappedClientStream) RecvMsg(m interface{}) error {
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	s.tap.recv(m)
+
+	return nil
+}
+
+func tapClientStream(cfg invokeConfig, s grpc.ClientStream) grpc.ClientStream {
+	if cfg.messageTap == nil {
+		return s
+	}
+
+	return &tappedClientStream{ClientStream: s, tap: cfg.messageTap}
+}
+
+// tapValue returns a copy of the proto messages, so the taps could not change the messages of the call.
+func tapValue(v interface{}) interface{} {
+	if msg, ok := v.(proto.Message); ok {
+		return proto.Clone(msg)
+	}
+
+	return v
+}
+
+// WithMessageTap calls onSend with every message sent by the client, and onRecv with every message received from the
+// server. The taps receive copies of the proto messages, so they could not change what is sent or received. Either tap
+// could be nil.
+//
+//    err := grpcmock.InvokeBidirectionalStream(ctx, "grpctest.ItemService/TransformItems", handler,
+//    	grpcmock.WithMessageTap(
+//    		func(msg interface{}) { t.Logf("sent: %v", msg) },
+//    		func(msg interface{}) { t.Logf("received: %v", msg) },
+//    	),
+//    )
+func WithMessageTap(onSend func(interface{}), onRecv func(interface{})) InvokeOption {
+	return func(c *invokeConfig) {
+		c.messageTap = &messageTap{
+			onSend: onSend,
+			onRecv: onRecv,
+		}
+	}
+}
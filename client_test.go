@@ -6,20 +6,30 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/nhatthm/grpcmock"
 	grpcAssert "github.com/nhatthm/grpcmock/assert"
+	grpcErrors "github.com/nhatthm/grpcmock/errors"
 	grpcMock "github.com/nhatthm/grpcmock/mock/grpc"
+	"github.com/nhatthm/grpcmock/service"
 	"github.com/nhatthm/grpcmock/test"
 	"github.com/nhatthm/grpcmock/test/grpctest"
 )
@@ -124,6 +134,901 @@ func TestInvokeUnary_Success(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestInvokeUnary_WithTypeCheck(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.GetItem(func(_ context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+		return &grpctest.Item{Id: request.Id}, nil
+	}))
+
+	testCases := []struct {
+		scenario       string
+		method         string
+		in             interface{}
+		out            interface{}
+		expectedError  error
+		expectedString string
+	}{
+		{
+			scenario: "success",
+			method:   "grpctest.ItemService/GetItem",
+			in:       &grpctest.GetItemRequest{Id: 42},
+			out:      &grpctest.Item{},
+		},
+		{
+			scenario:       "input mismatch",
+			method:         "grpctest.ItemService/GetItem",
+			in:             &grpctest.ListItemsRequest{},
+			out:            &grpctest.Item{},
+			expectedError:  grpcErrors.ErrTypeMismatch,
+			expectedString: "type mismatch: input of /grpctest.ItemService/GetItem, got *grpctest.ListItemsRequest, want *grpctest.GetItemRequest",
+		},
+		{
+			scenario:       "output mismatch",
+			method:         "grpctest.ItemService/GetItem",
+			in:             &grpctest.GetItemRequest{Id: 42},
+			out:            &grpctest.CreateItemsResponse{},
+			expectedError:  grpcErrors.ErrTypeMismatch,
+			expectedString: "type mismatch: output of /grpctest.ItemService/GetItem, got *grpctest.CreateItemsResponse, want *grpctest.Item",
+		},
+		{
+			scenario:       "method not found",
+			method:         "grpctest.ItemService/DeleteItem",
+			in:             &grpctest.GetItemRequest{Id: 42},
+			out:            &grpctest.Item{},
+			expectedError:  grpcErrors.ErrMethodNotFound,
+			expectedString: "method not found: /grpctest.ItemService/DeleteItem",
+		},
+		{
+			scenario:       "method of another service",
+			method:         "grpctest.OtherService/GetItem",
+			in:             &grpctest.GetItemRequest{Id: 42},
+			out:            &grpctest.Item{},
+			expectedError:  grpcErrors.ErrMethodNotFound,
+			expectedString: "method not found: /grpctest.OtherService/GetItem",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			err := grpcmock.InvokeUnary(context.Background(), tc.method, tc.in, tc.out,
+				grpcmock.WithContextDialer(dialer),
+				grpcmock.WithInsecure(),
+				grpcmock.WithTypeCheck((*grpctest.ItemServiceServer)(nil)),
+			)
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err)
+				grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, tc.out.(proto.Message))
+			} else {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.EqualError(t, err, tc.expectedString)
+			}
+		})
+	}
+}
+
+func TestInvokeUnary_NilOutput_Empty(t *testing.T) {
+	t.Parallel()
+
+	_, d := grpcmock.MockServerWithBufConn(
+		grpcmock.RegisterServiceFromMethods(service.Method{
+			ServiceName: "grpctest.PingService",
+			MethodName:  "Ping",
+			MethodType:  service.TypeUnary,
+			Input:       &emptypb.Empty{},
+			Output:      &emptypb.Empty{},
+		}),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary("grpctest.PingService/Ping").
+				Return(&emptypb.Empty{})
+		},
+	)(t)
+
+	err := grpcmock.InvokeUnary(context.Background(), "grpctest.PingService/Ping", &emptypb.Empty{}, nil,
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	assert.NoError(t, err)
+}
+
+func TestInvokeUnary_WithCallOptions(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.GetItem(func(_ context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+		return &grpctest.Item{Id: request.Id, Name: "Item #42"}, nil
+	}))
+
+	// The response is larger than the max receive size, so the call fails if the option is forwarded.
+	err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem",
+		&grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+		grpcmock.WithCallOptions(grpc.MaxCallRecvMsgSize(1)),
+	)
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestInvokeUnary_WithRetry(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario         string
+		mockServer       grpcmock.ServerOption
+		retry            grpcmock.InvokeOption
+		expectedAttempts []int
+		expectedCode     codes.Code
+	}{
+		{
+			scenario: "success after retry",
+			mockServer: func(s *grpcmock.Server) {
+				s.ExpectUnary("grpctest.ItemService/GetItem").
+					ReturnError(codes.Unavailable, "try again")
+
+				s.ExpectUnary("grpctest.ItemService/GetItem").
+					Return(&grpctest.Item{Id: 42})
+			},
+			retry:            grpcmock.WithRetry(3),
+			expectedAttempts: []int{0, 1},
+			expectedCode:     codes.OK,
+		},
+		{
+			scenario: "not retryable",
+			mockServer: func(s *grpcmock.Server) {
+				s.ExpectUnary("grpctest.ItemService/GetItem").
+					ReturnError(codes.NotFound, "item not found")
+			},
+			retry:            grpcmock.WithRetry(3),
+			expectedAttempts: []int{0},
+			expectedCode:     codes.NotFound,
+		},
+		{
+			scenario: "retry with custom codes",
+			mockServer: func(s *grpcmock.Server) {
+				s.ExpectUnary("grpctest.ItemService/GetItem").
+					ReturnError(codes.ResourceExhausted, "slow down")
+
+				s.ExpectUnary("grpctest.ItemService/GetItem").
+					Return(&grpctest.Item{Id: 42})
+			},
+			retry:            grpcmock.WithRetry(2, codes.ResourceExhausted),
+			expectedAttempts: []int{0, 1},
+			expectedCode:     codes.OK,
+		},
+		{
+			scenario: "attempts are exhausted",
+			mockServer: func(s *grpcmock.Server) {
+				s.ExpectUnary("grpctest.ItemService/GetItem").Twice().
+					ReturnError(codes.Unavailable, "try again")
+			},
+			retry:            grpcmock.WithRetry(2),
+			expectedAttempts: []int{0, 1},
+			expectedCode:     codes.Unavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			_, d := grpcmock.MockServerWithBufConn(
+				grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+				tc.mockServer,
+			)(t)
+
+			var attempts []int
+
+			err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem",
+				&grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+				grpcmock.WithContextDialer(d),
+				grpcmock.WithInsecure(),
+				tc.retry,
+				grpcmock.WithCallOptionFunc(func(attempt int) []grpc.CallOption {
+					attempts = append(attempts, attempt)
+
+					return nil
+				}),
+			)
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			assert.Equal(t, tc.expectedAttempts, attempts)
+		})
+	}
+}
+
+func TestInvokeUnary_WithCallOptionFunc(t *testing.T) {
+	t.Parallel()
+
+	s, d := grpcmock.MockServerWithBufConn(
+		grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary("grpctest.ItemService/GetItem").
+				ReturnError(codes.Unavailable, "try again")
+
+			s.ExpectUnary("grpctest.ItemService/GetItem").
+				Return(&grpctest.Item{Id: 42})
+		},
+	)(t)
+
+	out := &grpctest.Item{}
+	err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem",
+		&grpctest.GetItemRequest{Id: 42}, out,
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+		grpcmock.WithRetry(2),
+		// Compress the request of the retries only.
+		grpcmock.WithCallOptionFunc(func(attempt int) []grpc.CallOption {
+			if attempt == 0 {
+				return nil
+			}
+
+			return []grpc.CallOption{grpc.UseCompressor("gzip")}
+		}),
+	)
+
+	require.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, out)
+	require.Len(t, s.Calls, 2)
+	assert.Empty(t, s.Calls[0].Compressor)
+	assert.Equal(t, "gzip", s.Calls[1].Compressor)
+}
+
+// fakeLogger records the logs, the durations are replaced by a placeholder so that the records could be compared.
+type fakeLogger struct {
+	mu      sync.Mutex
+	records [][]interface{}
+}
+
+func (l *fakeLogger) Log(keyvals ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record := make([]interface{}, 0, len(keyvals))
+
+	for _, v := range keyvals {
+		if _, ok := v.(time.Duration); ok {
+			v = "<duration>"
+		}
+
+		record = append(record, v)
+	}
+
+	l.records = append(l.records, record)
+}
+
+func (l *fakeLogger) loggedRecords() [][]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.records
+}
+
+func TestInvoke_WithLogger(t *testing.T) {
+	t.Parallel()
+
+	_, d := grpcmock.MockServerWithBufConn(
+		grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary("grpctest.ItemService/GetItem").
+				ReturnError(codes.Unavailable, "server is not ready")
+
+			s.ExpectUnary("grpctest.ItemService/GetItem").
+				Return(&grpctest.Item{Id: 42})
+
+			s.ExpectServerStream("grpctest.ItemService/ListItems").
+				Return([]*grpctest.Item{{Id: 42}})
+		},
+	)(t)
+
+	logger := &fakeLogger{}
+	opts := []grpcmock.InvokeOption{
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+		grpcmock.WithLogger(logger),
+	}
+
+	err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem",
+		&grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+		append(opts, grpcmock.WithRetry(2, codes.Unavailable))...,
+	)
+
+	require.NoError(t, err)
+
+	out := make([]*grpctest.Item, 0)
+
+	err = grpcmock.InvokeServerStream(context.Background(), "grpctest.ItemService/ListItems",
+		&grpctest.ListItemsRequest{}, grpcmock.RecvAll(&out), opts...,
+	)
+
+	require.NoError(t, err)
+
+	expected := [][]interface{}{
+		{"method", "/grpctest.ItemService/GetItem", "attempt", 0, "code", "Unavailable", "duration", "<duration>"},
+		{"method", "/grpctest.ItemService/GetItem", "attempt", 1, "code", "OK", "duration", "<duration>"},
+		{"method", "/grpctest.ItemService/ListItems", "attempt", 0, "code", "OK", "duration", "<duration>"},
+	}
+
+	assert.Equal(t, expected, logger.loggedRecords())
+}
+
+func TestInvoke_WithTiming(t *testing.T) {
+	t.Parallel()
+
+	const delay = 50 * time.Millisecond
+
+	testCases := []struct {
+		scenario   string
+		mockServer grpcmock.ServerOption
+		invoke     func(d grpcmock.ContextDialer, opts ...grpcmock.InvokeOption) error
+	}{
+		{
+			scenario: "unary",
+			mockServer: func(s *grpcmock.Server) {
+				s.ExpectUnary("grpctest.ItemService/GetItem").
+					After(delay).
+					Return(&grpctest.Item{Id: 42})
+			},
+			invoke: func(d grpcmock.ContextDialer, opts ...grpcmock.InvokeOption) error {
+				opts = append(opts, grpcmock.WithContextDialer(d), grpcmock.WithInsecure())
+
+				return grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem",
+					&grpctest.GetItemRequest{Id: 42}, &grpctest.Item{}, opts...,
+				)
+			},
+		},
+		{
+			scenario: "server stream",
+			mockServer: func(s *grpcmock.Server) {
+				s.ExpectServerStream("grpctest.ItemService/ListItems").
+					After(delay).
+					Return([]*grpctest.Item{{Id: 41}, {Id: 42}})
+			},
+			invoke: func(d grpcmock.ContextDialer, opts ...grpcmock.InvokeOption) error {
+				opts = append(opts, grpcmock.WithContextDialer(d), grpcmock.WithInsecure())
+				out := make([]*grpctest.Item, 0)
+
+				return grpcmock.InvokeServerStream(context.Background(), "grpctest.ItemService/ListItems",
+					&grpctest.ListItemsRequest{}, grpcmock.RecvAll(&out), opts...,
+				)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			_, d := grpcmock.MockServerWithBufConn(
+				grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+				tc.mockServer,
+			)(t)
+
+			var durations []time.Duration
+
+			err := tc.invoke(d, grpcmock.WithTiming(func(d time.Duration) {
+				durations = append(durations, d)
+			}))
+
+			require.NoError(t, err)
+			require.Len(t, durations, 1)
+			assert.GreaterOrEqual(t, durations[0], delay)
+		})
+	}
+}
+
+func TestInvokeUnary_NilOutput(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.GetItem(func(_ context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+		return &grpctest.Item{Id: request.Id}, nil
+	}))
+
+	testCases := []struct {
+		scenario string
+		out      interface{}
+		opts     []grpcmock.InvokeOption
+	}{
+		{
+			scenario: "nil",
+		},
+		{
+			scenario: "nil pointer",
+			out:      (*grpctest.Item)(nil),
+		},
+		{
+			scenario: "with type check",
+			opts:     []grpcmock.InvokeOption{grpcmock.WithTypeCheck((*grpctest.ItemServiceServer)(nil))},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			opts := []grpcmock.InvokeOption{
+				grpcmock.WithContextDialer(dialer),
+				grpcmock.WithInsecure(),
+			}
+
+			err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, tc.out,
+				append(opts, tc.opts...)...,
+			)
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// jsonCodec encodes the messages in json, it counts the messages it encodes and decodes.
+type jsonCodec struct {
+	calls *int64
+}
+
+func (c jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	atomic.AddInt64(c.calls, 1)
+
+	return protojson.Marshal(v.(proto.Message)) // nolint: errcheck
+}
+
+func (c jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	atomic.AddInt64(c.calls, 1)
+
+	return protojson.Unmarshal(data, v.(proto.Message)) // nolint: errcheck
+}
+
+func (jsonCodec) Name() string {
+	return "test-json"
+}
+
+var jsonCodecCalls int64
+
+// jsonSubtypeCodec is the same as jsonCodec, but it is registered for the "json" content-subtype.
+type jsonSubtypeCodec struct {
+	jsonCodec
+}
+
+func (jsonSubtypeCodec) Name() string {
+	return "json"
+}
+
+var jsonSubtypeCodecCalls int64
+
+// nolint: gochecknoinits
+func init() {
+	encoding.RegisterCodec(jsonCodec{calls: &jsonCodecCalls})
+	encoding.RegisterCodec(jsonSubtypeCodec{jsonCodec{calls: &jsonSubtypeCodecCalls}})
+}
+
+func TestInvokeUnary_WithCodec(t *testing.T) {
+	t.Parallel()
+
+	_, d := grpcmock.MockServerWithBufConn(
+		grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary("grpctest.ItemService/GetItem").
+				WithHeader("content-type", "application/grpc+test-json").
+				WithPayload(&grpctest.GetItemRequest{Id: 42}).
+				Return(&grpctest.Item{Id: 42, Name: "Item #42"})
+		},
+	)(t)
+
+	before := atomic.LoadInt64(&jsonCodecCalls)
+	out := &grpctest.Item{}
+
+	err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, out,
+		grpcmock.WithCodec(jsonCodec{calls: &jsonCodecCalls}),
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	require.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42, Name: "Item #42"}, out)
+
+	// Both the client and the server encode and decode with the codec.
+	assert.GreaterOrEqual(t, atomic.LoadInt64(&jsonCodecCalls)-before, int64(4))
+}
+
+func TestInvokeUnary_WithContentSubtype(t *testing.T) {
+	t.Parallel()
+
+	_, d := grpcmock.MockServerWithBufConn(
+		grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary("grpctest.ItemService/GetItem").
+				WithHeader("content-type", "application/grpc+json").
+				WithPayload(&grpctest.GetItemRequest{Id: 42}).
+				Return(&grpctest.Item{Id: 42, Name: "Item #42"})
+		},
+	)(t)
+
+	before := atomic.LoadInt64(&jsonSubtypeCodecCalls)
+	out := &grpctest.Item{}
+
+	err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, out,
+		grpcmock.WithContentSubtype("json"),
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	require.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42, Name: "Item #42"}, out)
+
+	// Both the client and the server encode and decode with the codec of the subtype.
+	assert.GreaterOrEqual(t, atomic.LoadInt64(&jsonSubtypeCodecCalls)-before, int64(4))
+}
+
+func TestWithContentSubtype_NotRegistered(t *testing.T) {
+	t.Parallel()
+
+	err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+		grpcmock.WithContentSubtype("unregistered"),
+		grpcmock.WithInsecure(),
+	)
+
+	assert.ErrorIs(t, err, grpcErrors.ErrCodecNotRegistered)
+	assert.EqualError(t, err, "codec is not registered: unregistered")
+}
+
+type unregisteredCodec struct {
+	jsonCodec
+}
+
+func (unregisteredCodec) Name() string {
+	return "unregistered"
+}
+
+func TestWithCodec_NotRegistered(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario       string
+		codec          encoding.Codec
+		expectedString string
+	}{
+		{
+			scenario:       "unregistered",
+			codec:          unregisteredCodec{},
+			expectedString: "codec is not registered: unregistered",
+		},
+		{
+			scenario:       "nil",
+			expectedString: "codec is not registered: <nil>",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+				grpcmock.WithCodec(tc.codec),
+				grpcmock.WithInsecure(),
+			)
+
+			assert.ErrorIs(t, err, grpcErrors.ErrCodecNotRegistered)
+			assert.EqualError(t, err, tc.expectedString)
+		})
+	}
+}
+
+func TestInvokeUnaryMethod(t *testing.T) {
+	t.Parallel()
+
+	var actualMethod string
+
+	l := bufconn.Listen(1024 * 1024)
+	srv := test.NewServer(test.GetItem(func(ctx context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+		actualMethod, _ = grpc.Method(ctx)
+
+		return &grpctest.Item{Id: request.Id, Name: "Foobar"}, nil
+	}))
+
+	go func() {
+		_ = srv.Serve(l) // nolint: errcheck
+	}()
+
+	t.Cleanup(srv.Stop)
+
+	actual := &grpctest.Item{}
+
+	err := grpcmock.InvokeUnaryMethod(context.Background(),
+		"bufnet", "grpctest.ItemService", "GetItem",
+		&grpctest.GetItemRequest{Id: 42}, actual,
+		grpcmock.WithBufConnDialer(l),
+		grpcmock.WithInsecure(),
+	)
+
+	expected := &grpctest.Item{Id: 42, Name: "Foobar"}
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/grpctest.ItemService/GetItem", actualMethod)
+	grpcAssert.EqualMessage(t, expected, actual)
+}
+
+func TestInvokeUnaryMethod_DialError(t *testing.T) {
+	t.Parallel()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return nil, errors.New("dial error")
+	}
+
+	err := grpcmock.InvokeUnaryMethod(context.Background(), "bufnet", "grpctest.ItemService", "GetItem", nil, nil,
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+	)
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestInvokeUnaryMethod_InvalidMethodPath(t *testing.T) {
+	t.Parallel()
+
+	err := grpcmock.InvokeUnaryMethod(context.Background(), "bufnet", "", "GetItem", nil, nil,
+		grpcmock.WithInsecure(),
+	)
+
+	assert.ErrorIs(t, err, grpcErrors.ErrInvalidMethodPath)
+	assert.EqualError(t, err, `invalid method path: want "service/method", got "/GetItem"`)
+}
+
+type rotatingTokenCredentials struct {
+	count  int32
+	secure bool
+}
+
+func (c *rotatingTokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	token := atomic.AddInt32(&c.count, 1)
+
+	return map[string]string{"authorization": fmt.Sprintf("Bearer token-%d", token)}, nil
+}
+
+func (c *rotatingTokenCredentials) RequireTransportSecurity() bool {
+	return c.secure
+}
+
+func TestInvokeUnary_WithPerRPCCredentials(t *testing.T) {
+	t.Parallel()
+
+	tokens := make([]string, 0)
+
+	dialer := test.StartServer(t, test.GetItem(func(ctx context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		tokens = append(tokens, md.Get("authorization")...)
+
+		return &grpctest.Item{Id: request.Id}, nil
+	}))
+
+	creds := &rotatingTokenCredentials{}
+
+	for i := 0; i < 2; i++ {
+		err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem",
+			&grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+			grpcmock.WithContextDialer(dialer),
+			grpcmock.WithInsecure(),
+			grpcmock.WithPerRPCCredentials(creds),
+		)
+
+		assert.NoError(t, err)
+	}
+
+	expected := []string{"Bearer token-1", "Bearer token-2"}
+
+	assert.Equal(t, expected, tokens)
+}
+
+func TestInvokeUnary_WithPerRPCCredentials_RequireTransportSecurity(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.GetItem(func(_ context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+		return &grpctest.Item{Id: request.Id}, nil
+	}))
+
+	creds := &rotatingTokenCredentials{secure: true}
+
+	err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem",
+		&grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+		grpcmock.WithPerRPCCredentials(creds),
+	)
+
+	assert.Error(t, err)
+
+	err = grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem",
+		&grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+		grpcmock.WithInsecurePerRPCCredentials(creds),
+	)
+
+	assert.NoError(t, err)
+}
+
+func TestInvokeUnaryJSON_MissingInputType(t *testing.T) {
+	t.Parallel()
+
+	err := grpcmock.InvokeUnaryJSON(context.Background(), "grpctest.ItemService/GetItem", `{"id": 42}`, &grpctest.Item{})
+
+	assert.EqualError(t, err, "missing input type")
+}
+
+func TestInvokeUnaryJSON_InputTypeIsNotProtoMessage(t *testing.T) {
+	t.Parallel()
+
+	err := grpcmock.InvokeUnaryJSON(context.Background(), "grpctest.ItemService/GetItem", `{"id": 42}`, &grpctest.Item{},
+		grpcmock.WithInputType(struct{}{}),
+	)
+
+	assert.EqualError(t, err, "unsupported data type: got struct {}, want proto.Message")
+}
+
+func TestInvokeUnaryJSON_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	err := grpcmock.InvokeUnaryJSON(context.Background(), "grpctest.ItemService/GetItem", `{"id": "foobar"}`, &grpctest.Item{},
+		grpcmock.WithInputType(&grpctest.GetItemRequest{}),
+	)
+
+	assert.ErrorContains(t, err, "could not unmarshal input:")
+}
+
+func TestInvokeUnaryJSON_Success(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.GetItem(func(_ context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+		return test.BuildItem().
+			WithID(request.Id).
+			WithName("Foobar").
+			New(), nil
+	}))
+
+	actual := &grpctest.Item{}
+
+	err := grpcmock.InvokeUnaryJSON(context.Background(), "grpctest.ItemService/GetItem", `{"id": 42}`, actual,
+		grpcmock.WithInputType(&grpctest.GetItemRequest{}),
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+	)
+
+	expected := &grpctest.Item{Id: 42, Locale: "en-US", Name: "Foobar"}
+
+	grpcAssert.EqualMessage(t, expected, actual)
+	assert.NoError(t, err)
+}
+
+func TestInvokeUnary_Passthrough(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.GetItem(func(_ context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+		return test.BuildItem().
+			WithID(request.Id).
+			WithName("Foobar").
+			New(), nil
+	}))
+
+	var endpoint string
+
+	actual := &grpctest.Item{}
+
+	err := grpcmock.InvokeUnary(context.Background(), "passthrough:///bufnet/grpctest.ItemService/GetItem",
+		&grpctest.GetItemRequest{Id: 42}, actual,
+		grpcmock.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			endpoint = addr
+
+			return dialer(ctx, addr)
+		}),
+		grpcmock.WithInsecure(),
+	)
+
+	expected := &grpctest.Item{Id: 42, Locale: "en-US", Name: "Foobar"}
+
+	require.NoError(t, err)
+	grpcAssert.EqualMessage(t, expected, actual)
+	assert.Equal(t, "bufnet", endpoint)
+}
+
+func TestInvokeUnaryRaw_Success(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.GetItem(func(_ context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+		return test.BuildItem().
+			WithID(request.Id).
+			WithName("Foobar").
+			New(), nil
+	}))
+
+	in, err := proto.Marshal(&grpctest.GetItemRequest{Id: 42})
+	require.NoError(t, err)
+
+	out, err := grpcmock.InvokeUnaryRaw(context.Background(), "grpctest.ItemService/GetItem", in,
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+	)
+	require.NoError(t, err)
+
+	actual := &grpctest.Item{}
+
+	require.NoError(t, proto.Unmarshal(out, actual))
+
+	expected := &grpctest.Item{Id: 42, Locale: "en-US", Name: "Foobar"}
+
+	grpcAssert.EqualMessage(t, expected, actual)
+}
+
+func TestInvokeUnaryRaw_Malformed(t *testing.T) {
+	t.Parallel()
+
+	_, d := grpcmock.MockServerWithBufConn(
+		grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+	)(t)
+
+	// A field with an incomplete varint.
+	out, err := grpcmock.InvokeUnaryRaw(context.Background(), "grpctest.ItemService/GetItem", []byte{0x08, 0xff},
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	assert.Nil(t, out)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "error unmarshalling request")
+}
+
+// nolint: paralleltest // The default invoke options are global.
+func TestDefaultInvokeOptions(t *testing.T) {
+	dialer := test.StartServer(t, test.GetItem(func(ctx context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+		var locale string
+
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("locale"); len(values) > 0 {
+				locale = values[0]
+			}
+		}
+
+		return &grpctest.Item{Id: request.Id, Locale: locale}, nil
+	}))
+
+	grpcmock.DefaultInvokeOptions(
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+		grpcmock.WithHeader("locale", "en-US"),
+	)
+
+	defer grpcmock.ResetDefaultInvokeOptions()
+
+	// Defaults are applied.
+	actual := &grpctest.Item{}
+	err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, actual)
+
+	assert.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42, Locale: "en-US"}, actual)
+
+	// Explicit options override the defaults.
+	actual = &grpctest.Item{}
+	err = grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, actual,
+		grpcmock.WithHeader("locale", "fr-FR"),
+	)
+
+	assert.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42, Locale: "fr-FR"}, actual)
+
+	// Defaults are removed.
+	grpcmock.ResetDefaultInvokeOptions()
+
+	err = grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, &grpctest.Item{})
+
+	assert.Error(t, err)
+}
+
 func TestInvokeServerStream_DialError(t *testing.T) {
 	t.Parallel()
 
@@ -131,7 +1036,372 @@ func TestInvokeServerStream_DialError(t *testing.T) {
 		return nil, errors.New("dial error")
 	}
 
-	err := grpcmock.InvokeServerStream(context.Background(), "Service/NotFound", nil, nil,
+	err := grpcmock.InvokeServerStream(context.Background(), "Service/NotFound", nil, nil,
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+	)
+	expected := `rpc error: code = Unavailable desc = connection error: desc = "transport: Error while dialing dial error"`
+
+	assert.EqualError(t, err, expected)
+}
+
+func TestInvokeServerStream_WithoutInsecure(t *testing.T) {
+	t.Parallel()
+
+	err := grpcmock.InvokeServerStream(context.Background(), "Service/NotFound", nil, nil)
+	expected := "grpc: no transport security set (use grpc.WithTransportCredentials(insecure.NewCredentials()) explicitly or set credentials)"
+
+	assert.EqualError(t, err, expected)
+}
+
+func TestInvokeServerStream_NoHandlerShouldBeFine(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t)
+
+	err := grpcmock.InvokeServerStream(context.Background(), "Service/NotFound", nil, nil,
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+	)
+
+	assert.NoError(t, err)
+}
+
+func TestInvokeServerStream_UnaryMethod(t *testing.T) {
+	t.Parallel()
+
+	item := test.DefaultItem()
+
+	dialer := test.StartServer(t, test.GetItem(func(context.Context, *grpctest.GetItemRequest) (*grpctest.Item, error) {
+		return item, nil
+	}))
+
+	err := grpcmock.InvokeServerStream(context.Background(),
+		"grpctest.ItemService/GetItem",
+		&grpctest.ListItemsRequest{},
+		func(s grpc.ClientStream) error {
+			msg := &grpctest.Item{}
+			err := s.RecvMsg(msg)
+
+			grpcAssert.EqualMessage(t, item, msg)
+			assert.NoError(t, err)
+
+			// Stream is closed.
+			err = s.RecvMsg(msg)
+			assert.ErrorIs(t, err, io.EOF)
+
+			return nil
+		},
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+		grpcmock.WithHeader("Locale", "en-US"),
+	)
+
+	assert.NoError(t, err)
+}
+
+func TestInvokeServerStream_FailToHandle(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.ListItems(func(*grpctest.ListItemsRequest, grpctest.ItemService_ListItemsServer) error {
+		return nil
+	}))
+
+	err := grpcmock.InvokeServerStream(context.Background(), "grpctest.ItemService/ListItems",
+		&grpctest.ListItemsRequest{},
+		func(grpc.ClientStream) error {
+			return errors.New("handle error")
+		},
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+	)
+
+	expected := "/grpctest.ItemService/ListItems: recv response: handle error"
+
+	assert.EqualError(t, err, expected)
+	assert.Equal(t, codes.Unknown, status.Code(err))
+}
+
+func TestInvokeServerStream_Success(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.ListItems(func(_ *grpctest.ListItemsRequest, server grpctest.ItemService_ListItemsServer) error {
+		var locale string
+
+		if md, ok := metadata.FromIncomingContext(server.Context()); ok {
+			if values := md.Get("locale"); len(values) > 0 {
+				locale = values[0]
+			}
+		}
+
+		for _, i := range test.DefaultItems() {
+			i.Locale = locale
+
+			if err := server.Send(i); err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+		}
+
+		return nil
+	}))
+
+	result := make([]*grpctest.Item, 0)
+
+	err := grpcmock.InvokeServerStream(context.Background(),
+		"grpctest.ItemService/ListItems",
+		&grpctest.ListItemsRequest{},
+		grpcmock.RecvAll(&result),
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+		grpcmock.WithHeader("Locale", "en-US"),
+	)
+
+	expected := []*grpctest.Item{
+		{
+			Id:     41,
+			Locale: "en-US",
+			Name:   "Item #41",
+		},
+		{
+			Id:     42,
+			Locale: "en-US",
+			Name:   "Item #42",
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(expected), len(result))
+
+	for i := 0; i < len(expected); i++ {
+		grpcAssert.EqualMessage(t, expected[i], result[i])
+	}
+}
+
+func TestInvokeServerStream_WithStreamHeaderCapture(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.ListItems(func(_ *grpctest.ListItemsRequest, srv grpctest.ItemService_ListItemsServer) error {
+		if err := srv.SendHeader(metadata.Pairs("x-total", "1")); err != nil {
+			return err
+		}
+
+		return srv.Send(&grpctest.Item{Id: 42})
+	}))
+
+	var (
+		header metadata.MD
+		result []*grpctest.Item
+	)
+
+	err := grpcmock.InvokeServerStream(context.Background(),
+		"grpctest.ItemService/ListItems",
+		&grpctest.ListItemsRequest{},
+		grpcmock.RecvAll(&result),
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+		grpcmock.WithStreamHeaderCapture(&header),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, header.Get("x-total"))
+	assert.Len(t, result, 1)
+}
+
+func TestInvokeServerStream_RecvAllT(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.ListItems(func(_ *grpctest.ListItemsRequest, srv grpctest.ItemService_ListItemsServer) error {
+		for _, i := range test.DefaultItems() {
+			if err := srv.Send(i); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}))
+
+	handler, result := grpcmock.RecvAllT[grpctest.Item]()
+
+	err := grpcmock.InvokeServerStream(context.Background(),
+		"grpctest.ItemService/ListItems",
+		&grpctest.ListItemsRequest{},
+		handler,
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+	)
+
+	// The type of the result is checked at compile time.
+	var actual []*grpctest.Item = *result
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, len(test.DefaultItems()))
+
+	for i, expected := range test.DefaultItems() {
+		grpcAssert.EqualMessage(t, expected, actual[i])
+	}
+}
+
+func TestInvokeServerStream_RecvAllT_Error(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.ListItems(func(_ *grpctest.ListItemsRequest, srv grpctest.ItemService_ListItemsServer) error {
+		if err := srv.Send(&grpctest.Item{Id: 41}); err != nil {
+			return err
+		}
+
+		return status.Error(codes.Unavailable, "server is unavailable")
+	}))
+
+	handler, result := grpcmock.RecvAllT[grpctest.Item]()
+
+	err := grpcmock.InvokeServerStream(context.Background(),
+		"grpctest.ItemService/ListItems",
+		&grpctest.ListItemsRequest{},
+		handler,
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+	)
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	require.Len(t, *result, 1)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 41}, (*result)[0])
+}
+
+func TestInvokeServerStream_WithDrainCheck(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		handle        grpcmock.ClientStreamHandler
+		expectedError error
+	}{
+		{
+			scenario: "stream is drained",
+			handle: func(s grpc.ClientStream) error {
+				var result []*grpctest.Item
+
+				return grpcmock.RecvAll(&result)(s)
+			},
+		},
+		{
+			scenario: "stream is not drained",
+			handle: func(s grpc.ClientStream) error {
+				return s.RecvMsg(&grpctest.Item{})
+			},
+			expectedError: grpcErrors.ErrStreamNotDrained,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			dialer := test.StartServer(t, test.ListItems(func(_ *grpctest.ListItemsRequest, srv grpctest.ItemService_ListItemsServer) error {
+				for _, i := range test.DefaultItems() {
+					if err := srv.Send(i); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			}))
+
+			err := grpcmock.InvokeServerStream(context.Background(),
+				"grpctest.ItemService/ListItems",
+				&grpctest.ListItemsRequest{},
+				tc.handle,
+				grpcmock.WithContextDialer(dialer),
+				grpcmock.WithInsecure(),
+				grpcmock.WithDrainCheck(),
+			)
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestInvokeServerStream_WithStreamDesc(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.ListItems(func(_ *grpctest.ListItemsRequest, srv grpctest.ItemService_ListItemsServer) error {
+		return srv.Send(&grpctest.Item{Id: 42})
+	}))
+
+	var actual grpc.StreamDesc
+
+	interceptor := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		actual = *desc
+
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+
+	var result []*grpctest.Item
+
+	err := grpcmock.InvokeServerStream(context.Background(),
+		"grpctest.ItemService/ListItems",
+		&grpctest.ListItemsRequest{},
+		grpcmock.RecvAll(&result),
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+		grpcmock.WithDialOptions(grpc.WithStreamInterceptor(interceptor)),
+		grpcmock.WithStreamDesc(&grpc.StreamDesc{StreamName: "ListItems"}),
+	)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "ListItems", actual.StreamName)
+	assert.True(t, actual.ServerStreams)
+	assert.False(t, actual.ClientStreams)
+}
+
+func TestInvokeServerStream_WithAppendHeaders(t *testing.T) {
+	t.Parallel()
+
+	var actual metadata.MD
+
+	dialer := test.StartServer(t, test.ListItems(func(_ *grpctest.ListItemsRequest, srv grpctest.ItemService_ListItemsServer) error {
+		actual, _ = metadata.FromIncomingContext(srv.Context())
+
+		return nil
+	}))
+
+	interceptor := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-interceptor", "interceptor")
+
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-caller", "caller")
+
+	err := grpcmock.InvokeServerStream(ctx,
+		"grpctest.ItemService/ListItems",
+		&grpctest.ListItemsRequest{},
+		grpcmock.RecvAll(&[]*grpctest.Item{}),
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+		grpcmock.WithDialOptions(grpc.WithStreamInterceptor(interceptor)),
+		grpcmock.WithAppendHeaders(map[string]string{"locale": "en-US"}),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"interceptor"}, actual.Get("x-interceptor"))
+	assert.Equal(t, []string{"caller"}, actual.Get("x-caller"))
+	assert.Equal(t, []string{"en-US"}, actual.Get("locale"))
+}
+
+func TestInvokeClientStream_DialError(t *testing.T) {
+	t.Parallel()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return nil, errors.New("dial error")
+	}
+
+	err := grpcmock.InvokeClientStream(context.Background(), "Service/NotFound", nil, nil,
 		grpcmock.WithContextDialer(dialer),
 		grpcmock.WithInsecure(),
 	)
@@ -140,21 +1410,23 @@ func TestInvokeServerStream_DialError(t *testing.T) {
 	assert.EqualError(t, err, expected)
 }
 
-func TestInvokeServerStream_WithoutInsecure(t *testing.T) {
+func TestInvokeClientStream_WithoutInsecure(t *testing.T) {
 	t.Parallel()
 
-	err := grpcmock.InvokeServerStream(context.Background(), "Service/NotFound", nil, nil)
+	err := grpcmock.InvokeClientStream(context.Background(), "Service/NotFound", nil, nil)
 	expected := "grpc: no transport security set (use grpc.WithTransportCredentials(insecure.NewCredentials()) explicitly or set credentials)"
 
 	assert.EqualError(t, err, expected)
 }
 
-func TestInvokeServerStream_NoHandlerShouldBeFine(t *testing.T) {
+func TestInvokeClientStream_NoHandlerShouldBeFine(t *testing.T) {
 	t.Parallel()
 
-	dialer := test.StartServer(t)
+	dialer := test.StartServer(t, test.CreateItems(func(srv grpctest.ItemService_CreateItemsServer) error {
+		return srv.SendAndClose(&grpctest.CreateItemsResponse{})
+	}))
 
-	err := grpcmock.InvokeServerStream(context.Background(), "Service/NotFound", nil, nil,
+	err := grpcmock.InvokeClientStream(context.Background(), "grpctest.ItemService/CreateItems", nil, &grpctest.CreateItemsResponse{},
 		grpcmock.WithContextDialer(dialer),
 		grpcmock.WithInsecure(),
 	)
@@ -162,163 +1434,268 @@ func TestInvokeServerStream_NoHandlerShouldBeFine(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestInvokeServerStream_UnaryMethod(t *testing.T) {
+func TestInvokeClientStream_FailToHandle(t *testing.T) {
 	t.Parallel()
 
-	item := test.DefaultItem()
-
-	dialer := test.StartServer(t, test.GetItem(func(context.Context, *grpctest.GetItemRequest) (*grpctest.Item, error) {
-		return item, nil
+	dialer := test.StartServer(t, test.CreateItems(func(srv grpctest.ItemService_CreateItemsServer) error {
+		return srv.SendAndClose(&grpctest.CreateItemsResponse{})
 	}))
 
-	err := grpcmock.InvokeServerStream(context.Background(),
-		"grpctest.ItemService/GetItem",
-		&grpctest.ListItemsRequest{},
-		func(s grpc.ClientStream) error {
-			msg := &grpctest.Item{}
-			err := s.RecvMsg(msg)
+	err := grpcmock.InvokeClientStream(context.Background(), "grpctest.ItemService/CreateItems",
+		func(grpc.ClientStream) error {
+			return errors.New("handle error")
+		},
+		&grpctest.CreateItemsResponse{},
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+	)
 
-			grpcAssert.EqualMessage(t, item, msg)
-			assert.NoError(t, err)
+	expected := "/grpctest.ItemService/CreateItems: send: handle error"
 
-			// Stream is closed.
-			err = s.RecvMsg(msg)
-			assert.ErrorIs(t, err, io.EOF)
+	assert.EqualError(t, err, expected)
+}
 
-			return nil
-		},
+func TestInvokeClientStream_RecvResponseError(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.CreateItems(func(srv grpctest.ItemService_CreateItemsServer) error {
+		return status.Error(codes.Internal, "server error")
+	}))
+
+	err := grpcmock.InvokeClientStream(context.Background(), "grpctest.ItemService/CreateItems",
+		grpcmock.SendAll([]*grpctest.Item{}),
+		&grpctest.CreateItemsResponse{},
 		grpcmock.WithContextDialer(dialer),
 		grpcmock.WithInsecure(),
-		grpcmock.WithHeader("Locale", "en-US"),
 	)
 
-	assert.NoError(t, err)
+	expected := "/grpctest.ItemService/CreateItems: recv response: rpc error: code = Internal desc = server error"
+
+	assert.EqualError(t, err, expected)
+	assert.Equal(t, codes.Internal, status.Code(err))
 }
 
-func TestInvokeServerStream_Success(t *testing.T) {
+func TestInvokeClientStream_Success(t *testing.T) {
 	t.Parallel()
 
-	dialer := test.StartServer(t, test.ListItems(func(_ *grpctest.ListItemsRequest, server grpctest.ItemService_ListItemsServer) error {
-		var locale string
+	received := make([]*grpctest.Item, 0)
 
-		if md, ok := metadata.FromIncomingContext(server.Context()); ok {
-			if values := md.Get("locale"); len(values) > 0 {
-				locale = values[0]
-			}
-		}
+	dialer := test.StartServer(t, test.CreateItems(func(srv grpctest.ItemService_CreateItemsServer) error {
+		for {
+			msg, err := srv.Recv()
 
-		for _, i := range test.DefaultItems() {
-			i.Locale = locale
+			if errors.Is(err, io.EOF) {
+				break
+			}
 
-			if err := server.Send(i); err != nil {
-				return status.Error(codes.Internal, err.Error())
+			if err != nil {
+				return err
 			}
+
+			received = append(received, msg)
 		}
 
-		return nil
+		return srv.SendAndClose(&grpctest.CreateItemsResponse{
+			NumItems: int64(len(received)),
+		})
 	}))
 
-	result := make([]*grpctest.Item, 0)
+	items := test.DefaultItems()
+	result := &grpctest.CreateItemsResponse{}
 
-	err := grpcmock.InvokeServerStream(context.Background(),
-		"grpctest.ItemService/ListItems",
-		&grpctest.ListItemsRequest{},
-		grpcmock.RecvAll(&result),
+	err := grpcmock.InvokeClientStream(context.Background(),
+		"grpctest.ItemService/CreateItems",
+		grpcmock.SendAll(items),
+		result,
 		grpcmock.WithContextDialer(dialer),
 		grpcmock.WithInsecure(),
-		grpcmock.WithHeader("Locale", "en-US"),
 	)
 
-	expected := []*grpctest.Item{
-		{
-			Id:     41,
-			Locale: "en-US",
-			Name:   "Item #41",
-		},
-		{
-			Id:     42,
-			Locale: "en-US",
-			Name:   "Item #42",
-		},
-	}
+	expectedResult := &grpctest.CreateItemsResponse{NumItems: int64(len(items))}
 
+	grpcAssert.EqualMessage(t, expectedResult, result)
 	assert.NoError(t, err)
-	assert.Equal(t, len(expected), len(result))
+	assert.Equal(t, len(received), len(items))
 
-	for i := 0; i < len(expected); i++ {
-		grpcAssert.EqualMessage(t, expected[i], result[i])
+	for i := 0; i < len(received); i++ {
+		grpcAssert.EqualMessage(t, received[i], items[i])
 	}
 }
 
-func TestInvokeClientStream_DialError(t *testing.T) {
+func TestInvokeClientStream_SendAllT(t *testing.T) {
 	t.Parallel()
 
-	dialer := func(context.Context, string) (net.Conn, error) {
-		return nil, errors.New("dial error")
-	}
+	received := make([]*grpctest.Item, 0)
 
-	err := grpcmock.InvokeClientStream(context.Background(), "Service/NotFound", nil, nil,
+	dialer := test.StartServer(t, test.CreateItems(func(srv grpctest.ItemService_CreateItemsServer) error {
+		for {
+			item, err := srv.Recv()
+
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				return err
+			}
+
+			received = append(received, item)
+		}
+
+		return srv.SendAndClose(&grpctest.CreateItemsResponse{NumItems: int64(len(received))})
+	}))
+
+	items := []*grpctest.Item{{Id: 41}, {Id: 42}}
+	result := &grpctest.CreateItemsResponse{}
+
+	err := grpcmock.InvokeClientStream(context.Background(),
+		"grpctest.ItemService/CreateItems",
+		grpcmock.SendAllT(items),
+		result,
 		grpcmock.WithContextDialer(dialer),
 		grpcmock.WithInsecure(),
 	)
-	expected := `rpc error: code = Unavailable desc = connection error: desc = "transport: Error while dialing dial error"`
 
-	assert.EqualError(t, err, expected)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.NumItems)
+	assert.Len(t, received, len(items))
+
+	for i := range items {
+		grpcAssert.EqualMessage(t, items[i], received[i])
+	}
 }
 
-func TestInvokeClientStream_WithoutInsecure(t *testing.T) {
+func TestInvokeClientStream_SendAllOf(t *testing.T) {
 	t.Parallel()
 
-	err := grpcmock.InvokeClientStream(context.Background(), "Service/NotFound", nil, nil)
-	expected := "grpc: no transport security set (use grpc.WithTransportCredentials(insecure.NewCredentials()) explicitly or set credentials)"
+	testCases := []struct {
+		scenario      string
+		method        string
+		items         interface{}
+		expectedError string
+	}{
+		{
+			scenario: "matched",
+			method:   "grpctest.ItemService/CreateItems",
+			items:    []*grpctest.Item{{Id: 41}, {Id: 42}},
+		},
+		{
+			scenario:      "mismatched",
+			method:        "grpctest.ItemService/CreateItems",
+			items:         []*grpctest.GetItemRequest{{Id: 41}, {Id: 42}},
+			expectedError: "send type mismatch: message #0 of /grpctest.ItemService/CreateItems, got grpctest.GetItemRequest, want grpctest.Item",
+		},
+		{
+			scenario:      "method not found",
+			method:        "grpctest.ItemService/DeleteItems",
+			items:         []*grpctest.Item{{Id: 41}, {Id: 42}},
+			expectedError: "method not found: /grpctest.ItemService/DeleteItems",
+		},
+		{
+			scenario:      "method of another service",
+			method:        "grpctest.OtherService/CreateItems",
+			items:         []*grpctest.Item{{Id: 41}, {Id: 42}},
+			expectedError: "method not found: /grpctest.OtherService/CreateItems",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			dialer := test.StartServer(t, test.CreateItems(func(srv grpctest.ItemService_CreateItemsServer) error {
+				received := 0
+
+				for {
+					_, err := srv.Recv()
+
+					if errors.Is(err, io.EOF) {
+						break
+					}
+
+					if err != nil {
+						return err
+					}
+
+					received++
+				}
+
+				return srv.SendAndClose(&grpctest.CreateItemsResponse{NumItems: int64(received)})
+			}))
+
+			result := &grpctest.CreateItemsResponse{}
+
+			err := grpcmock.InvokeClientStream(context.Background(),
+				"grpctest.ItemService/CreateItems",
+				grpcmock.SendAllOf((*grpctest.ItemServiceServer)(nil), tc.method, tc.items),
+				result,
+				grpcmock.WithContextDialer(dialer),
+				grpcmock.WithInsecure(),
+			)
 
-	assert.EqualError(t, err, expected)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(2), result.NumItems)
+			} else {
+				assert.ErrorContains(t, err, tc.expectedError)
+			}
+		})
+	}
 }
 
-func TestInvokeClientStream_NoHandlerShouldBeFine(t *testing.T) {
+func TestInvokeClientStream_SendAllWithDelay(t *testing.T) {
 	t.Parallel()
 
+	const delay = 50 * time.Millisecond
+
 	dialer := test.StartServer(t, test.CreateItems(func(srv grpctest.ItemService_CreateItemsServer) error {
-		return srv.SendAndClose(&grpctest.CreateItemsResponse{})
-	}))
+		var numItems int64
 
-	err := grpcmock.InvokeClientStream(context.Background(), "grpctest.ItemService/CreateItems", nil, &grpctest.CreateItemsResponse{},
-		grpcmock.WithContextDialer(dialer),
-		grpcmock.WithInsecure(),
-	)
+		for {
+			_, err := srv.Recv()
 
-	assert.NoError(t, err)
-}
+			if errors.Is(err, io.EOF) {
+				break
+			}
 
-func TestInvokeClientStream_FailToHandle(t *testing.T) {
-	t.Parallel()
+			if err != nil {
+				return err
+			}
 
-	dialer := test.StartServer(t, test.CreateItems(func(srv grpctest.ItemService_CreateItemsServer) error {
-		return srv.SendAndClose(&grpctest.CreateItemsResponse{})
+			numItems++
+		}
+
+		return srv.SendAndClose(&grpctest.CreateItemsResponse{NumItems: numItems})
 	}))
 
-	err := grpcmock.InvokeClientStream(context.Background(), "grpctest.ItemService/CreateItems",
-		func(grpc.ClientStream) error {
-			return errors.New("handle error")
-		},
-		&grpctest.CreateItemsResponse{},
+	items := []*grpctest.Item{{Id: 40}, {Id: 41}, {Id: 42}}
+	result := &grpctest.CreateItemsResponse{}
+	start := time.Now()
+
+	err := grpcmock.InvokeClientStream(context.Background(),
+		"grpctest.ItemService/CreateItems",
+		grpcmock.SendAllWithDelay(items, delay),
+		result,
 		grpcmock.WithContextDialer(dialer),
 		grpcmock.WithInsecure(),
 	)
 
-	expected := errors.New("handle error")
+	expectedResult := &grpctest.CreateItemsResponse{NumItems: int64(len(items))}
 
-	assert.Equal(t, expected, err)
+	grpcAssert.EqualMessage(t, expectedResult, result)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Duration(len(items)-1)*delay)
 }
 
-func TestInvokeClientStream_Success(t *testing.T) {
+func TestInvokeClientStream_WithStreamHeaderCapture(t *testing.T) {
 	t.Parallel()
 
-	received := make([]*grpctest.Item, 0)
-
 	dialer := test.StartServer(t, test.CreateItems(func(srv grpctest.ItemService_CreateItemsServer) error {
+		var numItems int64
+
 		for {
-			msg, err := srv.Recv()
+			_, err := srv.Recv()
 
 			if errors.Is(err, io.EOF) {
 				break
@@ -328,15 +1705,19 @@ func TestInvokeClientStream_Success(t *testing.T) {
 				return err
 			}
 
-			received = append(received, msg)
+			numItems++
 		}
 
-		return srv.SendAndClose(&grpctest.CreateItemsResponse{
-			NumItems: int64(len(received)),
-		})
+		if err := srv.SetHeader(metadata.Pairs("x-created", fmt.Sprintf("%d", numItems))); err != nil {
+			return err
+		}
+
+		return srv.SendAndClose(&grpctest.CreateItemsResponse{NumItems: numItems})
 	}))
 
-	items := test.DefaultItems()
+	var header metadata.MD
+
+	items := []*grpctest.Item{{Id: 41}, {Id: 42}}
 	result := &grpctest.CreateItemsResponse{}
 
 	err := grpcmock.InvokeClientStream(context.Background(),
@@ -345,17 +1726,36 @@ func TestInvokeClientStream_Success(t *testing.T) {
 		result,
 		grpcmock.WithContextDialer(dialer),
 		grpcmock.WithInsecure(),
+		grpcmock.WithStreamHeaderCapture(&header),
 	)
 
-	expectedResult := &grpctest.CreateItemsResponse{NumItems: int64(len(items))}
-
-	grpcAssert.EqualMessage(t, expectedResult, result)
 	assert.NoError(t, err)
-	assert.Equal(t, len(received), len(items))
+	assert.Equal(t, []string{"2"}, header.Get("x-created"))
+	assert.Equal(t, int64(2), result.NumItems)
+}
 
-	for i := 0; i < len(received); i++ {
-		grpcAssert.EqualMessage(t, received[i], items[i])
-	}
+func TestInvokeClientStream_WithHeader(t *testing.T) {
+	t.Parallel()
+
+	var actual metadata.MD
+
+	dialer := test.StartServer(t, test.CreateItems(func(srv grpctest.ItemService_CreateItemsServer) error {
+		actual, _ = metadata.FromIncomingContext(srv.Context())
+
+		return srv.SendAndClose(&grpctest.CreateItemsResponse{})
+	}))
+
+	err := grpcmock.InvokeClientStream(context.Background(),
+		"grpctest.ItemService/CreateItems",
+		grpcmock.SendAll([]*grpctest.Item{}),
+		&grpctest.CreateItemsResponse{},
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+		grpcmock.WithHeader("locale", "en-US"),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"en-US"}, actual.Get("locale"))
 }
 
 func TestInvokeBidirectionalStream_DialError(t *testing.T) {
@@ -474,6 +1874,106 @@ func TestInvokeBidirectionalStream_Success(t *testing.T) {
 	}
 }
 
+func TestInvokeBidirectionalStream_WithMessageTap(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.TransformItems(func(srv grpctest.ItemService_TransformItemsServer) error {
+		for {
+			msg, err := srv.Recv()
+
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			msg.Name = fmt.Sprintf("Modified %s", msg.Name)
+
+			if err := srv.SendMsg(msg); err != nil {
+				return err
+			}
+		}
+	}))
+
+	var (
+		mu       sync.Mutex
+		sent     []string
+		received []string
+	)
+
+	logMessage := func(log *[]string) func(interface{}) {
+		return func(msg interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			item := msg.(*grpctest.Item) // nolint: errcheck
+
+			*log = append(*log, item.Name)
+
+			// The tap must not change the message.
+			item.Name = "tapped"
+		}
+	}
+
+	items := []*grpctest.Item{{Id: 1, Name: "Foo"}, {Id: 2, Name: "Bar"}, {Id: 3, Name: "Baz"}}
+	result := make([]*grpctest.Item, 0)
+
+	err := grpcmock.InvokeBidirectionalStream(context.Background(),
+		"grpctest.ItemService/TransformItems",
+		grpcmock.SendAndRecvAll(items, &result),
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+		grpcmock.WithMessageTap(logMessage(&sent), logMessage(&received)),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Foo", "Bar", "Baz"}, sent)
+	assert.Equal(t, []string{"Modified Foo", "Modified Bar", "Modified Baz"}, received)
+
+	expected := []*grpctest.Item{
+		{Id: 1, Name: "Modified Foo"},
+		{Id: 2, Name: "Modified Bar"},
+		{Id: 3, Name: "Modified Baz"},
+	}
+
+	assert.Equal(t, len(expected), len(result))
+
+	for i := 0; i < len(expected); i++ {
+		grpcAssert.EqualMessage(t, expected[i], result[i])
+	}
+}
+
+func TestInvokeUnary_WithMessageTap(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.GetItem(func(_ context.Context, request *grpctest.GetItemRequest) (*grpctest.Item, error) {
+		return &grpctest.Item{Id: request.Id}, nil
+	}))
+
+	var sent, received []interface{}
+
+	out := &grpctest.Item{}
+
+	err := grpcmock.InvokeUnary(context.Background(), "grpctest.ItemService/GetItem", &grpctest.GetItemRequest{Id: 42}, out,
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+		grpcmock.WithMessageTap(
+			func(msg interface{}) { sent = append(sent, msg) },
+			func(msg interface{}) { received = append(received, msg) },
+		),
+	)
+
+	assert.NoError(t, err)
+	assert.Len(t, sent, 1)
+	assert.Len(t, received, 1)
+
+	grpcAssert.EqualMessage(t, &grpctest.GetItemRequest{Id: 42}, sent[0].(proto.Message))
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, received[0].(proto.Message))
+	assert.NotSame(t, out, received[0])
+}
+
 func TestSendAll(t *testing.T) {
 	t.Parallel()
 
@@ -628,8 +2128,13 @@ func TestRecvAll(t *testing.T) {
 		t.Run(tc.scenario, func(t *testing.T) {
 			t.Parallel()
 
+			s := tc.mockStream(t)
+
+			s.On("Context").Maybe().
+				Return(context.Background())
+
 			result := tc.output
-			err := grpcmock.RecvAll(result)(tc.mockStream(t))
+			err := grpcmock.RecvAll(result)(s)
 
 			grpcAssert.JSONEq(t, tc.expectedOutput, result)
 
@@ -772,3 +2277,199 @@ func TestSendAndRecvAll_Success(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorDetails(t *testing.T) {
+	t.Parallel()
+
+	retryInfo := &errdetails.RetryInfo{}
+
+	st, err := status.New(codes.Unavailable, "unavailable").WithDetails(retryInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		scenario        string
+		err             error
+		expectedDetails []proto.Message
+	}{
+		{
+			scenario: "no error",
+		},
+		{
+			scenario: "not a status error",
+			err:      errors.New("error"),
+		},
+		{
+			scenario:        "no details",
+			err:             status.Error(codes.Internal, "internal"),
+			expectedDetails: []proto.Message{},
+		},
+		{
+			scenario:        "details",
+			err:             st.Err(),
+			expectedDetails: []proto.Message{retryInfo},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			details, err := grpcmock.ErrorDetails(tc.err)
+
+			assert.NoError(t, err)
+			assert.Len(t, details, len(tc.expectedDetails))
+
+			for i := range details {
+				grpcAssert.EqualMessage(t, tc.expectedDetails[i], details[i])
+			}
+
+			if tc.expectedDetails == nil {
+				assert.Nil(t, details)
+			}
+		})
+	}
+}
+
+func TestClassifyStreamError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario     string
+		err          error
+		expectedKind grpcmock.StreamErrorKind
+	}{
+		{
+			scenario:     "no error",
+			expectedKind: grpcmock.StreamErrorNone,
+		},
+		{
+			scenario:     "eof",
+			err:          io.EOF,
+			expectedKind: grpcmock.StreamErrorEOF,
+		},
+		{
+			scenario:     "wrapped eof",
+			err:          fmt.Errorf("could not receive: %w", io.EOF),
+			expectedKind: grpcmock.StreamErrorEOF,
+		},
+		{
+			scenario:     "status",
+			err:          status.Error(codes.NotFound, "not found"),
+			expectedKind: grpcmock.StreamErrorStatus,
+		},
+		{
+			scenario:     "context canceled",
+			err:          context.Canceled,
+			expectedKind: grpcmock.StreamErrorContext,
+		},
+		{
+			scenario:     "canceled status",
+			err:          status.Error(codes.Canceled, "context canceled"),
+			expectedKind: grpcmock.StreamErrorContext,
+		},
+		{
+			scenario:     "deadline exceeded status",
+			err:          status.Error(codes.DeadlineExceeded, "context deadline exceeded"),
+			expectedKind: grpcmock.StreamErrorContext,
+		},
+		{
+			scenario:     "transport",
+			err:          &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")},
+			expectedKind: grpcmock.StreamErrorTransport,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expectedKind, grpcmock.ClassifyStreamError(tc.err))
+		})
+	}
+}
+
+func TestHasCode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		err      error
+		code     codes.Code
+		expected bool
+	}{
+		{
+			scenario: "no error is ok",
+			code:     codes.OK,
+			expected: true,
+		},
+		{
+			scenario: "no error is not an error code",
+			code:     codes.NotFound,
+		},
+		{
+			scenario: "matching code",
+			err:      status.Error(codes.NotFound, "not found"),
+			code:     codes.NotFound,
+			expected: true,
+		},
+		{
+			scenario: "mismatching code",
+			err:      status.Error(codes.NotFound, "not found"),
+			code:     codes.Internal,
+		},
+		{
+			scenario: "error is not ok",
+			err:      status.Error(codes.NotFound, "not found"),
+			code:     codes.OK,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, grpcmock.HasCode(tc.err, tc.code))
+
+			test := &recordingT{}
+
+			assert.Equal(t, tc.expected, grpcmock.AssertCode(test, tc.err, tc.code))
+			assert.Equal(t, !tc.expected, len(test.recordedErrors()) > 0)
+		})
+	}
+}
+
+func TestAssertCode_Message(t *testing.T) {
+	t.Parallel()
+
+	test := &recordingT{}
+
+	grpcmock.AssertCode(test, status.Error(codes.NotFound, "not found"), codes.OK)
+
+	require.Len(t, test.recordedErrors(), 1)
+	assert.Contains(t, test.recordedErrors()[0], "unexpected gRPC code, got NotFound, want OK, error: rpc error: code = NotFound desc = not found")
+}
+
+func TestClassifyStreamError_InvokeServerStream(t *testing.T) {
+	t.Parallel()
+
+	dialer := test.StartServer(t, test.ListItems(func(*grpctest.ListItemsRequest, grpctest.ItemService_ListItemsServer) error {
+		return status.Error(codes.NotFound, "not found")
+	}))
+
+	out := make([]*grpctest.Item, 0)
+
+	err := grpcmock.InvokeServerStream(context.Background(), "grpctest.ItemService/ListItems",
+		&grpctest.ListItemsRequest{},
+		grpcmock.RecvAll(&out),
+		grpcmock.WithContextDialer(dialer),
+		grpcmock.WithInsecure(),
+	)
+
+	require.Error(t, err)
+	assert.Equal(t, grpcmock.StreamErrorStatus, grpcmock.ClassifyStreamError(err))
+}
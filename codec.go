@@ -0,0 +1,56 @@
+package grpcmock
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/nhatthm/grpcmock/errors"
+	"github.com/nhatthm/grpcmock/internal/rawpayload"
+)
+
+var _ encoding.Codec = (*rawBytesCodec)(nil)
+
+// rawBytesCodec is a proto codec that also works with []byte, the bytes are sent and received as is. When it decodes a
+// request into a *rawpayload.Message, it also keeps the wire bytes for matcher.RawBytes().
+type rawBytesCodec struct{}
+
+// Marshal satisfies encoding.Codec.
+func (rawBytesCodec) Marshal(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case []byte:
+		return v, nil
+
+	case proto.Message:
+		return proto.Marshal(v)
+	}
+
+	return nil, fmt.Errorf("%w: got %T, want proto.Message or []byte", errors.ErrUnsupportedDataType, v)
+}
+
+// Unmarshal satisfies encoding.Codec.
+func (rawBytesCodec) Unmarshal(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case *[]byte:
+		*v = append([]byte(nil), data...)
+
+		return nil
+
+	case *rawpayload.Message:
+		// The bytes are never nil, even if the request is empty.
+		v.Raw = append(make([]byte, 0, len(data)), data...)
+
+		return proto.Unmarshal(data, v.Message)
+
+	case proto.Message:
+		return proto.Unmarshal(data, v)
+	}
+
+	return fmt.Errorf("%w: got %T, want proto.Message or *[]byte", errors.ErrUnsupportedDataType, v)
+}
+
+// Name satisfies encoding.Codec.
+func (rawBytesCodec) Name() string {
+	return "proto"
+}
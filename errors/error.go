@@ -1,6 +1,8 @@
 package errors
 
 import (
+	"errors"
+
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -9,8 +11,42 @@ const (
 	// ErrUnsupportedDataType represents that the data type is not supported.
 	ErrUnsupportedDataType err = "unsupported data type"
 
+	// ErrFieldNotFound indicates that the field is not described in the message.
+	ErrFieldNotFound err = "field not found"
+
+	// ErrMissingInputType indicates that the input type is not provided.
+	ErrMissingInputType err = "missing input type"
+	// ErrTypeMismatch indicates that the type of the input or the output does not match the method.
+	ErrTypeMismatch err = "type mismatch"
+	// ErrResponseTypeMismatch indicates that the response is not of the output type of the method.
+	ErrResponseTypeMismatch err = "response type mismatch"
+
+	// ErrStreamNotDrained indicates that there are still messages in the stream after the handler finished.
+	ErrStreamNotDrained err = "stream is not drained"
+
+	// ErrNotMatched indicates that a sub-matcher of a combinator does not match.
+	ErrNotMatched err = "does not match"
+	// ErrNoneMatched indicates that none of the sub-matchers of a combinator matches.
+	ErrNoneMatched err = "none of the matchers matches"
+
+	// ErrRawBytesUnavailable indicates that the wire bytes of the request are not kept by the server.
+	ErrRawBytesUnavailable err = "raw bytes are not available, the server must be started with WithRawBytesCodec()"
+
+	// ErrCodecNotRegistered indicates that the codec is not registered with encoding.RegisterCodec().
+	ErrCodecNotRegistered err = "codec is not registered"
+
+	// ErrSequenceExhausted indicates that all the responses in the sequence are returned.
+	ErrSequenceExhausted err = "sequence is exhausted"
+
+	// ErrConnectionReset indicates that the server resets the connection of the request.
+	ErrConnectionReset err = "connection reset"
+
 	// ErrMalformedMethod indicates that the method is malformed.
 	ErrMalformedMethod err = "malformed method"
+	// ErrInvalidMethodPath indicates that the method path is not in the form of "service/method".
+	ErrInvalidMethodPath err = "invalid method path"
+	// ErrServiceNotFound indicates that the GRPC service is not described in the server.
+	ErrServiceNotFound err = "service not found"
 	// ErrMethodNotFound indicates that the GRPC method is not described in the server.
 	ErrMethodNotFound err = "method not found"
 	// ErrMethodNotUnary indicates that the GRPC method is not a unary kind.
@@ -19,6 +55,8 @@ const (
 	ErrMethodNotClientStream err = "method is not client-stream"
 	// ErrMethodNotServerStream indicates that the GRPC method is not a server-stream kind.
 	ErrMethodNotServerStream err = "method is not server-stream"
+	// ErrUnsupportedMethodType indicates that the operation does not support the type of the GRPC method.
+	ErrUnsupportedMethodType err = "unsupported method type"
 	// ErrMethodNotBidirectionalStream indicates that the GRPC method is not a bidirectional-stream kind.
 	ErrMethodNotBidirectionalStream err = "method is not bidirectional-stream"
 )
@@ -30,17 +68,20 @@ func (e err) Error() string {
 	return string(e)
 }
 
-// StatusError converts error to status.Error if applicable.
+// StatusError converts error to status.Error if applicable. The errors that already carry a status are returned as is,
+// and so is ErrConnectionReset because the server resets the connection instead of sending a status.
 func StatusError(err error) error {
 	if err == nil {
 		return nil
 	}
 
-	code := status.Code(err)
+	if errors.Is(err, ErrConnectionReset) {
+		return err
+	}
 
-	if code == codes.Unknown {
-		return status.Error(codes.Internal, err.Error())
+	if _, ok := status.FromError(err); ok {
+		return err
 	}
 
-	return err
+	return status.Error(codes.Internal, err.Error())
 }
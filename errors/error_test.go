@@ -35,6 +35,11 @@ func TestStatusError(t *testing.T) {
 			err:      errors.New("error"),
 			expected: status.Error(codes.Internal, "error"),
 		},
+		{
+			scenario: "connection reset",
+			err:      ErrConnectionReset,
+			expected: ErrConnectionReset,
+		},
 		{
 			scenario: "status ok",
 			err:      status.Error(codes.OK, ""),
@@ -44,6 +49,11 @@ func TestStatusError(t *testing.T) {
 			err:      status.Error(codes.Internal, "internal"),
 			expected: status.Error(codes.Internal, "internal"),
 		},
+		{
+			scenario: "status unknown",
+			err:      status.Error(codes.Unknown, "unknown"),
+			expected: status.Error(codes.Unknown, "unknown"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -2,6 +2,7 @@ package grpcmock
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 
 	"google.golang.org/grpc"
@@ -20,3 +21,20 @@ func serviceSorter(services []*grpc.ServiceDesc) ([]*grpc.ServiceDesc, func(i, j
 func closeNothing() error {
 	return nil
 }
+
+// toMessages converts a slice of messages to a []interface{}.
+func toMessages(v interface{}) []interface{} {
+	val := reflect.ValueOf(v)
+
+	if val.Kind() != reflect.Slice {
+		return nil
+	}
+
+	result := make([]interface{}, val.Len())
+
+	for i := range result {
+		result[i] = val.Index(i).Interface()
+	}
+
+	return result
+}
@@ -0,0 +1,2 @@
+// Package rawpayload carries the wire bytes of the requests, so they could be matched after decoding.
+package rawpayload
@@ -0,0 +1,35 @@
+package rawpayload
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type ctxKey struct{}
+
+// Message is a decoded request with its wire bytes. The raw bytes codec decodes into it, and the proto matchers receive
+// it instead of the bare request when the wire bytes are available.
+type Message struct {
+	proto.Message
+
+	Raw []byte
+}
+
+// MarshalJSON marshals the request without its wire bytes.
+func (m *Message) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Message)
+}
+
+// NewContext attaches the wire bytes of the request to the context of the call.
+func NewContext(ctx context.Context, data []byte) context.Context {
+	return context.WithValue(ctx, ctxKey{}, data)
+}
+
+// FromContext returns the wire bytes of the request of the call.
+func FromContext(ctx context.Context) ([]byte, bool) {
+	data, ok := ctx.Value(ctxKey{}).([]byte)
+
+	return data, ok
+}
@@ -1,7 +1,9 @@
 package grpcmock
 
 import (
+	"errors"
 	"net"
+	"os"
 	"sync"
 
 	"github.com/nhatthm/grpcmock/must"
@@ -13,6 +15,10 @@ type listener struct {
 	upstream   net.Listener
 	signal     chan struct{}
 	sendSignal sync.Once
+
+	// conns holds the open connections, so they could be reset by closeConns().
+	mu    sync.Mutex
+	conns map[*trackedConn]struct{}
 }
 
 // Accept waits for and returns the next connection to the listener.
@@ -21,7 +27,19 @@ func (l *listener) Accept() (net.Conn, error) {
 		close(l.signal)
 	})
 
-	return l.upstream.Accept()
+	conn, err := l.upstream.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &trackedConn{Conn: conn, listener: l}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.conns[c] = struct{}{}
+
+	return c, nil
 }
 
 // Close closes the listener.
@@ -43,11 +61,65 @@ func newListenerByAddr(addr string) func() (net.Listener, func() error) {
 	}
 }
 
+func newListenerByUnixSocket(path string) func() (net.Listener, func() error) {
+	return func() (net.Listener, func() error) {
+		l, err := net.Listen("unix", path)
+		must.NotFail(err)
+
+		return l, func() error {
+			_ = l.Close() // nolint: errcheck
+
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+
+			return nil
+		}
+	}
+}
+
 func newListenerWithReadySignal(upstream net.Listener) (net.Listener, <-chan struct{}) {
 	signal := make(chan struct{})
 
 	return &listener{
 		upstream: upstream,
 		signal:   signal,
+		conns:    make(map[*trackedConn]struct{}),
 	}, signal
 }
+
+// closeConns closes the open connections of the remote address without going through the grpc server, so the clients
+// see a transport failure.
+func (l *listener) closeConns(addr net.Addr) {
+	l.mu.Lock()
+
+	conns := make([]*trackedConn, 0, len(l.conns))
+
+	for c := range l.conns {
+		if r := c.RemoteAddr(); r.Network() == addr.Network() && r.String() == addr.String() {
+			conns = append(conns, c)
+		}
+	}
+
+	l.mu.Unlock()
+
+	for _, c := range conns {
+		_ = c.Close() // nolint: errcheck
+	}
+}
+
+// trackedConn removes itself from the listener when it is closed.
+type trackedConn struct {
+	net.Conn
+
+	listener *listener
+}
+
+// Close closes the connection.
+func (c *trackedConn) Close() error {
+	c.listener.mu.Lock()
+	delete(c.listener.conns, c)
+	c.listener.mu.Unlock()
+
+	return c.Conn.Close()
+}
@@ -0,0 +1,8 @@
+package grpcmock
+
+// Logger logs a structured record as key-value pairs, for example a go-kit logger.
+//
+// See: WithLogger(), WithServerLogger().
+type Logger interface {
+	Log(keyvals ...interface{})
+}
@@ -0,0 +1,73 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nhatthm/go-matcher"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/nhatthm/grpcmock/errors"
+)
+
+var _ matcher.Matcher = (*AnyMessageMatcher)(nil)
+
+// AnyMessageMatcher unpacks a google.protobuf.Any and matches the message inside.
+type AnyMessageMatcher struct {
+	expected proto.Message
+}
+
+// Match satisfies the matcher.Matcher interface.
+func (m *AnyMessageMatcher) Match(actual interface{}) (bool, error) {
+	a, ok := actual.(*anypb.Any)
+	if !ok {
+		return false, fmt.Errorf("%w: got %T, want *anypb.Any", errors.ErrUnsupportedDataType, actual)
+	}
+
+	if !a.MessageIs(m.expected) {
+		return false, fmt.Errorf("%w: got %s, want %s", errors.ErrTypeMismatch, a.MessageName(), m.expected.ProtoReflect().Descriptor().FullName())
+	}
+
+	msg := m.expected.ProtoReflect().New().Interface()
+
+	if err := a.UnmarshalTo(msg); err != nil {
+		return false, err
+	}
+
+	return proto.Equal(m.expected, msg), nil
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *AnyMessageMatcher) Expected() string {
+	name := m.expected.ProtoReflect().Descriptor().FullName()
+
+	b, err := json.Marshal(m.expected)
+	if err != nil {
+		return fmt.Sprintf("any of %s", name)
+	}
+
+	return fmt.Sprintf("any of %s %s", name, string(b))
+}
+
+// AnyMessage matches a google.protobuf.Any that holds a message equal to the expected one. It returns an
+// errors.ErrTypeMismatch if the Any holds a message of a different type.
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItem").
+//    	WithPayload(matcher.Field("details", matcher.AnyMessage(&grpctest.Item{Id: 42})))
+//
+// See: AnyField().
+func AnyMessage(expected proto.Message) *AnyMessageMatcher {
+	return &AnyMessageMatcher{expected: expected}
+}
+
+// AnyField matches a google.protobuf.Any field of a proto message, the Any is unpacked and compared with the expected
+// message. Nested fields could be given using the dot notation.
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItem").
+//    	WithPayload(matcher.AnyField("details", &grpctest.Item{Id: 42}))
+//
+// See: AnyMessage(), Field().
+func AnyField(path string, expected proto.Message) *FieldMatcher {
+	return Field(path, AnyMessage(expected))
+}
@@ -0,0 +1,105 @@
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/nhatthm/go-matcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/typepb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	grpcMatcher "github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestAnyField(t *testing.T) {
+	t.Parallel()
+
+	newOption := func(t *testing.T, v *grpctest.Item) *typepb.Option {
+		t.Helper()
+
+		value, err := anypb.New(v)
+		require.NoError(t, err)
+
+		return &typepb.Option{Name: "item", Value: value}
+	}
+
+	testCases := []struct {
+		scenario       string
+		matcher        matcher.Matcher
+		actual         func(t *testing.T) interface{}
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			scenario: "match",
+			matcher:  grpcMatcher.AnyField("value", &grpctest.Item{Id: 42, Name: "Foobar"}),
+			actual: func(t *testing.T) interface{} {
+				t.Helper()
+
+				return newOption(t, &grpctest.Item{Id: 42, Name: "Foobar"})
+			},
+			expectedResult: true,
+		},
+		{
+			scenario: "mismatch",
+			matcher:  grpcMatcher.AnyField("value", &grpctest.Item{Id: 42, Name: "Foobar"}),
+			actual: func(t *testing.T) interface{} {
+				t.Helper()
+
+				return newOption(t, &grpctest.Item{Id: 42, Name: "Baz"})
+			},
+		},
+		{
+			scenario: "different type",
+			matcher:  grpcMatcher.AnyField("value", &grpctest.Item{Id: 42}),
+			actual: func(t *testing.T) interface{} {
+				t.Helper()
+
+				value, err := anypb.New(wrapperspb.Int32(42))
+				require.NoError(t, err)
+
+				return &typepb.Option{Value: value}
+			},
+			expectedError: "type mismatch: got google.protobuf.Int32Value, want grpctest.Item",
+		},
+		{
+			scenario: "not an any",
+			matcher:  grpcMatcher.AnyField("name", &grpctest.Item{Id: 42}),
+			actual: func(t *testing.T) interface{} {
+				t.Helper()
+
+				return &typepb.Option{Name: "item"}
+			},
+			expectedError: "unsupported data type: got string, want *anypb.Any",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := tc.matcher.Match(tc.actual(t))
+
+			assert.Equal(t, tc.expectedResult, result)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestAnyMessageMatcher_Expected(t *testing.T) {
+	t.Parallel()
+
+	m := grpcMatcher.AnyMessage(&grpctest.Item{Id: 42})
+
+	assert.Equal(t, `any of grpctest.Item {"id":42}`, m.Expected())
+}
@@ -0,0 +1,183 @@
+package matcher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nhatthm/go-matcher"
+
+	"github.com/nhatthm/grpcmock/errors"
+	"github.com/nhatthm/grpcmock/value"
+)
+
+var (
+	_ ProtoMatcher = (*AllOfMatcher)(nil)
+	_ ProtoMatcher = (*AnyOfMatcher)(nil)
+	_ ProtoMatcher = (*NotMatcher)(nil)
+)
+
+// AllOfMatcher matches if all the matchers match.
+type AllOfMatcher struct {
+	matchers []matcher.Matcher
+}
+
+// Match satisfies the matcher.Matcher interface.
+func (m *AllOfMatcher) Match(actual interface{}) (bool, error) {
+	for i, sub := range m.matchers {
+		matched, err := matchSub(sub, actual)
+		if err != nil {
+			return false, fmt.Errorf("matcher #%d %s failed: %w", i, describeMatcher(sub), err)
+		}
+
+		if !matched {
+			return false, fmt.Errorf("matcher #%d %s %w", i, describeMatcher(sub), errors.ErrNotMatched)
+		}
+	}
+
+	return true, nil
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *AllOfMatcher) Expected() string {
+	return fmt.Sprintf("all of %s", describeMatchers(m.matchers))
+}
+
+// MatchProto satisfies the ProtoMatcher interface.
+func (m *AllOfMatcher) MatchProto() bool {
+	return anyProtoMatcher(m.matchers...)
+}
+
+// AnyOfMatcher matches if at least one of the matchers matches.
+type AnyOfMatcher struct {
+	matchers []matcher.Matcher
+}
+
+// Match satisfies the matcher.Matcher interface.
+func (m *AnyOfMatcher) Match(actual interface{}) (bool, error) {
+	var errs []string
+
+	for i, sub := range m.matchers {
+		matched, err := matchSub(sub, actual)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("matcher #%d %s failed: %s", i, describeMatcher(sub), err.Error()))
+
+			continue
+		}
+
+		if matched {
+			return true, nil
+		}
+	}
+
+	if len(errs) > 0 {
+		return false, fmt.Errorf("%w: %s", errors.ErrNoneMatched, strings.Join(errs, "; "))
+	}
+
+	return false, nil
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *AnyOfMatcher) Expected() string {
+	return fmt.Sprintf("any of %s", describeMatchers(m.matchers))
+}
+
+// MatchProto satisfies the ProtoMatcher interface.
+func (m *AnyOfMatcher) MatchProto() bool {
+	return anyProtoMatcher(m.matchers...)
+}
+
+// NotMatcher matches if the matcher does not match.
+type NotMatcher struct {
+	matcher matcher.Matcher
+}
+
+// Match satisfies the matcher.Matcher interface.
+func (m *NotMatcher) Match(actual interface{}) (bool, error) {
+	matched, err := matchSub(m.matcher, actual)
+	if err != nil {
+		return false, err
+	}
+
+	return !matched, nil
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *NotMatcher) Expected() string {
+	return fmt.Sprintf("not %s", describeMatcher(m.matcher))
+}
+
+// MatchProto satisfies the ProtoMatcher interface.
+func (m *NotMatcher) MatchProto() bool {
+	return IsProtoMatcher(m.matcher)
+}
+
+// AllOf matches if all the matchers match. The matchers are evaluated in order and the first one that does not match
+// is reported in the error. The proto matchers, such as Field() or ExactExcept(), receive the proto message of the
+// request while the other matchers receive the request payload in json, so they can be freely combined.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	WithPayload(matcher.AllOf(
+//    		matcher.JSON(`{"id": "<ignore-diff>"}`),
+//    		matcher.Not(matcher.Field("name", matcher.RegexPattern(`^Deleted`))),
+//    	))
+func AllOf(ms ...matcher.Matcher) *AllOfMatcher {
+	return &AllOfMatcher{matchers: ms}
+}
+
+// AnyOf matches if at least one of the matchers matches.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	WithPayload(matcher.AnyOf(
+//    		matcher.JSON(`{"id": 41}`),
+//    		matcher.JSON(`{"id": 42}`),
+//    	))
+//
+// See: AllOf().
+func AnyOf(ms ...matcher.Matcher) *AnyOfMatcher {
+	return &AnyOfMatcher{matchers: ms}
+}
+
+// Not matches if the matcher does not match. The error of the matcher is returned as is.
+//
+// See: AllOf().
+func Not(m matcher.Matcher) *NotMatcher {
+	return &NotMatcher{matcher: m}
+}
+
+// matchSub matches the value with a sub matcher. The proto matchers get the value as is, the others get it in json.
+func matchSub(m matcher.Matcher, actual interface{}) (bool, error) {
+	if IsProtoMatcher(m) {
+		return m.Match(actual)
+	}
+
+	v, err := value.Marshal(actual)
+	if err != nil {
+		return false, err
+	}
+
+	return m.Match(v)
+}
+
+func anyProtoMatcher(ms ...matcher.Matcher) bool {
+	for _, m := range ms {
+		if IsProtoMatcher(m) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func describeMatcher(m matcher.Matcher) string {
+	return fmt.Sprintf("(%s)", m.Expected())
+}
+
+func describeMatchers(ms []matcher.Matcher) string {
+	expected := make([]string, len(ms))
+
+	for i, m := range ms {
+		expected[i] = describeMatcher(m)
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(expected, ", "))
+}
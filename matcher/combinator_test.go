@@ -0,0 +1,288 @@
+package matcher_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nhatthm/go-matcher"
+	"github.com/stretchr/testify/assert"
+
+	grpcErrors "github.com/nhatthm/grpcmock/errors"
+	grpcMatcher "github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestAllOf(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario       string
+		matcher        matcher.Matcher
+		actual         interface{}
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			scenario:       "no matchers",
+			matcher:        grpcMatcher.AllOf(),
+			actual:         "foobar",
+			expectedResult: true,
+		},
+		{
+			scenario:       "all match",
+			matcher:        grpcMatcher.AllOf(matcher.RegexPattern(`^foo`), matcher.RegexPattern(`bar$`)),
+			actual:         "foobar",
+			expectedResult: true,
+		},
+		{
+			scenario:      "second does not match",
+			matcher:       grpcMatcher.AllOf(matcher.RegexPattern(`^foo`), matcher.RegexPattern(`baz$`)),
+			actual:        "foobar",
+			expectedError: "matcher #1 (baz$) does not match",
+		},
+		{
+			scenario: "error",
+			matcher: grpcMatcher.AllOf(grpcMatcher.Fn("fn", func(interface{}) (bool, error) {
+				return false, errors.New("match error")
+			})),
+			actual:        "foobar",
+			expectedError: "matcher #0 (fn) failed: match error",
+		},
+		{
+			scenario: "nested",
+			matcher: grpcMatcher.AllOf(
+				matcher.RegexPattern(`^foo`),
+				grpcMatcher.AnyOf(matcher.Exact("foobar"), matcher.Exact("foobaz")),
+				grpcMatcher.Not(matcher.Exact("foobaz")),
+			),
+			actual:         "foobar",
+			expectedResult: true,
+		},
+		{
+			scenario: "nested does not match",
+			matcher: grpcMatcher.AllOf(
+				matcher.RegexPattern(`^foo`),
+				grpcMatcher.Not(grpcMatcher.AnyOf(matcher.Exact("foobar"), matcher.Exact("foobaz"))),
+			),
+			actual:        "foobar",
+			expectedError: "matcher #1 (not (any of [(foobar), (foobaz)])) does not match",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			matched, err := tc.matcher.Match(tc.actual)
+
+			assert.Equal(t, tc.expectedResult, matched)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestAllOf_ErrNotMatched(t *testing.T) {
+	t.Parallel()
+
+	_, err := grpcMatcher.AllOf(matcher.Exact("foo")).Match("bar")
+
+	assert.ErrorIs(t, err, grpcErrors.ErrNotMatched)
+}
+
+func TestAnyOf(t *testing.T) {
+	t.Parallel()
+
+	failed := grpcMatcher.Fn("fn", func(interface{}) (bool, error) {
+		return false, errors.New("match error")
+	})
+
+	testCases := []struct {
+		scenario       string
+		matcher        matcher.Matcher
+		actual         interface{}
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			scenario: "no matchers",
+			matcher:  grpcMatcher.AnyOf(),
+			actual:   "foobar",
+		},
+		{
+			scenario:       "one matches",
+			matcher:        grpcMatcher.AnyOf(matcher.Exact("foo"), matcher.Exact("foobar")),
+			actual:         "foobar",
+			expectedResult: true,
+		},
+		{
+			scenario: "none matches",
+			matcher:  grpcMatcher.AnyOf(matcher.Exact("foo"), matcher.Exact("bar")),
+			actual:   "foobar",
+		},
+		{
+			scenario:       "error is ignored when another matches",
+			matcher:        grpcMatcher.AnyOf(failed, matcher.Exact("foobar")),
+			actual:         "foobar",
+			expectedResult: true,
+		},
+		{
+			scenario:      "error when none matches",
+			matcher:       grpcMatcher.AnyOf(failed, matcher.Exact("foo")),
+			actual:        "foobar",
+			expectedError: "none of the matchers matches: matcher #0 (fn) failed: match error",
+		},
+		{
+			scenario: "nested",
+			matcher: grpcMatcher.AnyOf(
+				grpcMatcher.AllOf(matcher.RegexPattern(`^foo`), matcher.RegexPattern(`baz$`)),
+				grpcMatcher.AllOf(matcher.RegexPattern(`^foo`), matcher.RegexPattern(`bar$`)),
+			),
+			actual:         "foobar",
+			expectedResult: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			matched, err := tc.matcher.Match(tc.actual)
+
+			assert.Equal(t, tc.expectedResult, matched)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestNot(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario       string
+		matcher        matcher.Matcher
+		actual         interface{}
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			scenario:       "matches",
+			matcher:        grpcMatcher.Not(matcher.Exact("foo")),
+			actual:         "foobar",
+			expectedResult: true,
+		},
+		{
+			scenario: "does not match",
+			matcher:  grpcMatcher.Not(matcher.Exact("foobar")),
+			actual:   "foobar",
+		},
+		{
+			scenario: "error",
+			matcher: grpcMatcher.Not(grpcMatcher.Fn("fn", func(interface{}) (bool, error) {
+				return false, errors.New("match error")
+			})),
+			actual:        "foobar",
+			expectedError: "match error",
+		},
+		{
+			scenario:       "double negation",
+			matcher:        grpcMatcher.Not(grpcMatcher.Not(matcher.Exact("foobar"))),
+			actual:         "foobar",
+			expectedResult: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			matched, err := tc.matcher.Match(tc.actual)
+
+			assert.Equal(t, tc.expectedResult, matched)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestCombinators_Expected(t *testing.T) {
+	t.Parallel()
+
+	m := grpcMatcher.AllOf(
+		matcher.Exact("foo"),
+		grpcMatcher.AnyOf(matcher.Exact("bar"), grpcMatcher.Not(matcher.Exact("baz"))),
+	)
+
+	assert.Equal(t, "all of [(foo), (any of [(bar), (not (baz))])]", m.Expected())
+}
+
+func TestCombinators_MatchProto(t *testing.T) {
+	t.Parallel()
+
+	field := grpcMatcher.Field("id", matcher.Exact(int32(42)))
+
+	testCases := []struct {
+		scenario string
+		matcher  matcher.Matcher
+		expected bool
+	}{
+		{
+			scenario: "no proto matchers",
+			matcher:  grpcMatcher.AllOf(matcher.Exact("foo"), grpcMatcher.Not(matcher.Exact("bar"))),
+		},
+		{
+			scenario: "all of",
+			matcher:  grpcMatcher.AllOf(matcher.Exact("foo"), field),
+			expected: true,
+		},
+		{
+			scenario: "any of",
+			matcher:  grpcMatcher.AnyOf(field),
+			expected: true,
+		},
+		{
+			scenario: "nested not",
+			matcher:  grpcMatcher.AllOf(grpcMatcher.Not(field)),
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, grpcMatcher.IsProtoMatcher(tc.matcher))
+		})
+	}
+}
+
+func TestAllOf_MixedMatchers(t *testing.T) {
+	t.Parallel()
+
+	m := grpcMatcher.AllOf(
+		matcher.JSON(`{"id": 42}`),
+		grpcMatcher.Field("id", matcher.Exact(int32(42))),
+	)
+
+	actual, err := m.Match(&grpctest.GetItemRequest{Id: 42})
+
+	assert.True(t, actual)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,114 @@
+package matcher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nhatthm/go-matcher"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/nhatthm/grpcmock/errors"
+)
+
+var _ ProtoMatcher = (*FieldMatcher)(nil)
+
+// FieldMatcher matches a field of a proto message.
+type FieldMatcher struct {
+	path    string
+	matcher matcher.Matcher
+}
+
+// Match satisfies the matcher.Matcher interface.
+func (m *FieldMatcher) Match(actual interface{}) (bool, error) {
+	msg, ok := actual.(proto.Message)
+	if !ok {
+		return false, fmt.Errorf("%w: got %T, want proto.Message", errors.ErrUnsupportedDataType, actual)
+	}
+
+	v, err := fieldValue(msg.ProtoReflect(), strings.Split(m.path, "."))
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", err, m.path)
+	}
+
+	return m.matcher.Match(v)
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *FieldMatcher) Expected() string {
+	return fmt.Sprintf("field %q %s", m.path, describeMatcher(m.matcher))
+}
+
+// MatchProto satisfies the ProtoMatcher interface.
+func (m *FieldMatcher) MatchProto() bool {
+	return true
+}
+
+// Field matches a field of a proto message, instead of the whole message. Nested fields could be given using the dot
+// notation, for example "create_time.seconds". The matcher receives the value of the field as is, for example an int32
+// for an int32 field, a proto.Message for a message field, a []interface{} for a repeated field and a
+// map[string]interface{} for a map field.
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItem").
+//    	WithPayload(matcher.Field("name", matcher.RegexPattern(`^Item #`)))
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItem").
+//    	WithPayload(matcher.Field("tags", matcher.Len(2)))
+func Field(path string, m matcher.Matcher) *FieldMatcher {
+	return &FieldMatcher{
+		path:    path,
+		matcher: m,
+	}
+}
+
+func fieldValue(msg protoreflect.Message, path []string) (interface{}, error) {
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(path[0]))
+	if fd == nil {
+		return nil, errors.ErrFieldNotFound
+	}
+
+	if len(path) == 1 {
+		return protoValue(fd, msg.Get(fd)), nil
+	}
+
+	if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+		return nil, errors.ErrFieldNotFound
+	}
+
+	return fieldValue(msg.Get(fd).Message(), path[1:])
+}
+
+func protoValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch {
+	case fd.IsList():
+		list := v.List()
+		result := make([]interface{}, list.Len())
+
+		for i := range result {
+			result[i] = protoScalarValue(fd, list.Get(i))
+		}
+
+		return result
+
+	case fd.IsMap():
+		result := make(map[string]interface{}, v.Map().Len())
+
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			result[k.String()] = protoScalarValue(fd.MapValue(), v)
+
+			return true
+		})
+
+		return result
+	}
+
+	return protoScalarValue(fd, v)
+}
+
+func protoScalarValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		return v.Message().Interface()
+	}
+
+	return v.Interface()
+}
@@ -0,0 +1,144 @@
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/nhatthm/go-matcher"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	grpcErrors "github.com/nhatthm/grpcmock/errors"
+	grpcMatcher "github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestField(t *testing.T) {
+	t.Parallel()
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name: proto.String("service.proto"),
+		Options: &descriptorpb.FileOptions{
+			JavaPackage: proto.String("com.example.grpctest"),
+		},
+	}
+
+	badRequest := &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "id", Description: "must be positive"},
+			{Field: "name", Description: "must not be empty"},
+		},
+	}
+
+	testCases := []struct {
+		scenario       string
+		matcher        matcher.Matcher
+		actual         interface{}
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			scenario:       "field matched",
+			matcher:        grpcMatcher.Field("name", matcher.Exact("Item #42")),
+			actual:         &grpctest.Item{Id: 42, Name: "Item #42"},
+			expectedResult: true,
+		},
+		{
+			scenario: "field not matched",
+			matcher:  grpcMatcher.Field("id", matcher.Exact(int32(41))),
+			actual:   &grpctest.Item{Id: 42},
+		},
+		{
+			scenario:       "nested string field",
+			matcher:        grpcMatcher.Field("options.java_package", matcher.RegexPattern(`^com\.example\.`)),
+			actual:         file,
+			expectedResult: true,
+		},
+		{
+			scenario:       "nested field of an unset message",
+			matcher:        grpcMatcher.Field("create_time.seconds", matcher.Exact(int64(0))),
+			actual:         &grpctest.Item{Id: 42},
+			expectedResult: true,
+		},
+		{
+			scenario:       "nested field of a well-known type",
+			matcher:        grpcMatcher.Field("create_time.seconds", matcher.Exact(int64(1234))),
+			actual:         &grpctest.Item{CreateTime: &timestamppb.Timestamp{Seconds: 1234}},
+			expectedResult: true,
+		},
+		{
+			scenario:       "length of a repeated field",
+			matcher:        grpcMatcher.Field("field_violations", matcher.Len(2)),
+			actual:         badRequest,
+			expectedResult: true,
+		},
+		{
+			scenario: "length of a repeated field not matched",
+			matcher:  grpcMatcher.Field("field_violations", matcher.Len(1)),
+			actual:   badRequest,
+		},
+		{
+			scenario:      "unknown field",
+			matcher:       grpcMatcher.Field("unknown", matcher.Exact("foobar")),
+			actual:        &grpctest.Item{Id: 42},
+			expectedError: "field not found: unknown",
+		},
+		{
+			scenario:      "unknown nested field",
+			matcher:       grpcMatcher.Field("create_time.unknown", matcher.Exact("foobar")),
+			actual:        &grpctest.Item{Id: 42},
+			expectedError: "field not found: create_time.unknown",
+		},
+		{
+			scenario:      "path through a scalar field",
+			matcher:       grpcMatcher.Field("name.length", matcher.Exact(0)),
+			actual:        &grpctest.Item{Id: 42},
+			expectedError: "field not found: name.length",
+		},
+		{
+			scenario:      "path through a repeated field",
+			matcher:       grpcMatcher.Field("field_violations.field", matcher.Exact("id")),
+			actual:        badRequest,
+			expectedError: "field not found: field_violations.field",
+		},
+		{
+			scenario:      "not a proto message",
+			matcher:       grpcMatcher.Field("name", matcher.Exact("foobar")),
+			actual:        `{"name": "foobar"}`,
+			expectedError: "unsupported data type: got string, want proto.Message",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			matched, err := tc.matcher.Match(tc.actual)
+
+			assert.Equal(t, tc.expectedResult, matched)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestField_ErrFieldNotFound(t *testing.T) {
+	t.Parallel()
+
+	_, err := grpcMatcher.Field("unknown", matcher.Exact("foobar")).Match(&grpctest.Item{})
+
+	assert.ErrorIs(t, err, grpcErrors.ErrFieldNotFound)
+}
+
+func TestField_Expected(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `field "field_violations" (len is 2)`, grpcMatcher.Field("field_violations", matcher.Len(2)).Expected())
+}
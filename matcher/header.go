@@ -3,11 +3,19 @@ package matcher
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/nhatthm/go-matcher"
 	"google.golang.org/grpc/metadata"
+
+	"github.com/nhatthm/grpcmock/errors"
 )
 
+const authorityHeader = ":authority"
+
+var _ matcher.Matcher = (*HeaderPresenceMatcher)(nil)
+
 // HeaderMatcher matches the header values.
 type HeaderMatcher map[string]matcher.Matcher
 
@@ -20,6 +28,14 @@ func (m HeaderMatcher) Match(ctx context.Context) error {
 	md := incomingHeader(ctx)
 
 	for h, m := range m {
+		if p, ok := m.(*HeaderPresenceMatcher); ok {
+			if matched, _ := p.Match(md.Get(h)); !matched { // nolint: errcheck
+				return fmt.Errorf("header %q expected to be %s", h, p.Expected()) // nolint: goerr113
+			}
+
+			continue
+		}
+
 		value := getHeader(md, h)
 
 		matched, err := m.Match(value)
@@ -35,6 +51,89 @@ func (m HeaderMatcher) Match(ctx context.Context) error {
 	return nil
 }
 
+// String returns a readable description of the expected headers, the headers are sorted by name.
+func (m HeaderMatcher) String() string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	desc := make([]string, 0, len(keys))
+
+	for _, k := range keys {
+		desc = append(desc, fmt.Sprintf("%s: %s", k, m[k].Expected()))
+	}
+
+	return strings.Join(desc, ", ")
+}
+
+// Authority matches the authority of the request, which is the ":authority" pseudo-header.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	WithHeaderMatcher(matcher.Authority("example.com"))
+//
+// See: AuthorityMatch().
+func Authority(value string) HeaderMatcher {
+	return AuthorityMatch(matcher.Exact(value))
+}
+
+// AuthorityMatch matches the authority of the request, which is the ":authority" pseudo-header, using a matcher.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	WithHeaderMatcher(matcher.AuthorityMatch(matcher.RegexPattern(`\.example\.com$`)))
+//
+// See: Authority().
+func AuthorityMatch(m matcher.Matcher) HeaderMatcher {
+	return HeaderMatcher{authorityHeader: m}
+}
+
+// HeaderPresenceMatcher matches whether a header is present or not, regardless of its values.
+type HeaderPresenceMatcher struct {
+	present bool
+}
+
+// Match satisfies the matcher.Matcher interface. The actual value is the values of the header.
+func (m *HeaderPresenceMatcher) Match(actual interface{}) (bool, error) {
+	values, ok := actual.([]string)
+	if !ok {
+		return false, fmt.Errorf("%w: got %T, want []string", errors.ErrUnsupportedDataType, actual)
+	}
+
+	return (len(values) > 0) == m.present, nil
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *HeaderPresenceMatcher) Expected() string {
+	if m.present {
+		return "present"
+	}
+
+	return "absent"
+}
+
+// HeaderPresent matches if the header is present, regardless of its values.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	WithHeaderMatcher(matcher.HeaderPresent("authorization"))
+//
+// See: HeaderAbsent().
+func HeaderPresent(key string) HeaderMatcher {
+	return HeaderMatcher{key: &HeaderPresenceMatcher{present: true}}
+}
+
+// HeaderAbsent matches if the header is absent, for example, to make sure the credentials are not leaked.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	WithHeaderMatcher(matcher.HeaderAbsent("authorization"))
+//
+// See: HeaderPresent().
+func HeaderAbsent(key string) HeaderMatcher {
+	return HeaderMatcher{key: &HeaderPresenceMatcher{present: false}}
+}
+
 func getHeader(md metadata.MD, k string) string {
 	values := md.Get(k)
 
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/metadata"
 
+	grpcErrors "github.com/nhatthm/grpcmock/errors"
 	grpcMatcher "github.com/nhatthm/grpcmock/matcher"
 )
 
@@ -48,6 +49,48 @@ func TestHeaderMatcher_Match(t *testing.T) {
 			},
 			expectedError: `header "Authorization" with value "Bearer token" expected, "Bearer foobar" received`,
 		},
+		{
+			scenario: "authority mismatched",
+			matcher:  grpcMatcher.Authority("example.com"),
+			header: map[string]string{
+				":authority": "localhost",
+			},
+			expectedError: `header ":authority" with value "example.com" expected, "localhost" received`,
+		},
+		{
+			scenario: "authority matched",
+			matcher:  grpcMatcher.AuthorityMatch(matcher.RegexPattern(`\.example\.com$`)),
+			header: map[string]string{
+				":authority": "api.example.com",
+			},
+		},
+		{
+			scenario: "present",
+			matcher:  grpcMatcher.HeaderPresent("Authorization"),
+			header: map[string]string{
+				"Authorization": "",
+			},
+		},
+		{
+			scenario:      "not present",
+			matcher:       grpcMatcher.HeaderPresent("Authorization"),
+			expectedError: `header "Authorization" expected to be present`,
+		},
+		{
+			scenario: "absent",
+			matcher:  grpcMatcher.HeaderAbsent("Authorization"),
+			header: map[string]string{
+				"Locale": "en-US",
+			},
+		},
+		{
+			scenario: "not absent",
+			matcher:  grpcMatcher.HeaderAbsent("Authorization"),
+			header: map[string]string{
+				"Authorization": "Bearer foobar",
+			},
+			expectedError: `header "Authorization" expected to be absent`,
+		},
 		{
 			scenario: "matched",
 			matcher: grpcMatcher.HeaderMatcher{
@@ -75,3 +118,26 @@ func TestHeaderMatcher_Match(t *testing.T) {
 		})
 	}
 }
+
+func TestHeaderPresenceMatcher_Match_UnsupportedDataType(t *testing.T) {
+	t.Parallel()
+
+	m := grpcMatcher.HeaderPresent("Authorization")["Authorization"]
+
+	matched, err := m.Match("Bearer foobar")
+
+	assert.False(t, matched)
+	assert.ErrorIs(t, err, grpcErrors.ErrUnsupportedDataType)
+}
+
+func TestHeaderMatcher_String(t *testing.T) {
+	t.Parallel()
+
+	m := grpcMatcher.HeaderMatcher{
+		"locale":        matcher.Exact("en-US"),
+		"authorization": matcher.RegexPattern(`^Bearer `),
+	}
+
+	assert.Equal(t, "authorization: ^Bearer , locale: en-US", m.String())
+	assert.Empty(t, grpcMatcher.HeaderMatcher(nil).String())
+}
@@ -0,0 +1,159 @@
+package matcher
+
+import (
+	"encoding/json"
+
+	"github.com/nhatthm/go-matcher"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var _ ProtoMatcher = (*JSONEqMatcher)(nil)
+
+// JSONMatchOption sets up the JSONEqMatcher.
+type JSONMatchOption func(m *JSONEqMatcher)
+
+// JSONEqMatcher matches a proto message against a json using protojson.
+type JSONEqMatcher struct {
+	expected      string
+	ignoreUnknown bool
+	emitDefaults  bool
+}
+
+// Match satisfies the matcher.Matcher interface.
+func (m *JSONEqMatcher) Match(actual interface{}) (bool, error) {
+	msg, ok := actual.(proto.Message)
+	if !ok {
+		return false, nil
+	}
+
+	expected, err := m.expectedJSON(msg)
+	if err != nil {
+		return false, err
+	}
+
+	b, err := protojson.MarshalOptions{EmitUnpopulated: m.emitDefaults}.Marshal(msg)
+	if err != nil {
+		return false, err
+	}
+
+	return matcher.JSON(expected).Match(string(b))
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *JSONEqMatcher) Expected() string {
+	return m.expected
+}
+
+// MatchProto satisfies the ProtoMatcher interface.
+func (m *JSONEqMatcher) MatchProto() bool {
+	return true
+}
+
+// expectedJSON validates the expected json against the message and removes the unknown fields if
+// IgnoreUnknownFields() is set.
+func (m *JSONEqMatcher) expectedJSON(actual proto.Message) (string, error) {
+	msg := actual.ProtoReflect().New().Interface()
+
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: m.ignoreUnknown}).Unmarshal([]byte(m.expected), msg); err != nil {
+		return "", err
+	}
+
+	if !m.ignoreUnknown {
+		return m.expected, nil
+	}
+
+	var v map[string]interface{}
+
+	if err := json.Unmarshal([]byte(m.expected), &v); err != nil {
+		return "", err
+	}
+
+	removeUnknownFields(v, msg.ProtoReflect().Descriptor())
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// JSONEq matches a proto message against a json, the message is marshaled using protojson, so the expected json must
+// use the json names of the fields, for example "createTime" instead of "create_time".
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItem").
+//    	WithPayload(matcher.JSONEq(`{"id": 42, "name": "Foobar"}`))
+//
+// See: JSONEqWith().
+func JSONEq(expected string) *JSONEqMatcher {
+	return JSONEqWith(expected)
+}
+
+// JSONEqWith matches a proto message against a json with options.
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItem").
+//    	WithPayload(matcher.JSONEqWith(`{"id": 42, "name": "", "unknown": true}`,
+//    		matcher.EmitDefaults(),
+//    		matcher.IgnoreUnknownFields(),
+//    	))
+//
+// See: IgnoreUnknownFields(), EmitDefaults().
+func JSONEqWith(expected string, opts ...JSONMatchOption) *JSONEqMatcher {
+	m := &JSONEqMatcher{expected: expected}
+
+	for _, o := range opts {
+		o(m)
+	}
+
+	return m
+}
+
+// IgnoreUnknownFields ignores the fields in the expected json that do not exist in the message. Without this option,
+// the unknown fields result in an error.
+func IgnoreUnknownFields() JSONMatchOption {
+	return func(m *JSONEqMatcher) {
+		m.ignoreUnknown = true
+	}
+}
+
+// EmitDefaults marshals the fields that have default values, so the expected json must contain them. Without this
+// option, the fields that have default values must not be in the expected json.
+func EmitDefaults() JSONMatchOption {
+	return func(m *JSONEqMatcher) {
+		m.emitDefaults = true
+	}
+}
+
+func removeUnknownFields(v map[string]interface{}, md protoreflect.MessageDescriptor) {
+	for k, value := range v {
+		fd := md.Fields().ByJSONName(k)
+		if fd == nil {
+			fd = md.Fields().ByName(protoreflect.Name(k))
+		}
+
+		if fd == nil {
+			delete(v, k)
+
+			continue
+		}
+
+		// The well-known types have their own json representation, so they are not walked through.
+		if fd.Kind() != protoreflect.MessageKind || fd.IsMap() || fd.Message().FullName().Parent() == "google.protobuf" {
+			continue
+		}
+
+		switch value := value.(type) {
+		case map[string]interface{}:
+			removeUnknownFields(value, fd.Message())
+
+		case []interface{}:
+			for _, item := range value {
+				if item, ok := item.(map[string]interface{}); ok {
+					removeUnknownFields(item, fd.Message())
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var _ ProtoMatcher = (*JSONContainsMatcher)(nil)
+
+// JSONContainsOption sets up the JSONContainsMatcher.
+type JSONContainsOption func(m *JSONContainsMatcher)
+
+// JSONContainsMatcher matches a proto message, or a json, that contains a partial json.
+type JSONContainsMatcher struct {
+	expected    string
+	exactArrays bool
+}
+
+// Match satisfies the matcher.Matcher interface.
+func (m *JSONContainsMatcher) Match(actual interface{}) (bool, error) {
+	var data []byte
+
+	switch v := actual.(type) {
+	case proto.Message:
+		b, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(v)
+		if err != nil {
+			return false, err
+		}
+
+		data = b
+
+	case []byte:
+		data = v
+
+	case string:
+		data = []byte(v)
+
+	default:
+		return false, nil
+	}
+
+	var expected, actualValue interface{}
+
+	if err := json.Unmarshal([]byte(m.expected), &expected); err != nil {
+		return false, fmt.Errorf("could not decode expected json: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &actualValue); err != nil {
+		return false, fmt.Errorf("could not decode actual json: %w", err)
+	}
+
+	return m.contains(actualValue, expected), nil
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *JSONContainsMatcher) Expected() string {
+	return fmt.Sprintf("json contains %s", m.expected)
+}
+
+// MatchProto satisfies the ProtoMatcher interface.
+func (m *JSONContainsMatcher) MatchProto() bool {
+	return true
+}
+
+func (m *JSONContainsMatcher) contains(actual, expected interface{}) bool {
+	switch expected := expected.(type) {
+	case map[string]interface{}:
+		actual, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		for k, v := range expected {
+			a, ok := actual[k]
+			if !ok || !m.contains(a, v) {
+				return false
+			}
+		}
+
+		return true
+
+	case []interface{}:
+		actual, ok := actual.([]interface{})
+		if !ok || len(expected) > len(actual) || (m.exactArrays && len(expected) != len(actual)) {
+			return false
+		}
+
+		for i, v := range expected {
+			if !m.contains(actual[i], v) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return reflect.DeepEqual(actual, expected)
+}
+
+// JSONContains matches a proto message that contains all the keys and values of a partial json, the extra fields of the
+// message are ignored. The nested objects are matched the same way. An array matches if its first elements contain the
+// elements of the expected array, index by index. The message is marshaled using protojson with the default values, so
+// the partial json must use the json names of the fields, for example "createTime" instead of "create_time".
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItem").
+//    	WithPayload(matcher.JSONContains(`{"name": "Foobar"}`))
+//
+// See: JSONContainsWith(), JSONEq().
+func JSONContains(partial string) *JSONContainsMatcher {
+	return JSONContainsWith(partial)
+}
+
+// JSONContainsWith matches a proto message that contains a partial json with options.
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItems").
+//    	WithPayload(matcher.JSONContainsWith(`{"items": [{"id": 41}, {"id": 42}]}`,
+//    		matcher.ExactArrays(),
+//    	))
+//
+// See: ExactArrays().
+func JSONContainsWith(partial string, opts ...JSONContainsOption) *JSONContainsMatcher {
+	m := &JSONContainsMatcher{expected: partial}
+
+	for _, o := range opts {
+		o(m)
+	}
+
+	return m
+}
+
+// ExactArrays requires the arrays to have the same number of elements as the expected arrays. The elements are still
+// matched as subsets.
+func ExactArrays() JSONContainsOption {
+	return func(m *JSONContainsMatcher) {
+		m.exactArrays = true
+	}
+}
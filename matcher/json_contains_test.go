@@ -0,0 +1,124 @@
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestJSONContainsWith_Match(t *testing.T) {
+	t.Parallel()
+
+	api := &apipb.Api{
+		Name:    "grpctest.ItemService",
+		Methods: []*apipb.Method{{Name: "GetItem"}, {Name: "ListItems"}},
+	}
+
+	testCases := []struct {
+		scenario       string
+		expected       string
+		options        []matcher.JSONContainsOption
+		actual         interface{}
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			scenario: "not a proto message",
+			expected: `{"id": 42}`,
+			actual:   42,
+		},
+		{
+			scenario:       "subset",
+			expected:       `{"name": "Foobar"}`,
+			actual:         &grpctest.Item{Id: 42, Locale: "en-US", Name: "Foobar"},
+			expectedResult: true,
+		},
+		{
+			scenario:       "default value",
+			expected:       `{"id": 42, "name": ""}`,
+			actual:         &grpctest.Item{Id: 42},
+			expectedResult: true,
+		},
+		{
+			scenario: "different value",
+			expected: `{"name": "Foobar"}`,
+			actual:   &grpctest.Item{Id: 42, Name: "Baz"},
+		},
+		{
+			scenario: "missing key",
+			expected: `{"id": 42, "unknown": true}`,
+			actual:   &grpctest.Item{Id: 42},
+		},
+		{
+			scenario:       "nested object",
+			expected:       `{"createTime": "2020-01-02T03:04:05Z"}`,
+			actual:         &grpctest.Item{Id: 42, CreateTime: &timestamppb.Timestamp{Seconds: 1577934245}},
+			expectedResult: true,
+		},
+		{
+			scenario:       "array is a subset at each index",
+			expected:       `{"methods": [{"name": "GetItem"}]}`,
+			actual:         api,
+			expectedResult: true,
+		},
+		{
+			scenario: "array element is different",
+			expected: `{"methods": [{"name": "ListItems"}]}`,
+			actual:   api,
+		},
+		{
+			scenario: "array is longer than actual",
+			expected: `{"methods": [{"name": "GetItem"}, {"name": "ListItems"}, {"name": "CreateItems"}]}`,
+			actual:   api,
+		},
+		{
+			scenario: "exact arrays with fewer elements",
+			expected: `{"methods": [{"name": "GetItem"}]}`,
+			options:  []matcher.JSONContainsOption{matcher.ExactArrays()},
+			actual:   api,
+		},
+		{
+			scenario:       "exact arrays with the same elements",
+			expected:       `{"methods": [{"name": "GetItem"}, {"name": "ListItems"}]}`,
+			options:        []matcher.JSONContainsOption{matcher.ExactArrays()},
+			actual:         api,
+			expectedResult: true,
+		},
+		{
+			scenario:       "json string",
+			expected:       `{"user": {"id": 42}}`,
+			actual:         `{"user": {"id": 42, "name": "John"}, "active": true}`,
+			expectedResult: true,
+		},
+		{
+			scenario:      "invalid expected json",
+			expected:      `{"id": 42`,
+			actual:        &grpctest.Item{Id: 42},
+			expectedError: "could not decode expected json: unexpected end of JSON input",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			m := matcher.JSONContainsWith(tc.expected, tc.options...)
+			result, err := m.Match(tc.actual)
+
+			assert.Equal(t, tc.expectedResult, result)
+			assert.Equal(t, "json contains "+tc.expected, m.Expected())
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
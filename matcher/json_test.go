@@ -0,0 +1,107 @@
+package matcher_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestJSONEqWith_Match(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario       string
+		expected       string
+		options        []matcher.JSONMatchOption
+		actual         interface{}
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			scenario: "not a proto message",
+			expected: `{"id": 42}`,
+			actual:   "foobar",
+		},
+		{
+			scenario:       "same",
+			expected:       `{"id": 42, "name": "Foobar"}`,
+			actual:         &grpctest.Item{Id: 42, Name: "Foobar"},
+			expectedResult: true,
+		},
+		{
+			scenario: "different",
+			expected: `{"id": 42, "name": "Foobar"}`,
+			actual:   &grpctest.Item{Id: 42, Name: "Baz"},
+		},
+		{
+			scenario:       "default values are not emitted",
+			expected:       `{"id": 42}`,
+			actual:         &grpctest.Item{Id: 42},
+			expectedResult: true,
+		},
+		{
+			scenario: "default values are expected but not emitted",
+			expected: `{"id": 42, "locale": "", "name": ""}`,
+			actual:   &grpctest.Item{Id: 42},
+		},
+		{
+			scenario:       "default values are emitted",
+			expected:       `{"id": 42, "locale": "", "name": "", "createTime": null}`,
+			options:        []matcher.JSONMatchOption{matcher.EmitDefaults()},
+			actual:         &grpctest.Item{Id: 42},
+			expectedResult: true,
+		},
+		{
+			scenario: "default values are emitted but not expected",
+			expected: `{"id": 42}`,
+			options:  []matcher.JSONMatchOption{matcher.EmitDefaults()},
+			actual:   &grpctest.Item{Id: 42},
+		},
+		{
+			scenario:      "unknown field",
+			expected:      `{"id": 42, "unknown": true}`,
+			actual:        &grpctest.Item{Id: 42},
+			expectedError: `unknown field "unknown"`,
+		},
+		{
+			scenario:       "ignore unknown field",
+			expected:       `{"id": 42, "unknown": true, "createTime": "2020-01-01T00:00:00Z"}`,
+			options:        []matcher.JSONMatchOption{matcher.IgnoreUnknownFields()},
+			actual:         &grpctest.Item{Id: 42, CreateTime: timestamppb.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))},
+			expectedResult: true,
+		},
+		{
+			scenario:      "invalid json",
+			expected:      `{"id": 42`,
+			actual:        &grpctest.Item{Id: 42},
+			expectedError: `unexpected EOF`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			m := matcher.JSONEqWith(tc.expected, tc.options...)
+
+			result, err := m.Match(tc.actual)
+
+			assert.Equal(t, tc.expectedResult, result)
+			assert.Equal(t, tc.expected, m.Expected())
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				// protojson randomizes the whitespaces in the error messages, so only the reason is checked.
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+			}
+		})
+	}
+}
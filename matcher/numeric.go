@@ -0,0 +1,144 @@
+package matcher
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/nhatthm/go-matcher"
+
+	"github.com/nhatthm/grpcmock/errors"
+)
+
+var _ matcher.Matcher = (*NumericMatcher)(nil)
+
+// NumericMatcher compares a number with the expected bounds.
+type NumericMatcher struct {
+	expected string
+	compare  func(v *big.Float) bool
+}
+
+// Match satisfies the matcher.Matcher interface.
+func (m *NumericMatcher) Match(actual interface{}) (bool, error) {
+	v, err := numericValue(actual)
+	if err != nil {
+		return false, err
+	}
+
+	// NaN is not comparable.
+	if v == nil {
+		return false, nil
+	}
+
+	return m.compare(v), nil
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *NumericMatcher) Expected() string {
+	return m.expected
+}
+
+// GreaterThan matches a number that is greater than the given value. It works with all the numeric proto fields, int32,
+// int64, uint32, uint64, float and double, when combined with Field().
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItem").
+//    	WithPayload(matcher.Field("price", matcher.GreaterThan(0)))
+//
+// See: LessThan(), Between().
+func GreaterThan(v float64) *NumericMatcher {
+	bound := newNumericBound(v)
+
+	return &NumericMatcher{
+		expected: fmt.Sprintf("greater than %s", formatNumber(v)),
+		compare: func(actual *big.Float) bool {
+			return actual.Cmp(bound) > 0
+		},
+	}
+}
+
+// LessThan matches a number that is less than the given value.
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItem").
+//    	WithPayload(matcher.Field("discount", matcher.LessThan(100)))
+//
+// See: GreaterThan(), Between().
+func LessThan(v float64) *NumericMatcher {
+	bound := newNumericBound(v)
+
+	return &NumericMatcher{
+		expected: fmt.Sprintf("less than %s", formatNumber(v)),
+		compare: func(actual *big.Float) bool {
+			return actual.Cmp(bound) < 0
+		},
+	}
+}
+
+// Between matches a number that is between the given values, inclusively.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	WithPayload(matcher.Field("id", matcher.Between(1, 100)))
+//
+// See: GreaterThan(), LessThan().
+func Between(min, max float64) *NumericMatcher {
+	lower, upper := newNumericBound(min), newNumericBound(max)
+
+	return &NumericMatcher{
+		expected: fmt.Sprintf("between %s and %s", formatNumber(min), formatNumber(max)),
+		compare: func(actual *big.Float) bool {
+			return actual.Cmp(lower) >= 0 && actual.Cmp(upper) <= 0
+		},
+	}
+}
+
+func newNumericBound(v float64) *big.Float {
+	if math.IsNaN(v) {
+		panic(fmt.Errorf("%w: NaN is not comparable", errors.ErrUnsupportedDataType))
+	}
+
+	return big.NewFloat(v)
+}
+
+// numericValue converts a number to a *big.Float, so that the large int64 and uint64 are compared without losing
+// precision. It returns nil for NaN.
+func numericValue(v interface{}) (*big.Float, error) {
+	switch v := v.(type) {
+	case int:
+		return new(big.Float).SetInt64(int64(v)), nil
+
+	case int32:
+		return new(big.Float).SetInt64(int64(v)), nil
+
+	case int64:
+		return new(big.Float).SetInt64(v), nil
+
+	case uint:
+		return new(big.Float).SetUint64(uint64(v)), nil
+
+	case uint32:
+		return new(big.Float).SetUint64(uint64(v)), nil
+
+	case uint64:
+		return new(big.Float).SetUint64(v), nil
+
+	case float32:
+		return numericFloatValue(float64(v)), nil
+
+	case float64:
+		return numericFloatValue(v), nil
+	}
+
+	return nil, fmt.Errorf("%w: got %T, want a number", errors.ErrUnsupportedDataType, v)
+}
+
+func numericFloatValue(v float64) *big.Float {
+	if math.IsNaN(v) {
+		return nil
+	}
+
+	return big.NewFloat(v)
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,123 @@
+package matcher_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nhatthm/go-matcher"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	grpcMatcher "github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestNumericMatcher(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario       string
+		matcher        matcher.Matcher
+		actual         interface{}
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			scenario:       "int32 is greater",
+			matcher:        grpcMatcher.Field("id", grpcMatcher.GreaterThan(0)),
+			actual:         &grpctest.Item{Id: 42},
+			expectedResult: true,
+		},
+		{
+			scenario: "int32 is equal",
+			matcher:  grpcMatcher.Field("id", grpcMatcher.GreaterThan(42)),
+			actual:   &grpctest.Item{Id: 42},
+		},
+		{
+			scenario:       "int64 is less",
+			matcher:        grpcMatcher.Field("value", grpcMatcher.LessThan(0)),
+			actual:         wrapperspb.Int64(-1),
+			expectedResult: true,
+		},
+		{
+			scenario: "large int64 is greater",
+			// float64 could not represent the value, but the comparison is exact.
+			matcher:        grpcMatcher.Field("value", grpcMatcher.GreaterThan(1<<53)),
+			actual:         wrapperspb.Int64(1<<53 + 1),
+			expectedResult: true,
+		},
+		{
+			scenario:       "uint32 is between",
+			matcher:        grpcMatcher.Field("value", grpcMatcher.Between(1, 10)),
+			actual:         wrapperspb.UInt32(10),
+			expectedResult: true,
+		},
+		{
+			scenario:       "uint64 is between",
+			matcher:        grpcMatcher.Field("value", grpcMatcher.Between(1, 10)),
+			actual:         wrapperspb.UInt64(1),
+			expectedResult: true,
+		},
+		{
+			scenario: "uint64 is out of range",
+			matcher:  grpcMatcher.Field("value", grpcMatcher.Between(1, 10)),
+			actual:   wrapperspb.UInt64(11),
+		},
+		{
+			scenario:       "float is greater",
+			matcher:        grpcMatcher.Field("value", grpcMatcher.GreaterThan(0.5)),
+			actual:         wrapperspb.Float(0.75),
+			expectedResult: true,
+		},
+		{
+			scenario: "double is out of range",
+			matcher:  grpcMatcher.Field("value", grpcMatcher.Between(-1.5, 1.5)),
+			actual:   wrapperspb.Double(-1.6),
+		},
+		{
+			scenario: "double is NaN",
+			matcher:  grpcMatcher.Field("value", grpcMatcher.GreaterThan(0)),
+			actual:   wrapperspb.Double(math.NaN()),
+		},
+		{
+			scenario:      "not a number",
+			matcher:       grpcMatcher.Field("name", grpcMatcher.GreaterThan(0)),
+			actual:        &grpctest.Item{Name: "Item #42"},
+			expectedError: "unsupported data type: got string, want a number",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := tc.matcher.Match(tc.actual)
+
+			assert.Equal(t, tc.expectedResult, result)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestNumericMatcher_Expected(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "greater than 0", grpcMatcher.GreaterThan(0).Expected())
+	assert.Equal(t, "less than 1.5", grpcMatcher.LessThan(1.5).Expected())
+	assert.Equal(t, "between -1 and 1e+06", grpcMatcher.Between(-1, 1e6).Expected())
+	assert.Equal(t, `field "price" (greater than 0)`, grpcMatcher.Field("price", grpcMatcher.GreaterThan(0)).Expected())
+}
+
+func TestNumericMatcher_NaN(t *testing.T) {
+	t.Parallel()
+
+	assert.PanicsWithError(t, "unsupported data type: NaN is not comparable", func() {
+		grpcMatcher.GreaterThan(math.NaN())
+	})
+}
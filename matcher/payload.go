@@ -13,6 +13,21 @@ var _ matcher.Matcher = (*PayloadMatcher)(nil)
 // PayloadDecoder decodes an input for matching.
 type PayloadDecoder func(in interface{}) (string, error)
 
+// ProtoMatcher is a matcher that matches the proto message of a request as is, instead of its payload in json.
+type ProtoMatcher interface {
+	matcher.Matcher
+
+	// MatchProto reports whether the matcher wants the proto message.
+	MatchProto() bool
+}
+
+// IsProtoMatcher checks whether the matcher wants the proto message of a request.
+func IsProtoMatcher(m interface{}) bool {
+	p, ok := m.(ProtoMatcher)
+
+	return ok && p.MatchProto()
+}
+
 // PayloadMatcher matches a payload of a grpc request.
 type PayloadMatcher struct {
 	matcher matcher.Matcher
@@ -62,6 +77,19 @@ func (m PayloadMatcher) Expected() string {
 	return m.matcher.Expected()
 }
 
+// String returns a readable description of the expected payload.
+func (m *PayloadMatcher) String() string {
+	if m == nil || m.matcher == nil {
+		return ""
+	}
+
+	if e := m.matcher.Expected(); e != "" {
+		return e
+	}
+
+	return "matches custom expectation"
+}
+
 // Payload initiates a new payload matcher.
 func Payload(m matcher.Matcher, decode PayloadDecoder) *PayloadMatcher {
 	return &PayloadMatcher{
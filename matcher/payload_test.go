@@ -96,3 +96,38 @@ func TestBodyMatcher_Matcher(t *testing.T) {
 	assert.Equal(t, matcher.Match(expected), m.Matcher())
 	assert.Equal(t, expected, m.Expected())
 }
+
+func TestPayloadMatcher_String(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		matcher  *srvMatcher.PayloadMatcher
+		expected string
+	}{
+		{
+			scenario: "nil",
+		},
+		{
+			scenario: "exact",
+			matcher:  srvMatcher.Payload(matcher.Exact(`{"id": 42}`), nil),
+			expected: `{"id": 42}`,
+		},
+		{
+			scenario: "custom matcher without expectation",
+			matcher: srvMatcher.Payload(srvMatcher.Fn("", func(interface{}) (bool, error) {
+				return true, nil
+			}), nil),
+			expected: "matches custom expectation",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, tc.matcher.String())
+		})
+	}
+}
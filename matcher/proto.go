@@ -0,0 +1,106 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/nhatthm/grpcmock/errors"
+)
+
+var _ ProtoMatcher = (*ExactExceptMatcher)(nil)
+
+// ExactExceptMatcher matches a proto message exactly, except the ignored fields.
+type ExactExceptMatcher struct {
+	expected proto.Message
+	fields   []string
+}
+
+// Match satisfies the matcher.Matcher interface.
+func (m *ExactExceptMatcher) Match(actual interface{}) (bool, error) {
+	msg, ok := actual.(proto.Message)
+	if !ok || msg.ProtoReflect().Descriptor().FullName() != m.expected.ProtoReflect().Descriptor().FullName() {
+		return false, nil
+	}
+
+	expected, err := clearFields(m.expected, m.fields...)
+	if err != nil {
+		return false, err
+	}
+
+	msg, err = clearFields(msg, m.fields...)
+	if err != nil {
+		return false, err
+	}
+
+	return proto.Equal(expected, msg), nil
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *ExactExceptMatcher) Expected() string {
+	b, err := json.Marshal(m.expected)
+	if err != nil {
+		return fmt.Sprintf("%T", m.expected)
+	}
+
+	if len(m.fields) == 0 {
+		return string(b)
+	}
+
+	return fmt.Sprintf("%s (ignoring %s)", string(b), strings.Join(m.fields, ", "))
+}
+
+// MatchProto satisfies the ProtoMatcher interface.
+func (m *ExactExceptMatcher) MatchProto() bool {
+	return true
+}
+
+// ExactExcept matches a proto message exactly, except the given fields. Nested fields could be given using the dot
+// notation, for example "metadata.created_at".
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItem").
+//    	WithPayload(matcher.ExactExcept(&grpctest.Item{Name: "Foobar"}, "id", "create_time"))
+func ExactExcept(expected proto.Message, fields ...string) *ExactExceptMatcher {
+	return &ExactExceptMatcher{
+		expected: expected,
+		fields:   fields,
+	}
+}
+
+func clearFields(msg proto.Message, fields ...string) (proto.Message, error) {
+	msg = proto.Clone(msg)
+
+	for _, f := range fields {
+		if err := clearField(msg.ProtoReflect(), strings.Split(f, ".")); err != nil {
+			return nil, fmt.Errorf("%w: %s", err, f)
+		}
+	}
+
+	return msg, nil
+}
+
+func clearField(msg protoreflect.Message, path []string) error {
+	fd := msg.Descriptor().Fields().ByName(protoreflect.Name(path[0]))
+	if fd == nil {
+		return errors.ErrFieldNotFound
+	}
+
+	if len(path) == 1 {
+		msg.Clear(fd)
+
+		return nil
+	}
+
+	if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+		return errors.ErrFieldNotFound
+	}
+
+	if !msg.Has(fd) {
+		return nil
+	}
+
+	return clearField(msg.Mutable(fd).Message(), path[1:])
+}
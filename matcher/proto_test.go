@@ -0,0 +1,124 @@
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestExactExcept_Match(t *testing.T) {
+	t.Parallel()
+
+	expected := &grpctest.Item{
+		Id:         42,
+		Name:       "Foobar",
+		CreateTime: &timestamppb.Timestamp{Seconds: 1, Nanos: 1},
+	}
+
+	testCases := []struct {
+		scenario       string
+		fields         []string
+		actual         interface{}
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			scenario: "not a proto message",
+			actual:   "foobar",
+		},
+		{
+			scenario: "different message type",
+			actual:   &grpctest.GetItemRequest{Id: 42},
+		},
+		{
+			scenario: "no ignored fields and same",
+			actual: &grpctest.Item{
+				Id:         42,
+				Name:       "Foobar",
+				CreateTime: &timestamppb.Timestamp{Seconds: 1, Nanos: 1},
+			},
+			expectedResult: true,
+		},
+		{
+			scenario: "volatile fields differ",
+			fields:   []string{"id", "create_time"},
+			actual: &grpctest.Item{
+				Id:         1,
+				Name:       "Foobar",
+				CreateTime: timestamppb.Now(),
+			},
+			expectedResult: true,
+		},
+		{
+			scenario: "nested volatile field differs",
+			fields:   []string{"create_time.nanos"},
+			actual: &grpctest.Item{
+				Id:         42,
+				Name:       "Foobar",
+				CreateTime: &timestamppb.Timestamp{Seconds: 1, Nanos: 42},
+			},
+			expectedResult: true,
+		},
+		{
+			scenario: "nested field is not set",
+			fields:   []string{"create_time.nanos"},
+			actual: &grpctest.Item{
+				Id:   42,
+				Name: "Foobar",
+			},
+		},
+		{
+			scenario: "not ignored field differs",
+			fields:   []string{"id", "create_time"},
+			actual: &grpctest.Item{
+				Id:   42,
+				Name: "Baz",
+			},
+		},
+		{
+			scenario:      "unknown field",
+			fields:        []string{"unknown"},
+			actual:        &grpctest.Item{},
+			expectedError: "field not found: unknown",
+		},
+		{
+			scenario:      "unknown nested field",
+			fields:        []string{"name.unknown"},
+			actual:        &grpctest.Item{},
+			expectedError: "field not found: name.unknown",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := matcher.ExactExcept(expected, tc.fields...).Match(tc.actual)
+
+			assert.Equal(t, tc.expectedResult, result)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestExactExcept_Expected(t *testing.T) {
+	t.Parallel()
+
+	m := matcher.ExactExcept(&grpctest.Item{Id: 42})
+
+	assert.Equal(t, `{"id":42}`, m.Expected())
+
+	m = matcher.ExactExcept(&grpctest.Item{Id: 42}, "name", "create_time")
+
+	assert.Equal(t, `{"id":42} (ignoring name, create_time)`, m.Expected())
+}
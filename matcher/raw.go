@@ -0,0 +1,56 @@
+package matcher
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/nhatthm/grpcmock/errors"
+	"github.com/nhatthm/grpcmock/internal/rawpayload"
+)
+
+var _ ProtoMatcher = (*RawBytesMatcher)(nil)
+
+// RawBytesMatcher matches the wire bytes of a request.
+type RawBytesMatcher struct {
+	expected []byte
+}
+
+// Match satisfies the matcher.Matcher interface.
+func (m *RawBytesMatcher) Match(actual interface{}) (bool, error) {
+	switch v := actual.(type) {
+	case []byte:
+		return bytes.Equal(m.expected, v), nil
+
+	case *rawpayload.Message:
+		return bytes.Equal(m.expected, v.Raw), nil
+	}
+
+	return false, errors.ErrRawBytesUnavailable
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *RawBytesMatcher) Expected() string {
+	return fmt.Sprintf("raw bytes %q", m.expected)
+}
+
+// MatchProto satisfies the ProtoMatcher interface.
+func (m *RawBytesMatcher) MatchProto() bool {
+	return true
+}
+
+// RawBytes matches the wire bytes of a unary request exactly. The raw bytes are only available when the server is
+// started with grpcmock.WithRawBytesCodec(), and the client could send the bytes as is with
+// grpcmock.WithRawBytesPayload().
+//
+//    srv := grpcmock.MockServer(
+//    	grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+//    	grpcmock.WithRawBytesCodec(),
+//    	func(s *grpcmock.Server) {
+//    		s.ExpectUnary("grpctest.ItemService/GetItem").
+//    			WithPayload(matcher.RawBytes([]byte("opaque blob"))).
+//    			Return(&grpctest.Item{Id: 42})
+//    	},
+//    )(t)
+func RawBytes(expected []byte) *RawBytesMatcher {
+	return &RawBytesMatcher{expected: expected}
+}
@@ -0,0 +1,61 @@
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	grpcErrors "github.com/nhatthm/grpcmock/errors"
+	"github.com/nhatthm/grpcmock/internal/rawpayload"
+	"github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestRawBytes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario       string
+		actual         interface{}
+		expectedResult bool
+		expectedError  error
+	}{
+		{
+			scenario:       "same bytes",
+			actual:         []byte("opaque blob"),
+			expectedResult: true,
+		},
+		{
+			scenario: "different bytes",
+			actual:   []byte("another blob"),
+		},
+		{
+			scenario:       "wire bytes of a request",
+			actual:         &rawpayload.Message{Message: &grpctest.GetItemRequest{}, Raw: []byte("opaque blob")},
+			expectedResult: true,
+		},
+		{
+			scenario:      "raw bytes are not available",
+			actual:        &grpctest.GetItemRequest{Id: 42},
+			expectedError: grpcErrors.ErrRawBytesUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			matched, err := matcher.RawBytes([]byte("opaque blob")).Match(tc.actual)
+
+			assert.Equal(t, tc.expectedResult, matched)
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestRawBytes_Expected(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `raw bytes "opaque blob"`, matcher.RawBytes([]byte("opaque blob")).Expected())
+}
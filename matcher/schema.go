@@ -0,0 +1,86 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/nhatthm/grpcmock/must"
+	"github.com/nhatthm/grpcmock/value"
+)
+
+const jsonSchemaURL = "mem://schema.json"
+
+var _ ProtoMatcher = (*JSONSchemaMatcher)(nil)
+
+// JSONSchemaMatcher matches a payload against a json schema.
+type JSONSchemaMatcher struct {
+	expected string
+	schema   *jsonschema.Schema
+}
+
+// Match satisfies the matcher.Matcher interface.
+func (m *JSONSchemaMatcher) Match(actual interface{}) (bool, error) {
+	b, err := marshalJSON(actual)
+	if err != nil {
+		return false, err
+	}
+
+	var v interface{}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return false, err
+	}
+
+	if err := m.schema.Validate(v); err != nil {
+		return false, fmt.Errorf("payload does not match the json schema: %w", err)
+	}
+
+	return true, nil
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *JSONSchemaMatcher) Expected() string {
+	return m.expected
+}
+
+// MatchProto satisfies the ProtoMatcher interface.
+func (m *JSONSchemaMatcher) MatchProto() bool {
+	return true
+}
+
+// JSONSchema matches a payload against a json schema. The proto messages are marshaled using protojson, so the schema
+// must use the json names of the fields, for example "createTime" instead of "create_time". It panics if the schema is
+// invalid.
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItem").
+//    	WithPayload(matcher.JSONSchema(`{
+//    		"type": "object",
+//    		"required": ["name"],
+//    		"properties": {"name": {"type": "string", "minLength": 1}}
+//    	}`))
+func JSONSchema(schema string) *JSONSchemaMatcher {
+	s, err := jsonschema.CompileString(jsonSchemaURL, schema)
+	must.NotFail(err)
+
+	return &JSONSchemaMatcher{
+		expected: schema,
+		schema:   s,
+	}
+}
+
+func marshalJSON(v interface{}) ([]byte, error) {
+	if msg, ok := v.(proto.Message); ok {
+		return protojson.Marshal(msg)
+	}
+
+	s, err := value.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(s), nil
+}
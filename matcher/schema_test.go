@@ -0,0 +1,84 @@
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestJSONSchema_Match(t *testing.T) {
+	t.Parallel()
+
+	const schema = `{
+	"type": "object",
+	"required": ["id", "name"],
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"name": {"type": "string", "minLength": 1}
+	}
+}`
+
+	testCases := []struct {
+		scenario       string
+		actual         interface{}
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			scenario:       "proto message",
+			actual:         &grpctest.Item{Id: 42, Name: "Foobar"},
+			expectedResult: true,
+		},
+		{
+			scenario:       "json string",
+			actual:         `{"id": 42, "name": "Foobar"}`,
+			expectedResult: true,
+		},
+		{
+			scenario:      "missing field",
+			actual:        &grpctest.Item{Id: 42},
+			expectedError: `payload does not match the json schema: jsonschema: '' does not validate with mem://schema.json#/required: missing properties: 'name'`,
+		},
+		{
+			scenario:      "invalid field",
+			actual:        `{"id": 0, "name": "Foobar"}`,
+			expectedError: `payload does not match the json schema: jsonschema: '/id' does not validate with mem://schema.json#/properties/id/minimum: must be >= 1 but found 0`,
+		},
+		{
+			scenario:      "invalid json",
+			actual:        `{"id": 42`,
+			expectedError: `unexpected end of JSON input`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			m := matcher.JSONSchema(schema)
+
+			result, err := m.Match(tc.actual)
+
+			assert.Equal(t, tc.expectedResult, result)
+			assert.Equal(t, schema, m.Expected())
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestJSONSchema_InvalidSchema(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() {
+		matcher.JSONSchema(`{"type": 42}`)
+	})
+}
@@ -0,0 +1,56 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nhatthm/go-matcher"
+
+	grpcReflect "github.com/nhatthm/grpcmock/reflect"
+)
+
+var _ matcher.Matcher = (*SequenceEqMatcher)(nil)
+
+// SequenceEqMatcher matches a sequence of messages element by element.
+type SequenceEqMatcher struct {
+	expected []interface{}
+}
+
+// Match satisfies the matcher.Matcher interface.
+func (m *SequenceEqMatcher) Match(actual interface{}) (bool, error) {
+	msgs, ok := actual.([]interface{})
+	if !ok {
+		return false, nil
+	}
+
+	if len(msgs) != len(m.expected) {
+		return false, nil
+	}
+
+	for i, msg := range msgs {
+		if !grpcReflect.Equal(m.expected[i], msg) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *SequenceEqMatcher) Expected() string {
+	b, err := json.Marshal(m.expected)
+	if err != nil {
+		return fmt.Sprintf("%d message(s)", len(m.expected))
+	}
+
+	return string(b)
+}
+
+// SequenceEq matches a sequence of messages element by element, in order. The proto messages are compared by using
+// proto.Equal().
+//
+//    Server.ExpectClientStream("grpctest.Service/CreateItems").
+//    	WithPayloads(matcher.SequenceEq(&grpctest.Item{Id: 41}, &grpctest.Item{Id: 42}))
+func SequenceEq(expected ...interface{}) *SequenceEqMatcher {
+	return &SequenceEqMatcher{expected: expected}
+}
@@ -0,0 +1,64 @@
+package matcher_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestSequenceEq_Match(t *testing.T) {
+	t.Parallel()
+
+	m := matcher.SequenceEq(&grpctest.Item{Id: 41}, &grpctest.Item{Id: 42}, &grpctest.Item{Id: 43})
+
+	testCases := []struct {
+		scenario string
+		actual   interface{}
+		expected bool
+	}{
+		{
+			scenario: "not a slice",
+			actual:   &grpctest.Item{Id: 41},
+		},
+		{
+			scenario: "different length",
+			actual:   []interface{}{&grpctest.Item{Id: 41}, &grpctest.Item{Id: 42}},
+		},
+		{
+			scenario: "different order",
+			actual:   []interface{}{&grpctest.Item{Id: 42}, &grpctest.Item{Id: 41}, &grpctest.Item{Id: 43}},
+		},
+		{
+			scenario: "not a proto message",
+			actual:   []interface{}{"41", &grpctest.Item{Id: 42}, &grpctest.Item{Id: 43}},
+		},
+		{
+			scenario: "same",
+			actual:   []interface{}{&grpctest.Item{Id: 41}, &grpctest.Item{Id: 42}, &grpctest.Item{Id: 43}},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := m.Match(tc.actual)
+
+			assert.Equal(t, tc.expected, actual)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSequenceEq_Expected(t *testing.T) {
+	t.Parallel()
+
+	m := matcher.SequenceEq(&grpctest.Item{Id: 41}, &grpctest.Item{Id: 42})
+
+	assert.Equal(t, `[{"id":41},{"id":42}]`, m.Expected())
+}
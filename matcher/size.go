@@ -0,0 +1,71 @@
+package matcher
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/proto"
+
+	"github.com/nhatthm/grpcmock/internal/rawpayload"
+)
+
+var _ ProtoMatcher = (*PayloadSizeMatcher)(nil)
+
+// PayloadSizeMatcher matches the serialized size of a request.
+type PayloadSizeMatcher struct {
+	min int
+	max int
+}
+
+// Match satisfies the matcher.Matcher interface.
+func (m *PayloadSizeMatcher) Match(actual interface{}) (bool, error) {
+	size, err := payloadSize(actual)
+	if err != nil {
+		return false, err
+	}
+
+	return size >= m.min && size <= m.max, nil
+}
+
+// Expected satisfies the matcher.Matcher interface.
+func (m *PayloadSizeMatcher) Expected() string {
+	return fmt.Sprintf("payload size between %d and %d bytes", m.min, m.max)
+}
+
+// MatchProto satisfies the ProtoMatcher interface.
+func (m *PayloadSizeMatcher) MatchProto() bool {
+	return true
+}
+
+// PayloadSize matches a request whose serialized size is between min and max bytes, inclusively. The request is
+// marshaled with the proto codec, so the size is the same as the size on the wire. The raw bytes are used as is when
+// they are available. It panics if min is greater than max.
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItem").
+//    	WithPayload(matcher.PayloadSize(0, 1024))
+//
+// See: RawBytes().
+func PayloadSize(min, max int) *PayloadSizeMatcher {
+	if min > max {
+		panic(fmt.Errorf("invalid payload size: min %d is greater than max %d", min, max)) // nolint: goerr113
+	}
+
+	return &PayloadSizeMatcher{min: min, max: max}
+}
+
+func payloadSize(v interface{}) (int, error) {
+	switch v := v.(type) {
+	case []byte:
+		return len(v), nil
+
+	case *rawpayload.Message:
+		return len(v.Raw), nil
+	}
+
+	b, err := encoding.GetCodec(proto.Name).Marshal(v)
+	if err != nil {
+		return 0, fmt.Errorf("could not marshal payload: %w", err)
+	}
+
+	return len(b), nil
+}
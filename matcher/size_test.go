@@ -0,0 +1,79 @@
+package matcher_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nhatthm/grpcmock/internal/rawpayload"
+	grpcMatcher "github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestPayloadSizeMatcher(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario       string
+		actual         interface{}
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			scenario: "small payload",
+			actual:   &grpctest.Item{Id: 42},
+		},
+		{
+			scenario:       "payload within the window",
+			actual:         &grpctest.Item{Id: 42, Name: strings.Repeat("a", 20)},
+			expectedResult: true,
+		},
+		{
+			scenario: "large payload",
+			actual:   &grpctest.Item{Id: 42, Name: strings.Repeat("a", 1024)},
+		},
+		{
+			scenario:       "raw bytes",
+			actual:         make([]byte, 16),
+			expectedResult: true,
+		},
+		{
+			scenario:       "wire bytes of a request",
+			actual:         &rawpayload.Message{Message: &grpctest.Item{Id: 42}, Raw: make([]byte, 16)},
+			expectedResult: true,
+		},
+		{
+			scenario:      "not a message",
+			actual:        42,
+			expectedError: "could not marshal payload: failed to marshal, message is int, want proto.Message",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			m := grpcMatcher.PayloadSize(16, 64)
+			result, err := m.Match(tc.actual)
+
+			assert.Equal(t, tc.expectedResult, result)
+			assert.Equal(t, "payload size between 16 and 64 bytes", m.Expected())
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestPayloadSize_Panic(t *testing.T) {
+	t.Parallel()
+
+	assert.PanicsWithError(t, "invalid payload size: min 64 is greater than max 16", func() {
+		grpcMatcher.PayloadSize(64, 16)
+	})
+}
@@ -45,8 +45,9 @@ func MockServer(opts ...ServerOption) ServerMocker {
 // MockServerWithBufConn starts a new mocked server with bufconn and ensures all the expectations were met at the end of the test.
 func MockServerWithBufConn(opts ...ServerOption) ServerMockerWithContextDialer {
 	return func(t T) (*Server, ContextDialer) {
-		buf := bufconn.Listen(1024 * 1024)
-		opts = append(opts, WithListener(buf))
+		var buf *bufconn.Listener
+
+		opts = append(opts, withBufConnListener(&buf))
 
 		return MockServer(opts...)(t), func(ctx context.Context, s string) (net.Conn, error) {
 			return buf.Dial()
@@ -63,7 +63,7 @@ func (m *firstMatch) Remain() []request.Request {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	return m.expectations
+	return copyExpectations(m.expectations)
 }
 
 func (m *firstMatch) Reset() {
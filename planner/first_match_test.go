@@ -132,6 +132,19 @@ func TestFirstMatch_Plan_Unary_MatchedRequestIsRemoved(t *testing.T) {
 	assert.Len(t, p.Remain(), 0)
 }
 
+func TestFirstMatch_Remain_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	p := mockFirstMatch(func(p planner.Planner) {
+		p.Expect(newGetItemRequest().Once())
+	})()
+
+	remain := p.Remain()
+	remain[0] = nil
+
+	assert.NotNil(t, p.Remain()[0])
+}
+
 func TestFirstMatch_Plan_ClientStream_Error(t *testing.T) {
 	t.Parallel()
 
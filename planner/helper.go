@@ -50,3 +50,16 @@ func removeExpectations(expectations []request.Request, index int) []request.Req
 
 	return remains
 }
+
+// copyExpectations returns a snapshot of the expectations, so the caller could read them while the planner is changing.
+func copyExpectations(expectations []request.Request) []request.Request {
+	if expectations == nil {
+		return nil
+	}
+
+	result := make([]request.Request, len(expectations))
+
+	copy(result, expectations)
+
+	return result
+}
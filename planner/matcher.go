@@ -3,6 +3,10 @@ package planner
 import (
 	"context"
 
+	"google.golang.org/protobuf/proto"
+
+	"github.com/nhatthm/grpcmock/internal/rawpayload"
+	"github.com/nhatthm/grpcmock/matcher"
 	"github.com/nhatthm/grpcmock/request"
 	"github.com/nhatthm/grpcmock/service"
 )
@@ -75,7 +79,7 @@ func MatchPayload(ctx context.Context, expected request.Request, actual service.
 		}
 	}()
 
-	matched, err := m.Match(in)
+	matched, err := m.Match(withRawPayload(ctx, m, in))
 	if err != nil {
 		return NewError(ctx, expected, actual, m.Actual(),
 			"could not match payload: %s", err.Error(),
@@ -92,3 +96,18 @@ func MatchPayload(ctx context.Context, expected request.Request, actual service.
 
 	return nil
 }
+
+// withRawPayload gives the proto matchers the request with its wire bytes if they are kept by the server.
+func withRawPayload(ctx context.Context, m *matcher.PayloadMatcher, in interface{}) interface{} {
+	msg, ok := in.(proto.Message)
+	if !ok || !matcher.IsProtoMatcher(m.Matcher()) {
+		return in
+	}
+
+	raw, ok := rawpayload.FromContext(ctx)
+	if !ok {
+		return in
+	}
+
+	return &rawpayload.Message{Message: msg, Raw: raw}
+}
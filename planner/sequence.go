@@ -48,7 +48,7 @@ func (s *sequence) Remain() []request.Request {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.expectations
+	return copyExpectations(s.expectations)
 }
 
 func (s *sequence) Reset() {
@@ -130,6 +130,19 @@ func TestSequence_Empty(t *testing.T) {
 	assert.True(t, p.IsEmpty())
 }
 
+func TestSequence_Remain_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	p := planner.Sequence()
+
+	p.Expect(expectGetItems())
+
+	remain := p.Remain()
+	remain[0] = nil
+
+	assert.NotNil(t, p.Remain()[0])
+}
+
 func mockSequence(mocks ...func(p planner.Planner)) func() planner.Planner {
 	return func() planner.Planner {
 		p := planner.Sequence()
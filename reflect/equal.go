@@ -0,0 +1,43 @@
+package reflect
+
+import (
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Equal tells whether the two values are equal. The proto messages are compared by using proto.Equal(), the other
+// values are compared by using reflect.DeepEqual(). The pointers are unwrapped, so a message equals to a pointer to the
+// same message.
+func Equal(a, b interface{}) bool {
+	ma, okA := protoMessage(a)
+	mb, okB := protoMessage(b)
+
+	if okA && okB {
+		return proto.Equal(ma, mb)
+	}
+
+	va, vb := UnwrapValue(a), UnwrapValue(b)
+
+	if !va.IsValid() || !vb.IsValid() {
+		return va.IsValid() == vb.IsValid()
+	}
+
+	return reflect.DeepEqual(va.Interface(), vb.Interface())
+}
+
+// protoMessage returns the value as a proto message, a message that is not a pointer is copied to a new pointer.
+func protoMessage(v interface{}) (proto.Message, bool) {
+	if m, ok := v.(proto.Message); ok {
+		return m, true
+	}
+
+	val := UnwrapValue(v)
+	if val.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	m, ok := PtrValue(val).(proto.Message)
+
+	return m, ok
+}
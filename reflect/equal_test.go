@@ -0,0 +1,95 @@
+package reflect_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	grpcReflect "github.com/nhatthm/grpcmock/reflect"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+// nolint: govet
+func TestEqual(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		a        interface{}
+		b        interface{}
+		expected bool
+	}{
+		{
+			scenario: "both are nil",
+			expected: true,
+		},
+		{
+			scenario: "one is nil",
+			a:        &grpctest.Item{Id: 42},
+		},
+		{
+			scenario: "nil pointer and nil",
+			a:        (*grpctest.Item)(nil),
+			expected: true,
+		},
+		{
+			scenario: "equal messages",
+			a:        &grpctest.Item{Id: 42, Name: "Foobar"},
+			b:        &grpctest.Item{Id: 42, Name: "Foobar"},
+			expected: true,
+		},
+		{
+			scenario: "unequal messages",
+			a:        &grpctest.Item{Id: 42, Name: "Foobar"},
+			b:        &grpctest.Item{Id: 42, Name: "Baz"},
+		},
+		{
+			scenario: "different message types",
+			a:        &grpctest.Item{Id: 42},
+			b:        &grpctest.GetItemRequest{Id: 42},
+		},
+		{
+			scenario: "message value and pointer",
+			a:        grpctest.Item{Id: 42},
+			b:        &grpctest.Item{Id: 42},
+			expected: true,
+		},
+		{
+			scenario: "unequal message value and pointer",
+			a:        &grpctest.Item{Id: 42},
+			b:        grpctest.Item{Id: 41},
+		},
+		{
+			scenario: "equal values",
+			a:        map[string]int{"id": 42},
+			b:        map[string]int{"id": 42},
+			expected: true,
+		},
+		{
+			scenario: "unequal values",
+			a:        []int{41, 42},
+			b:        []int{42, 41},
+		},
+		{
+			scenario: "value and pointer",
+			a:        "foobar",
+			b:        func() *string { s := "foobar"; return &s }(),
+			expected: true,
+		},
+		{
+			scenario: "message and value",
+			a:        &grpctest.Item{Id: 42},
+			b:        42,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, grpcReflect.Equal(tc.a, tc.b))
+			assert.Equal(t, tc.expected, grpcReflect.Equal(tc.b, tc.a))
+		})
+	}
+}
@@ -9,6 +9,8 @@ const (
 	ErrIsNotPtr err = "not a pointer"
 	// ErrIsNotSlice indicates that the given value is not a slice.
 	ErrIsNotSlice err = "not a slice"
+	// ErrIsNotStruct indicates that the given value is not a struct.
+	ErrIsNotStruct err = "not a struct"
 	// ErrIsNotFunc indicates that the given value is not a function.
 	ErrIsNotFunc err = "not a function"
 	// ErrIsNotRegisterFunc indicates that the given value is not a register function.
@@ -0,0 +1,171 @@
+package reflect
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MessageFields returns the proto field names of a message. The fields of the nested messages are also returned using
+// the dot notation, for example "create_time.seconds", but the elements of the repeated and map fields are not walked
+// through. If the message is not a proto.Message, the field names are parsed from the struct tags, the "protobuf" tag
+// comes first, then the "json" tag and then the name of the field.
+//
+//    fields, err := reflect.MessageFields(&grpctest.Item{})
+//    // []string{"id", "locale", "name", "create_time", "create_time.seconds", "create_time.nanos"}
+func MessageFields(v interface{}) ([]string, error) {
+	if msg, ok := v.(proto.Message); ok {
+		return protoMessageFields(msg.ProtoReflect().Descriptor(), "", nil), nil
+	}
+
+	if v == nil || UnwrapType(v).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T", ErrIsNotStruct, v)
+	}
+
+	return structFields(UnwrapType(v), "", nil), nil
+}
+
+// RequiredFields returns the names of the required fields of a proto message. The proto3 messages do not have required
+// fields, so the result is empty for them, and for the values that are not a proto.Message.
+//
+//    fields := reflect.RequiredFields(&descriptorpb.UninterpretedOption_NamePart{})
+//    // []string{"name_part", "is_extension"}
+func RequiredFields(v interface{}) []string {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return []string{}
+	}
+
+	fields := msg.ProtoReflect().Descriptor().Fields()
+	result := make([]string, 0)
+
+	for i := 0; i < fields.Len(); i++ {
+		if fd := fields.Get(i); fd.Cardinality() == protoreflect.Required {
+			result = append(result, string(fd.Name()))
+		}
+	}
+
+	return result
+}
+
+// OneofFields returns the oneof groups of a proto message, the key is the name of the group and the value is the names of
+// its fields. The synthetic groups of the proto3 optional fields are not returned. The result is empty for the messages
+// without oneof groups, and for the values that are not a proto.Message.
+//
+//    fields := reflect.OneofFields(&structpb.Value{})
+//    // map[string][]string{"kind": {"null_value", "number_value", "string_value", "bool_value", "struct_value", "list_value"}}
+func OneofFields(v interface{}) map[string][]string {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return map[string][]string{}
+	}
+
+	oneofs := msg.ProtoReflect().Descriptor().Oneofs()
+	result := make(map[string][]string, oneofs.Len())
+
+	for i := 0; i < oneofs.Len(); i++ {
+		od := oneofs.Get(i)
+
+		if od.IsSynthetic() {
+			continue
+		}
+
+		fields := od.Fields()
+		names := make([]string, 0, fields.Len())
+
+		for j := 0; j < fields.Len(); j++ {
+			names = append(names, string(fields.Get(j).Name()))
+		}
+
+		result[string(od.Name())] = names
+	}
+
+	return result
+}
+
+func protoMessageFields(md protoreflect.MessageDescriptor, prefix string, visited []protoreflect.FullName) []string {
+	visited = append(visited, md.FullName())
+	fields := md.Fields()
+	result := make([]string, 0, fields.Len())
+
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		name := prefix + string(fd.Name())
+
+		result = append(result, name)
+
+		if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() || isVisited(visited, fd.Message().FullName()) {
+			continue
+		}
+
+		result = append(result, protoMessageFields(fd.Message(), name+".", visited)...)
+	}
+
+	return result
+}
+
+func structFields(t reflect.Type, prefix string, visited []reflect.Type) []string {
+	visited = append(visited, t)
+	result := make([]string, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if !f.IsExported() {
+			continue
+		}
+
+		name := prefix + structFieldName(f)
+		result = append(result, name)
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if ft.Kind() != reflect.Struct || isVisitedType(visited, ft) {
+			continue
+		}
+
+		result = append(result, structFields(ft, name+".", visited)...)
+	}
+
+	return result
+}
+
+func structFieldName(f reflect.StructField) string {
+	for _, part := range strings.Split(f.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(part, "name=") {
+			return strings.TrimPrefix(part, "name=")
+		}
+	}
+
+	if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+
+	return f.Name
+}
+
+func isVisited(visited []protoreflect.FullName, name protoreflect.FullName) bool {
+	for _, v := range visited {
+		if v == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isVisitedType(visited []reflect.Type, t reflect.Type) bool {
+	for _, v := range visited {
+		if v == t {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,178 @@
+package reflect_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	grpcReflect "github.com/nhatthm/grpcmock/reflect"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestMessageFields(t *testing.T) {
+	t.Parallel()
+
+	type metadata struct {
+		Labels []string `json:"labels"`
+		Owner  string
+	}
+
+	type item struct {
+		ID       int32     `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+		Name     string    `json:"name"`
+		Metadata *metadata `json:"metadata"`
+		Ignored  string    `json:"-"`
+		internal string
+	}
+
+	testCases := []struct {
+		scenario       string
+		input          interface{}
+		expectedResult []string
+		expectedError  string
+	}{
+		{
+			scenario:      "nil",
+			expectedError: "not a struct: <nil>",
+		},
+		{
+			scenario:      "not a struct",
+			input:         42,
+			expectedError: "not a struct: int",
+		},
+		{
+			scenario: "proto message with nested field",
+			input:    &grpctest.Item{},
+			expectedResult: []string{
+				"id", "locale", "name", "create_time", "create_time.seconds", "create_time.nanos",
+			},
+		},
+		{
+			scenario: "proto message with repeated fields",
+			input:    &apipb.Api{},
+			expectedResult: []string{
+				"name", "methods", "options", "version", "source_context", "source_context.file_name", "mixins", "syntax",
+			},
+		},
+		{
+			scenario: "recursive proto message",
+			input:    &structpb.Value{},
+			expectedResult: []string{
+				"null_value", "number_value", "string_value", "bool_value",
+				"struct_value", "struct_value.fields", "list_value", "list_value.values",
+			},
+		},
+		{
+			scenario: "struct",
+			input:    item{},
+			expectedResult: []string{
+				"id", "name", "metadata", "metadata.labels", "metadata.Owner", "Ignored",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := grpcReflect.MessageFields(tc.input)
+
+			assert.Equal(t, tc.expectedResult, result)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRequiredFields(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		input    interface{}
+		expected []string
+	}{
+		{
+			scenario: "nil",
+			expected: []string{},
+		},
+		{
+			scenario: "not a proto message",
+			input:    struct{ ID int }{},
+			expected: []string{},
+		},
+		{
+			scenario: "proto3 message",
+			input:    &grpctest.Item{},
+			expected: []string{},
+		},
+		{
+			scenario: "proto2 message with required fields",
+			input:    &descriptorpb.UninterpretedOption_NamePart{},
+			expected: []string{"name_part", "is_extension"},
+		},
+		{
+			scenario: "proto2 message without required fields",
+			input:    &descriptorpb.FileOptions{},
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, grpcReflect.RequiredFields(tc.input))
+		})
+	}
+}
+
+func TestOneofFields(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		input    interface{}
+		expected map[string][]string
+	}{
+		{
+			scenario: "nil",
+			expected: map[string][]string{},
+		},
+		{
+			scenario: "not a proto message",
+			input:    struct{ ID int }{},
+			expected: map[string][]string{},
+		},
+		{
+			scenario: "message without oneof",
+			input:    &grpctest.Item{},
+			expected: map[string][]string{},
+		},
+		{
+			scenario: "message with oneof",
+			input:    &structpb.Value{},
+			expected: map[string][]string{
+				"kind": {"null_value", "number_value", "string_value", "bool_value", "struct_value", "list_value"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, grpcReflect.OneofFields(tc.input))
+		})
+	}
+}
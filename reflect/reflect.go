@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"google.golang.org/grpc"
 )
@@ -39,6 +40,9 @@ const (
 	methodNameSendAndClose = "SendAndClose"
 	methodNameRecv         = "Recv"
 	methodNameSend         = "Send"
+
+	methodPrefixMustEmbedUnimplemented = "mustEmbedUnimplemented"
+	methodNameTestEmbeddedByValue      = "testEmbeddedByValue"
 )
 
 // ServiceMethod provides all information about a service method.
@@ -48,6 +52,9 @@ type ServiceMethod struct {
 	Output         interface{}
 	IsClientStream bool
 	IsServerStream bool
+	// StreamType is the type of the stream that is passed to a streaming method, for example
+	// grpctest.ItemService_ListItemsServer. It is nil for a unary method.
+	StreamType reflect.Type
 }
 
 type serviceRegistrarFunc func(desc *grpc.ServiceDesc, impl interface{})
@@ -75,7 +82,95 @@ func FindServiceMethods(svc interface{}) []ServiceMethod {
 	return result
 }
 
+// FindServiceMethodsOf finds all the service methods of the given input, which could be:
+//   - A typed-nil pointer to the server interface, for example (*grpctest.ItemServiceServer)(nil).
+//   - An implementation of the server, as a value or as a pointer, for example &Server{}.
+//   - A reflect.Type of any of the above.
+//
+// All the forms of the same service resolve to the same methods.
+//
+//    reflect.FindServiceMethodsOf(&itemServer{})
+func FindServiceMethodsOf(v interface{}) []ServiceMethod {
+	typeOf := UnwrapType(v)
+
+	if typeOf.Kind() == reflect.Interface {
+		return FindServiceMethods(typeOf)
+	}
+
+	// Use the pointer type so the methods with pointer receivers are also included.
+	typeOf = reflect.PtrTo(typeOf)
+	numMethods := typeOf.NumMethod()
+	result := make([]ServiceMethod, 0, numMethods)
+
+	for i := 0; i < numMethods; i++ {
+		method := withoutReceiver(typeOf.Method(i))
+
+		if svc := getMethodInfo(method); svc != nil {
+			result = append(result, *svc)
+		}
+	}
+
+	return result
+}
+
+// withoutReceiver removes the receiver from the method of a concrete type, so it has the same signature as the method
+// of an interface.
+func withoutReceiver(method reflect.Method) reflect.Method {
+	numIn := method.Type.NumIn()
+	in := make([]reflect.Type, 0, numIn-1)
+	out := make([]reflect.Type, 0, method.Type.NumOut())
+
+	for i := 1; i < numIn; i++ {
+		in = append(in, method.Type.In(i))
+	}
+
+	for i := 0; i < method.Type.NumOut(); i++ {
+		out = append(out, method.Type.Out(i))
+	}
+
+	method.Type = reflect.FuncOf(in, out, method.Type.IsVariadic())
+
+	return method
+}
+
+// IsUnimplementedMethod checks whether the method is added by the Unimplemented server that the generated servers embed
+// for the forward compatibility, for example mustEmbedUnimplementedItemServiceServer().
+func IsUnimplementedMethod(method reflect.Method) bool {
+	return strings.HasPrefix(method.Name, methodPrefixMustEmbedUnimplemented) ||
+		method.Name == methodNameTestEmbeddedByValue
+}
+
+// NewStreamAdapter returns the stream as a value of the stream type of a method, so it could be passed to the method
+// with reflect.Value.Call() by a dynamic handler. The stream must implement the stream type, for example the stream that
+// is created by the generated handler of the method.
+//
+//    adapter, err := reflect.NewStreamAdapter(method.StreamType, stream)
+//    if err != nil {
+//    	return err
+//    }
+//
+//    out := reflect.ValueOf(srv).MethodByName(method.Name).Call([]reflect.Value{reflect.ValueOf(in), adapter})
+func NewStreamAdapter(streamType reflect.Type, stream grpc.ServerStream) (reflect.Value, error) {
+	if streamType == nil || streamType.Kind() != reflect.Interface {
+		return reflect.Value{}, fmt.Errorf("%w: %v is not a stream type", ErrIsNotSameType, streamType)
+	}
+
+	if stream == nil || !reflect.TypeOf(stream).Implements(streamType) {
+		return reflect.Value{}, fmt.Errorf("%w: %T does not implement %s", ErrIsNotSameType, stream, streamType)
+	}
+
+	adapter := reflect.New(streamType).Elem()
+
+	adapter.Set(reflect.ValueOf(stream))
+
+	return adapter, nil
+}
+
 func getMethodInfo(method reflect.Method) *ServiceMethod {
+	if IsUnimplementedMethod(method) {
+		return nil
+	}
+
 	if isUnary(method) {
 		return &ServiceMethod{
 			Name:   method.Name,
@@ -93,6 +188,7 @@ func getMethodInfo(method reflect.Method) *ServiceMethod {
 			Input:          methodOutput(recv, clientStreamInputPosition),
 			Output:         methodInput(sendAndClose, clientStreamOutputPosition),
 			IsClientStream: true,
+			StreamType:     method.Type.In(clientStreamPosition),
 		}
 	}
 
@@ -104,6 +200,7 @@ func getMethodInfo(method reflect.Method) *ServiceMethod {
 			Input:          methodInput(method, serverStreamInputPosition),
 			Output:         methodInput(send, serverStreamOutputPosition),
 			IsServerStream: true,
+			StreamType:     method.Type.In(serverStreamPosition),
 		}
 	}
 
@@ -117,6 +214,7 @@ func getMethodInfo(method reflect.Method) *ServiceMethod {
 			Output:         methodInput(send, bidirectionalStreamOutputPosition),
 			IsClientStream: true,
 			IsServerStream: true,
+			StreamType:     method.Type.In(bidirectionalStreamPosition),
 		}
 	}
 
@@ -334,12 +432,27 @@ func UnwrapValue(v interface{}) reflect.Value {
 	return val
 }
 
-// New creates a pointer to a new object of a given type.
+// New creates a pointer to a new object of a given type. If the type is a slice or a map, the object is initialized, so
+// New([]int{}) returns a pointer to an empty slice and New(map[string]int{}) returns a pointer to an empty map instead of
+// a pointer to nil.
 func New(v interface{}) interface{} {
-	return reflect.New(UnwrapType(v)).Interface()
+	t := UnwrapType(v)
+	ptr := reflect.New(t)
+
+	// nolint: exhaustive
+	switch t.Kind() {
+	case reflect.Slice:
+		ptr.Elem().Set(reflect.MakeSlice(t, 0, 0))
+
+	case reflect.Map:
+		ptr.Elem().Set(reflect.MakeMap(t))
+	}
+
+	return ptr.Interface()
 }
 
-// NewZero creates a pointer to a nil object of a given type.
+// NewZero creates a pointer to a nil object of a given type, the pointer itself is nil for all the types, including
+// slices and maps.
 func NewZero(v interface{}) interface{} {
 	valueOf := reflect.New(UnwrapType(v))
 
@@ -385,20 +498,24 @@ func SetPtrValue(ptr interface{}, v interface{}) {
 	valueOf.Elem().Set(UnwrapValue(v))
 }
 
-// PtrValue ensures the value is a pointer. If it is not, a new pointer to the value is returned.
+// PtrValue ensures the value is a pointer. If it is not, a new pointer to a copy of the value is returned. The value
+// could also be a reflect.Value, even if it is not addressable, for example an element of a map.
 func PtrValue(v interface{}) interface{} {
-	typeOf := reflect.TypeOf(v)
+	valueOf, ok := v.(reflect.Value)
+	if !ok {
+		valueOf = reflect.ValueOf(v)
+	}
 
-	if typeOf == nil {
+	if !valueOf.IsValid() {
 		panic(ErrPtrIsNil)
 	}
 
-	if typeOf.Kind() == reflect.Ptr {
-		return v
+	if valueOf.Kind() == reflect.Ptr {
+		return valueOf.Interface()
 	}
 
-	p := reflect.New(typeOf)
-	p.Elem().Set(reflect.ValueOf(v))
+	p := reflect.New(valueOf.Type())
+	p.Elem().Set(valueOf)
 
 	return p.Interface()
 }
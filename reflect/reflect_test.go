@@ -6,9 +6,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 
 	grpcReflect "github.com/nhatthm/grpcmock/reflect"
+	"github.com/nhatthm/grpcmock/test"
 	"github.com/nhatthm/grpcmock/test/grpctest"
 )
 
@@ -67,6 +69,7 @@ func TestBuildServiceMethods(t *testing.T) {
 					Input:          &grpctest.Item{},
 					Output:         &grpctest.CreateItemsResponse{},
 					IsClientStream: true,
+					StreamType:     reflect.TypeOf((*grpctest.ItemService_CreateItemsServer)(nil)).Elem(),
 				},
 				{
 					Name:   "GetItem",
@@ -78,6 +81,7 @@ func TestBuildServiceMethods(t *testing.T) {
 					Input:          &grpctest.ListItemsRequest{},
 					Output:         &grpctest.Item{},
 					IsServerStream: true,
+					StreamType:     reflect.TypeOf((*grpctest.ItemService_ListItemsServer)(nil)).Elem(),
 				},
 				{
 					Name:           "TransformItems",
@@ -85,6 +89,7 @@ func TestBuildServiceMethods(t *testing.T) {
 					Output:         &grpctest.Item{},
 					IsClientStream: true,
 					IsServerStream: true,
+					StreamType:     reflect.TypeOf((*grpctest.ItemService_TransformItemsServer)(nil)).Elem(),
 				},
 			},
 		},
@@ -102,6 +107,198 @@ func TestBuildServiceMethods(t *testing.T) {
 	}
 }
 
+type listItemsServer struct {
+	grpc.ServerStream
+}
+
+func (listItemsServer) Send(*grpctest.Item) error {
+	return nil
+}
+
+func TestNewStreamAdapter(t *testing.T) {
+	t.Parallel()
+
+	stream := &listItemsServer{}
+
+	testCases := []struct {
+		scenario      string
+		streamType    reflect.Type
+		stream        grpc.ServerStream
+		expectedError string
+	}{
+		{
+			scenario:      "no stream type",
+			stream:        stream,
+			expectedError: "not same type: <nil> is not a stream type",
+		},
+		{
+			scenario:      "stream type is not an interface",
+			streamType:    reflect.TypeOf(stream),
+			stream:        stream,
+			expectedError: "not same type: *reflect_test.listItemsServer is not a stream type",
+		},
+		{
+			scenario:      "no stream",
+			streamType:    reflect.TypeOf((*grpctest.ItemService_ListItemsServer)(nil)).Elem(),
+			expectedError: "not same type: <nil> does not implement grpctest.ItemService_ListItemsServer",
+		},
+		{
+			scenario:      "stream does not implement the stream type",
+			streamType:    reflect.TypeOf((*grpctest.ItemService_TransformItemsServer)(nil)).Elem(),
+			stream:        stream,
+			expectedError: "not same type: *reflect_test.listItemsServer does not implement grpctest.ItemService_TransformItemsServer",
+		},
+		{
+			scenario:   "stream implements the stream type",
+			streamType: reflect.TypeOf((*grpctest.ItemService_ListItemsServer)(nil)).Elem(),
+			stream:     stream,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := grpcReflect.NewStreamAdapter(tc.streamType, tc.stream)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.False(t, actual.IsValid())
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.streamType, actual.Type())
+			assert.Same(t, tc.stream, actual.Interface())
+		})
+	}
+}
+
+func TestFindServiceMethods_StreamType(t *testing.T) {
+	t.Parallel()
+
+	methods := grpcReflect.FindServiceMethodsOf(&test.Service{})
+	streamTypes := make(map[string]reflect.Type, len(methods))
+
+	for _, m := range methods {
+		streamTypes[m.Name] = m.StreamType
+	}
+
+	expected := map[string]reflect.Type{
+		"GetItem":        nil,
+		"CreateItems":    reflect.TypeOf((*grpctest.ItemService_CreateItemsServer)(nil)).Elem(),
+		"ListItems":      reflect.TypeOf((*grpctest.ItemService_ListItemsServer)(nil)).Elem(),
+		"TransformItems": reflect.TypeOf((*grpctest.ItemService_TransformItemsServer)(nil)).Elem(),
+	}
+
+	assert.Equal(t, expected, streamTypes)
+}
+
+func TestFindServiceMethodsOf(t *testing.T) {
+	t.Parallel()
+
+	expected := grpcReflect.FindServiceMethods((*grpctest.ItemServiceServer)(nil))
+
+	testCases := []struct {
+		scenario string
+		service  interface{}
+	}{
+		{
+			scenario: "typed-nil pointer to interface",
+			service:  (*grpctest.ItemServiceServer)(nil),
+		},
+		{
+			scenario: "type of interface",
+			service:  reflect.TypeOf((*grpctest.ItemServiceServer)(nil)).Elem(),
+		},
+		{
+			scenario: "pointer to implementation",
+			service:  &test.Service{},
+		},
+		{
+			scenario: "implementation value",
+			service:  grpctest.UnimplementedItemServiceServer{},
+		},
+		{
+			scenario: "type of implementation",
+			service:  reflect.TypeOf(&test.Service{}),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			actual := grpcReflect.FindServiceMethodsOf(tc.service)
+
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+type unimplementedItemServer struct {
+	grpctest.UnimplementedItemServiceServer
+}
+
+func TestIsUnimplementedMethod(t *testing.T) {
+	t.Parallel()
+
+	svc := reflect.TypeOf((*grpctest.ItemServiceServer)(nil)).Elem()
+
+	testCases := []struct {
+		scenario string
+		method   string
+		expected bool
+	}{
+		{
+			scenario: "must embed",
+			method:   "mustEmbedUnimplementedItemServiceServer",
+			expected: true,
+		},
+		{
+			scenario: "service method",
+			method:   "GetItem",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			method, ok := svc.MethodByName(tc.method)
+			require.True(t, ok)
+
+			assert.Equal(t, tc.expected, grpcReflect.IsUnimplementedMethod(method))
+		})
+	}
+
+	assert.True(t, grpcReflect.IsUnimplementedMethod(reflect.Method{Name: "testEmbeddedByValue"}))
+}
+
+func TestFindServiceMethods_UnimplementedEmbedding(t *testing.T) {
+	t.Parallel()
+
+	expected := []string{"CreateItems", "GetItem", "ListItems", "TransformItems"}
+
+	for _, svc := range []interface{}{
+		(*grpctest.ItemServiceServer)(nil),
+		&unimplementedItemServer{},
+	} {
+		methods := grpcReflect.FindServiceMethodsOf(svc)
+		actual := make([]string, 0, len(methods))
+
+		for _, m := range methods {
+			actual = append(actual, m.Name)
+		}
+
+		assert.Equal(t, expected, actual, "%T", svc)
+	}
+}
+
 func TestIsNil(t *testing.T) {
 	t.Parallel()
 
@@ -271,10 +468,75 @@ func TestUnwrapValue(t *testing.T) {
 func TestNew(t *testing.T) {
 	t.Parallel()
 
-	actual := grpcReflect.New(&grpctest.Item{})
-	expected := &grpctest.Item{}
+	testCases := []struct {
+		scenario string
+		v        interface{}
+		expected interface{}
+	}{
+		{
+			scenario: "struct",
+			v:        &grpctest.Item{},
+			expected: &grpctest.Item{},
+		},
+		{
+			scenario: "slice",
+			v:        []*grpctest.Item{{Id: 42}},
+			expected: &[]*grpctest.Item{},
+		},
+		{
+			scenario: "ptr of slice",
+			v:        &[]*grpctest.Item{},
+			expected: &[]*grpctest.Item{},
+		},
+		{
+			scenario: "map",
+			v:        map[string]*grpctest.Item{"foo": {Id: 42}},
+			expected: &map[string]*grpctest.Item{},
+		},
+	}
 
-	assert.Equal(t, expected, actual)
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			actual := grpcReflect.New(tc.v)
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.Run("value", func(b *testing.B) {
+		v := &grpctest.Item{}
+
+		b.ReportAllocs()
+
+		for n := 0; n < b.N; n++ {
+			_ = grpcReflect.New(v)
+		}
+	})
+
+	b.Run("resolved type", func(b *testing.B) {
+		t := grpcReflect.UnwrapType(&grpctest.Item{})
+
+		b.ReportAllocs()
+
+		for n := 0; n < b.N; n++ {
+			_ = grpcReflect.New(t)
+		}
+	})
+}
+
+func TestNew_MapIsWritable(t *testing.T) {
+	t.Parallel()
+
+	actual := grpcReflect.New(map[string]int{}).(*map[string]int)
+
+	assert.NotPanics(t, func() {
+		(*actual)["foo"] = 42
+	})
 }
 
 func TestNewZero(t *testing.T) {
@@ -286,6 +548,24 @@ func TestNewZero(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestNewZero_Slice(t *testing.T) {
+	t.Parallel()
+
+	actual := grpcReflect.NewZero([]*grpctest.Item{})
+	expected := (*[]*grpctest.Item)(nil)
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestNewZero_Map(t *testing.T) {
+	t.Parallel()
+
+	actual := grpcReflect.NewZero(map[string]*grpctest.Item{})
+	expected := (*map[string]*grpctest.Item)(nil)
+
+	assert.Equal(t, expected, actual)
+}
+
 func TestNewValue(t *testing.T) {
 	t.Parallel()
 
@@ -433,12 +713,59 @@ func TestPtrValue(t *testing.T) {
 	}
 }
 
+func TestPtrValue_Pointer(t *testing.T) {
+	t.Parallel()
+
+	num := 42
+
+	assert.Same(t, &num, grpcReflect.PtrValue(&num))
+	assert.Same(t, &num, grpcReflect.PtrValue(reflect.ValueOf(&num)))
+}
+
+func TestPtrValue_ReflectValue(t *testing.T) {
+	t.Parallel()
+
+	items := map[string]grpctest.Item{"foo": {Id: 42}}
+
+	// Map elements are not addressable.
+	valueOf := reflect.ValueOf(items).MapIndex(reflect.ValueOf("foo"))
+
+	actual := grpcReflect.PtrValue(valueOf)
+
+	assert.Equal(t, &grpctest.Item{Id: 42}, actual)
+
+	// The result is a copy.
+	actual.(*grpctest.Item).Id = 43
+
+	assert.Equal(t, int32(42), items["foo"].Id)
+}
+
 func TestPtrValue_Panic(t *testing.T) {
 	t.Parallel()
 
-	assert.Panics(t, func() {
-		grpcReflect.PtrValue(nil)
-	})
+	testCases := []struct {
+		scenario string
+		value    interface{}
+	}{
+		{
+			scenario: "nil",
+		},
+		{
+			scenario: "invalid reflect value",
+			value:    reflect.Value{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			assert.PanicsWithError(t, grpcReflect.ErrPtrIsNil.Error(), func() {
+				grpcReflect.PtrValue(tc.value)
+			})
+		})
+	}
 }
 
 func TestParseRegisterFunc(t *testing.T) {
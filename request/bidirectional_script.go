@@ -0,0 +1,95 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nhatthm/go-matcher"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	grpcMatcher "github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/reflect"
+	"github.com/nhatthm/grpcmock/streamer"
+)
+
+// Step is a step of the script of a bidirectional stream.
+//
+// See: BidirectionalStreamRequest.Script(), Recv(), Send().
+type Step struct {
+	execute func(ctx context.Context, s *streamer.BidirectionalStreamer) error
+}
+
+// Recv receives a message from the client and matches it. The expectation could be a matcher.Matcher or a message, a
+// message is compared by using reflect.Equal(). If the message does not match, or the client closes the stream, the
+// request fails with codes.FailedPrecondition.
+//
+//    Server.ExpectBidirectionalStream("grpctest.Service/TransformItems").
+//    	Script(
+//    		request.Recv(matcher.Field("id", 41)),
+//    		request.Send(&grpctest.Item{Id: 41, Name: "Foobar"}),
+//    		request.Recv(&grpctest.Item{Id: 42}),
+//    	)
+//
+// See: Send().
+func Recv(expected interface{}) Step {
+	m := newScriptMatcher(expected)
+
+	return Step{
+		execute: func(_ context.Context, s *streamer.BidirectionalStreamer) error {
+			msg := reflect.New(s.InputType())
+
+			if err := s.RecvMsg(msg); err != nil {
+				if errors.Is(err, io.EOF) {
+					return status.Errorf(codes.FailedPrecondition, "expected message: %s, received: EOF", m.Expected())
+				}
+
+				return err
+			}
+
+			matched, err := m.Match(msg)
+			if err != nil {
+				return status.Errorf(codes.FailedPrecondition, "could not match message: %s", err.Error())
+			}
+
+			if !matched {
+				return status.Errorf(codes.FailedPrecondition, "expected message: %s, received: %s", m.Expected(), scriptMessageString(msg))
+			}
+
+			return nil
+		},
+	}
+}
+
+// Send sends a message to the client. It could be []byte, string, or a value of the output type of the method.
+//
+// See: Recv().
+func Send(msg interface{}) Step {
+	return Step{
+		execute: func(ctx context.Context, s *streamer.BidirectionalStreamer) error {
+			return stepSend(s.OutputType(), msg)(ctx, s)
+		},
+	}
+}
+
+func newScriptMatcher(expected interface{}) matcher.Matcher {
+	if m, ok := expected.(matcher.Matcher); ok {
+		return m
+	}
+
+	return grpcMatcher.Fn(scriptMessageString(expected), func(actual interface{}) (bool, error) {
+		return reflect.Equal(expected, actual), nil
+	})
+}
+
+func scriptMessageString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+
+	return string(b)
+}
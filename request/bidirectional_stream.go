@@ -2,7 +2,9 @@ package request
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 
 	grpcErrors "github.com/nhatthm/grpcmock/errors"
 	grpcMatcher "github.com/nhatthm/grpcmock/matcher"
+	"github.com/nhatthm/grpcmock/reflect"
 	"github.com/nhatthm/grpcmock/service"
 	"github.com/nhatthm/grpcmock/streamer"
 )
@@ -84,6 +87,18 @@ func (r *BidirectionalStreamRequest) WithHeaders(headers map[string]interface{})
 	return r
 }
 
+// WithHeaderMatcher sets the expected headers of the given request using a matcher.HeaderMatcher.
+//
+//    Server.ExpectBidirectionalStream("grpctest.Service/TransformItems").
+//    	WithHeaderMatcher(matcher.Authority("example.com"))
+func (r *BidirectionalStreamRequest) WithHeaderMatcher(m grpcMatcher.HeaderMatcher) *BidirectionalStreamRequest {
+	for header, value := range m {
+		r.WithHeader(header, value)
+	}
+
+	return r
+}
+
 // ReturnCode sets the response code.
 //
 //    Server.ExpectBidirectionalStream("grpc.Service/TransformItems").
@@ -153,6 +168,72 @@ func (r *BidirectionalStreamRequest) Run(handler func(ctx context.Context, s grp
 	r.run = handler
 }
 
+// Echo sends every received message back to the client until the client closes the stream. The input and the output
+// of the method must be of the same type.
+//
+//    Server.ExpectBidirectionalStream("grpc.Service/TransformItems").
+//    	Echo()
+func (r *BidirectionalStreamRequest) Echo() {
+	r.ReturnCode(codes.OK)
+	r.Run(func(_ context.Context, s grpc.ServerStream) error {
+		bs, ok := s.(*streamer.BidirectionalStreamer)
+		if !ok {
+			return fmt.Errorf("%w: could not echo with %T", grpcErrors.ErrUnsupportedDataType, s)
+		}
+
+		if bs.InputType() != bs.OutputType() {
+			return fmt.Errorf("%w: could not echo %s as %s", grpcErrors.ErrUnsupportedDataType, bs.InputType().String(), bs.OutputType().String())
+		}
+
+		for {
+			msg := reflect.New(bs.InputType())
+			err := s.RecvMsg(msg)
+
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if err := s.SendMsg(msg); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// Script runs the steps in order, so that a conversational flow could be mocked precisely. The request fails with
+// codes.FailedPrecondition if a message does not match a Recv() step.
+//
+//    Server.ExpectBidirectionalStream("grpc.Service/TransformItems").
+//    	Script(
+//    		request.Recv(&grpctest.Item{Id: 41}),
+//    		request.Send(&grpctest.Item{Id: 41, Name: "Foobar"}),
+//    		request.Recv(&grpctest.Item{Id: 42}),
+//    	)
+//
+// See: Recv(), Send().
+func (r *BidirectionalStreamRequest) Script(steps ...Step) {
+	r.ReturnCode(codes.OK)
+	r.Run(func(ctx context.Context, s grpc.ServerStream) error {
+		bs := s.(*streamer.BidirectionalStreamer) // nolint: errcheck
+
+		for i, st := range steps {
+			if err := st.execute(ctx, bs); err != nil {
+				if se, ok := status.FromError(err); ok {
+					return status.Errorf(se.Code(), "step #%d: %s", i, se.Message())
+				}
+
+				return fmt.Errorf("step #%d: %w", i, err)
+			}
+		}
+
+		return nil
+	})
+}
+
 // handle executes the GRPC request.
 func (r *BidirectionalStreamRequest) handle(ctx context.Context, in interface{}, _ interface{}) error {
 	// Block if specified.
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -15,7 +16,9 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	grpcErrors "github.com/nhatthm/grpcmock/errors"
 	grpcMatcher "github.com/nhatthm/grpcmock/matcher"
+	grpcMock "github.com/nhatthm/grpcmock/mock/grpc"
 	"github.com/nhatthm/grpcmock/streamer"
 	"github.com/nhatthm/grpcmock/test"
 	"github.com/nhatthm/grpcmock/test/grpctest"
@@ -289,6 +292,87 @@ func TestBidirectionalStreamRequest_Run_GenericError(t *testing.T) {
 	assert.EqualError(t, err, expected)
 }
 
+func TestBidirectionalStreamRequest_Echo_Success(t *testing.T) {
+	t.Parallel()
+
+	r := newTransformItemsRequest()
+	r.Echo()
+
+	s := test.MockTransformItemsStreamer(
+		test.MockStreamRecvItemSuccess(&grpctest.Item{Id: 40}),
+		test.MockStreamSendItemSuccess(&grpctest.Item{Id: 40}),
+		test.MockStreamRecvItemSuccess(&grpctest.Item{Id: 41}),
+		test.MockStreamSendItemSuccess(&grpctest.Item{Id: 41}),
+		test.MockStreamRecvItemEOF(),
+	)(t)
+
+	err := Handle(context.Background(), r, s, s)
+
+	assert.NoError(t, err)
+}
+
+func TestBidirectionalStreamRequest_Echo_RecvError(t *testing.T) {
+	t.Parallel()
+
+	r := newTransformItemsRequest()
+	r.Echo()
+
+	s := test.MockTransformItemsStreamer(func(s *grpcMock.ServerStream) {
+		s.On("RecvMsg", &grpctest.Item{}).
+			Return(errors.New("recv error"))
+	})(t)
+
+	err := Handle(context.Background(), r, s, s)
+
+	assert.EqualError(t, err, `rpc error: code = Internal desc = recv error`)
+}
+
+func TestBidirectionalStreamRequest_Echo_SendError(t *testing.T) {
+	t.Parallel()
+
+	r := newTransformItemsRequest()
+	r.Echo()
+
+	s := test.MockTransformItemsStreamer(
+		test.MockStreamRecvItemSuccess(&grpctest.Item{Id: 40}),
+		func(s *grpcMock.ServerStream) {
+			s.On("SendMsg", &grpctest.Item{Id: 40}).
+				Return(errors.New("send error"))
+		},
+	)(t)
+
+	err := Handle(context.Background(), r, s, s)
+
+	assert.EqualError(t, err, `rpc error: code = Internal desc = send error`)
+}
+
+func TestBidirectionalStreamRequest_Echo_DifferentTypes(t *testing.T) {
+	t.Parallel()
+
+	r := newTransformItemsRequest()
+	r.Echo()
+
+	s := streamer.NewBidirectionalStreamer(grpcMock.NoMockServerStream(t), reflect.TypeOf(grpctest.Item{}), reflect.TypeOf(grpctest.CreateItemsResponse{}))
+
+	err := Handle(context.Background(), r, s, s)
+
+	expected := `rpc error: code = Internal desc = unsupported data type: could not echo grpctest.Item as grpctest.CreateItemsResponse`
+
+	assert.EqualError(t, err, expected)
+}
+
+func TestBidirectionalStreamRequest_Echo_UnsupportedStream(t *testing.T) {
+	t.Parallel()
+
+	r := newTransformItemsRequest()
+	r.Echo()
+
+	err := r.run(context.Background(), grpcMock.NoMockServerStream(t))
+
+	assert.ErrorIs(t, err, grpcErrors.ErrUnsupportedDataType)
+	assert.EqualError(t, err, `unsupported data type: could not echo with *grpc.ServerStream`)
+}
+
 func TestBidirectionalStreamRequest_ReturnStatusError(t *testing.T) {
 	t.Parallel()
 
@@ -40,6 +40,8 @@ type ClientStreamRequest struct {
 	requestHeader grpcMatcher.HeaderMatcher
 	// requestPayload is the expected parameters of the given request.
 	requestPayload *grpcMatcher.PayloadMatcher
+	// messageCount is the expected number of messages sent by the client, nil means any.
+	messageCount *messageCountBounds
 
 	// statusCode is the response code when the request is handled.
 	statusCode codes.Code
@@ -91,8 +93,20 @@ func (r *ClientStreamRequest) WithHeaders(headers map[string]interface{}) *Clien
 	return r
 }
 
+// WithHeaderMatcher sets the expected headers of the given request using a matcher.HeaderMatcher.
+//
+//    Server.ExpectClientStream("grpctest.Service/CreateItems").
+//    	WithHeaderMatcher(matcher.Authority("example.com"))
+func (r *ClientStreamRequest) WithHeaderMatcher(m grpcMatcher.HeaderMatcher) *ClientStreamRequest {
+	for header, value := range m {
+		r.WithHeader(header, value)
+	}
+
+	return r
+}
+
 // WithPayload sets the expected payload of the given request. It could be a JSON []byte, JSON string, an object (that will be marshaled),
-// or a custom matcher.
+// or a custom matcher. A proto matcher, for example matcher.Field(), must match every message of the stream.
 //
 //    Server.ExpectClientStream("grpctest.Service/CreateItems").
 //    	WithPayload(`[{"name": "Foobar"}]`)
@@ -107,6 +121,21 @@ func (r *ClientStreamRequest) WithPayload(in interface{}) *ClientStreamRequest {
 	return r
 }
 
+// WithPayloads sets a matcher for all the messages sent by the client. The matcher receives the messages as a
+// []interface{}, in the same order as they were sent.
+//
+//    Server.ExpectClientStream("grpctest.Service/CreateItems").
+//    	WithPayloads(matcher.SequenceEq(&grpctest.Item{Id: 41}, &grpctest.Item{Id: 42}))
+//
+// See: ClientStreamRequest.WithPayload().
+func (r *ClientStreamRequest) WithPayloads(m matcher.Matcher) *ClientStreamRequest {
+	return r.WithPayload(func() (string, grpcMatcher.MatchFn) {
+		return m.Expected(), func(in interface{}) (bool, error) {
+			return m.Match(toInterfaceSlice(in))
+		}
+	})
+}
+
 // WithPayloadf formats according to a format specifier and use it as the expected payload of the given request.
 //
 //    Server.ExpectClientStream("grpctest.Service/CreateItems").
@@ -117,6 +146,24 @@ func (r *ClientStreamRequest) WithPayloadf(format string, args ...interface{}) *
 	return r.WithPayload(fmt.Sprintf(format, args...))
 }
 
+// WithMessageCount sets the expected number of messages sent by the client, inclusively. Use -1 to leave either side
+// unbounded. If the number of messages is out of the bounds, the request fails with codes.FailedPrecondition.
+//
+//    Server.ExpectClientStream("grpctest.Service/CreateItems").
+//    	WithMessageCount(1, 10)
+func (r *ClientStreamRequest) WithMessageCount(min, max int) *ClientStreamRequest {
+	if min >= 0 && max >= 0 && min > max {
+		panic(fmt.Errorf("invalid message count: min %d is greater than max %d", min, max)) // nolint: goerr113
+	}
+
+	r.lock()
+	defer r.unlock()
+
+	r.messageCount = &messageCountBounds{min: min, max: max}
+
+	return r
+}
+
 // ReturnCode sets the response code.
 //
 //    Server.ExpectClientStream("grpc.Service/CreateItems").
@@ -186,6 +233,18 @@ func (r *ClientStreamRequest) Return(v interface{}) {
 	})
 }
 
+// ReturnDefault returns an empty message of the output type of the method with an OK status, which is handy for the
+// methods that only acknowledge the request. Without a Return, the request fails with codes.Unimplemented.
+//
+//    Server.ExpectClientStream("grpctest.Service/CreateItems").
+//    	ReturnDefault()
+func (r *ClientStreamRequest) ReturnDefault() {
+	r.ReturnCode(codes.OK)
+	r.Run(func(context.Context, grpc.ServerStream) (interface{}, error) {
+		return reflect.New(r.serviceDesc.Output), nil
+	})
+}
+
 // Returnf formats according to a format specifier and use it as the result to return to client.
 //
 //    Server.ExpectClientStream("grpc.Service/CreateItems").
@@ -255,6 +314,12 @@ func (r *ClientStreamRequest) handle(ctx context.Context, in interface{}, out in
 
 	stream := in.(*streamer.ClientStreamer) // nolint: errcheck
 
+	if r.messageCount != nil {
+		if err := r.messageCount.check(stream); err != nil {
+			return err
+		}
+	}
+
 	resp, err := r.run(ctx, stream)
 	if err != nil {
 		return grpcErrors.StatusError(err)
@@ -364,3 +429,40 @@ func (r *ClientStreamRequest) headerMatcher() grpcMatcher.HeaderMatcher {
 func (r *ClientStreamRequest) payloadMatcher() *grpcMatcher.PayloadMatcher {
 	return r.requestPayload
 }
+
+// messageCountBounds is the expected number of messages sent by the client, -1 means unbounded.
+type messageCountBounds struct {
+	min, max int
+}
+
+// check reads all the messages from the stream, without consuming them, and checks the number of messages.
+func (b *messageCountBounds) check(s *streamer.ClientStreamer) error {
+	in, err := streamer.ClientStreamerPayload(s)
+	if err != nil {
+		return grpcErrors.StatusError(err)
+	}
+
+	n := len(toInterfaceSlice(in))
+
+	if (b.min >= 0 && n < b.min) || (b.max >= 0 && n > b.max) {
+		return status.Errorf(codes.FailedPrecondition, "unexpected number of messages, got %d, want %s", n, b)
+	}
+
+	return nil
+}
+
+// String returns the description of the bounds.
+func (b *messageCountBounds) String() string {
+	switch {
+	case b.min >= 0 && b.max >= 0:
+		return fmt.Sprintf("between %d and %d", b.min, b.max)
+
+	case b.min >= 0:
+		return fmt.Sprintf("at least %d", b.min)
+
+	case b.max >= 0:
+		return fmt.Sprintf("at most %d", b.max)
+	}
+
+	return "any"
+}
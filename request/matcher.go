@@ -2,6 +2,7 @@ package request
 
 import (
 	"encoding/json"
+	"reflect"
 	"regexp"
 
 	"github.com/nhatthm/go-matcher"
@@ -17,6 +18,13 @@ func matchUnaryPayload(in interface{}) *grpcMatcher.PayloadMatcher {
 	case []byte, string:
 		return grpcMatcher.Payload(matcher.JSON(value.String(in)), decodeUnaryPayload)
 
+	case grpcMatcher.ProtoMatcher:
+		if v.MatchProto() {
+			return grpcMatcher.Payload(v, nil)
+		}
+
+		return grpcMatcher.Payload(v, decodeUnaryPayload)
+
 	case matcher.Matcher,
 		func() matcher.Matcher,
 		*regexp.Regexp:
@@ -38,6 +46,13 @@ func matchClientStreamPayload(in interface{}) *grpcMatcher.PayloadMatcher {
 	case []byte, string:
 		return grpcMatcher.Payload(matcher.JSON(value.String(v)), decodeClientStreamPayload)
 
+	case grpcMatcher.ProtoMatcher:
+		if v.MatchProto() {
+			return matchClientStreamPayloadWithCustomMatcher(v.Expected(), matchEachMessage(v))
+		}
+
+		return grpcMatcher.Payload(v, decodeClientStreamPayload)
+
 	case matcher.Matcher,
 		func() matcher.Matcher,
 		*regexp.Regexp:
@@ -64,6 +79,42 @@ func matchClientStreamPayloadWithCustomMatcher(expected string, match grpcMatche
 	}), nil)
 }
 
+// matchEachMessage matches every message of a client stream with a proto matcher. A stream without any message does not
+// match.
+func matchEachMessage(m matcher.Matcher) grpcMatcher.MatchFn {
+	return func(in interface{}) (bool, error) {
+		msgs := toInterfaceSlice(in)
+
+		if len(msgs) == 0 {
+			return false, nil
+		}
+
+		for _, msg := range msgs {
+			if matched, err := m.Match(msg); err != nil || !matched {
+				return false, err
+			}
+		}
+
+		return true, nil
+	}
+}
+
+func toInterfaceSlice(in interface{}) []interface{} {
+	valueOf := reflect.ValueOf(in)
+
+	if valueOf.Kind() != reflect.Slice {
+		return nil
+	}
+
+	result := make([]interface{}, valueOf.Len())
+
+	for i := range result {
+		result[i] = valueOf.Index(i).Interface()
+	}
+
+	return result
+}
+
 func decodeUnaryPayload(in interface{}) (string, error) {
 	switch v := in.(type) {
 	case []byte:
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"path/filepath"
 	"sync"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/spf13/afero"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	grpcErrors "github.com/nhatthm/grpcmock/errors"
@@ -90,6 +92,18 @@ func (r *ServerStreamRequest) WithHeaders(headers map[string]interface{}) *Serve
 	return r
 }
 
+// WithHeaderMatcher sets the expected headers of the given request using a matcher.HeaderMatcher.
+//
+//    Server.ExpectServerStream("grpctest.Service/ListItems").
+//    	WithHeaderMatcher(matcher.Authority("example.com"))
+func (r *ServerStreamRequest) WithHeaderMatcher(m grpcMatcher.HeaderMatcher) *ServerStreamRequest {
+	for header, value := range m {
+		r.WithHeader(header, value)
+	}
+
+	return r
+}
+
 // WithPayload sets the expected payload of the given request. It could be a JSON []byte, JSON string, or a slice of objects (that will be marshaled).
 //
 //    Server.ExpectServerStream("grpctest.Service/ListItems").
@@ -241,6 +255,98 @@ func (r *ServerStreamRequest) ReturnFile(filePath string) {
 	})
 }
 
+// ReturnGen sends count messages generated by fn, fn is called with the index of the message, from 0 to count-1. The
+// messages are generated while sending, so a long stream does not have to be allocated up front. The generated messages
+// must be of the output type of the method, or a JSON []byte or string.
+//
+//    Server.ExpectServerStream("grpc.Service/ListItems").
+//    	ReturnGen(1000, func(i int) interface{} {
+//    		return &grpctest.Item{Id: int32(i)}
+//    	})
+//
+// See: ServerStreamRequest.Return(), ServerStreamRequest.ReturnStream().
+func (r *ServerStreamRequest) ReturnGen(count int, fn func(i int) interface{}) {
+	r.ReturnCode(codes.OK)
+	r.Run(func(ctx context.Context, _ interface{}, s grpc.ServerStream) error {
+		return newServerStreamHandler(s.(*streamer.ServerStreamer)).
+			SendGen(count, fn).
+			handle(ctx)
+	})
+}
+
+// ReturnFromReader reads the length-delimited records from the reader and sends each of them as a message, so a large
+// fixture does not have to be loaded into memory. Each record is a varint of the length followed by the bytes of the
+// message. The records are decoded by decode, or unmarshaled into the output type of the method if decode is nil. A
+// trailing incomplete record fails the stream with codes.Internal.
+//
+// The reader is consumed by the first call, so the expectation should not be repeated.
+//
+//    f, err := os.Open("resources/fixtures/items.bin")
+//    // handle error.
+//
+//    Server.ExpectServerStream("grpc.Service/ListItems").
+//    	ReturnFromReader(f, nil)
+//
+// See: ServerStreamRequest.ReturnFile(), ServerStreamRequest.ReturnGen().
+func (r *ServerStreamRequest) ReturnFromReader(rd io.Reader, decode func([]byte) (interface{}, error)) {
+	r.ReturnCode(codes.OK)
+	r.Run(func(ctx context.Context, _ interface{}, s grpc.ServerStream) error {
+		return newServerStreamHandler(s.(*streamer.ServerStreamer)).
+			SendFromReader(rd, decode).
+			handle(ctx)
+	})
+}
+
+// ReturnAndClose sends the messages and then closes the stream without error, so the client receives io.EOF. This is
+// handy for simulating a server that closes the stream earlier than expected.
+//
+//    Server.ExpectServerStream("grpc.Service/ListItems").
+//    	ReturnAndClose([]interface{}{&grpctest.Item{Id: 41}, `{"id": 42}`})
+//
+// See: ServerStreamRequest.ReturnErrorAfter().
+func (r *ServerStreamRequest) ReturnAndClose(msgs []interface{}) {
+	r.ReturnCode(codes.OK)
+	r.Run(func(ctx context.Context, _ interface{}, s grpc.ServerStream) error {
+		return newServerStreamHandlerWithMessages(s.(*streamer.ServerStreamer), msgs).
+			handle(ctx)
+	})
+}
+
+// ReturnErrorAfter sends the messages and then closes the stream with an error.
+//
+//    Server.ExpectServerStream("grpc.Service/ListItems").
+//    	ReturnErrorAfter([]interface{}{&grpctest.Item{Id: 41}}, codes.Unavailable, "server is going away")
+//
+// See: ServerStreamRequest.ReturnAndClose().
+func (r *ServerStreamRequest) ReturnErrorAfter(msgs []interface{}, code codes.Code, msg string) {
+	r.ReturnCode(codes.OK)
+	r.Run(func(ctx context.Context, _ interface{}, s grpc.ServerStream) error {
+		h := newServerStreamHandlerWithMessages(s.(*streamer.ServerStreamer), msgs)
+
+		h.ReturnError(code, msg)
+
+		return h.handle(ctx)
+	})
+}
+
+// ReturnHeaderThenError sends the header and then closes the stream with an error, without sending any message. The
+// client is still able to read the header before the error surfaces.
+//
+//    Server.ExpectServerStream("grpc.Service/ListItems").
+//    	ReturnHeaderThenError(metadata.Pairs("x-request-id", "42"), codes.Unavailable, "server is going away")
+//
+// See: ServerStreamRequest.ReturnErrorAfter().
+func (r *ServerStreamRequest) ReturnHeaderThenError(header metadata.MD, code codes.Code, msg string) {
+	r.ReturnCode(codes.OK)
+	r.Run(func(_ context.Context, _ interface{}, s grpc.ServerStream) error {
+		if err := s.SendHeader(header); err != nil {
+			return err
+		}
+
+		return status.Error(code, msg)
+	})
+}
+
 // ReturnStream returns the stream with custom behaviors.
 //
 //    Server.ExpectServerStream("grpc.Service/ListItems").
@@ -6,6 +6,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type baseStreamHandler struct {
@@ -33,6 +34,11 @@ func (h *baseStreamHandler) addHeader(key, value string) {
 
 func (h *baseStreamHandler) handle(ctx context.Context) error {
 	for _, st := range h.steps {
+		// Stop running the steps if the client is gone.
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
 		if err := st.execute(ctx, h.stream); err != nil {
 			return err
 		}
@@ -2,6 +2,7 @@ package request
 
 import (
 	"context"
+	"io"
 	"reflect"
 	"time"
 
@@ -74,7 +75,35 @@ func (h *serverStreamHandler) SendMany(v interface{}) *serverStreamHandler {
 	return h
 }
 
+// SendGen sends count messages generated by fn, the messages are generated one by one while sending.
+func (h *serverStreamHandler) SendGen(count int, fn func(i int) interface{}) *serverStreamHandler {
+	h.addStep(streamStepFunc(func(ctx context.Context, s grpc.ServerStream) error {
+		return stepSendGen(h.outputType, count, fn)(ctx, s)
+	}))
+
+	return h
+}
+
+// SendFromReader sends the length-delimited records of the reader, the records are read one by one while sending.
+func (h *serverStreamHandler) SendFromReader(r io.Reader, decode func([]byte) (interface{}, error)) *serverStreamHandler {
+	h.addStep(streamStepFunc(func(ctx context.Context, s grpc.ServerStream) error {
+		return stepSendFromReader(h.outputType, r, decode)(ctx, s)
+	}))
+
+	return h
+}
+
 func newServerStreamHandler(stream *streamer.ServerStreamer) *serverStreamHandler {
 	return (&serverStreamHandler{}).
 		withStreamer(stream)
 }
+
+func newServerStreamHandlerWithMessages(stream *streamer.ServerStreamer, msgs []interface{}) *serverStreamHandler {
+	h := newServerStreamHandler(stream)
+
+	for _, msg := range msgs {
+		h.Send(msg)
+	}
+
+	return h
+}
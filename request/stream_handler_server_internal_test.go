@@ -218,6 +218,32 @@ func TestServerStreamHandler_WaitFor(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestServerStreamHandler_ClientCancelled(t *testing.T) {
+	t.Parallel()
+
+	s := test.MockListItemsStreamer(func(s *grpcMock.ServerStream) {
+		s.On("SendMsg", &grpctest.Item{Id: 41}).Once().
+			Return(nil)
+	})(t)
+
+	h := newServerStreamHandler(s)
+
+	h.Send(&grpctest.Item{Id: 41}).
+		WaitFor(time.Hour).
+		SendMany([]*grpctest.Item{{Id: 42}, {Id: 43}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	startTime := time.Now()
+	err := h.handle(ctx)
+	endTime := time.Now()
+
+	assert.Less(t, endTime.Sub(startTime), time.Second)
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
 func TestServerStreamHandler_ReturnError(t *testing.T) {
 	h := newServerStreamHandler(test.MockListItemsStreamer()(t))
 
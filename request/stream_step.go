@@ -1,8 +1,12 @@
 package request
 
 import (
+	"bufio"
 	"context"
+	"encoding/binary"
 	"encoding/json"
+	goErrors "errors"
+	"io"
 	"reflect"
 	"time"
 
@@ -10,6 +14,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/nhatthm/grpcmock/errors"
 	grpcReflect "github.com/nhatthm/grpcmock/reflect"
@@ -66,7 +71,7 @@ func stepSend(expectedType reflect.Type, msg interface{}) streamStepFunc {
 }
 
 func stepSendMany(msgType reflect.Type, msg interface{}) streamStepFunc {
-	return func(_ context.Context, s grpc.ServerStream) error {
+	return func(ctx context.Context, s grpc.ServerStream) error {
 		expectedType := reflect.SliceOf(msgType)
 
 		sendMany := func(v interface{}) error {
@@ -77,6 +82,11 @@ func stepSendMany(msgType reflect.Type, msg interface{}) streamStepFunc {
 			}
 
 			for i := 0; i < valueOf.Len(); i++ {
+				// Stop sending if the client is gone.
+				if err := ctx.Err(); err != nil {
+					return status.FromContextError(err).Err()
+				}
+
 				if err := s.SendMsg(grpcReflect.PtrValue(valueOf.Index(i).Interface())); err != nil {
 					return err
 				}
@@ -114,6 +124,85 @@ func stepSendMany(msgType reflect.Type, msg interface{}) streamStepFunc {
 	}
 }
 
+func stepSendGen(msgType reflect.Type, count int, fn func(i int) interface{}) streamStepFunc {
+	return func(ctx context.Context, s grpc.ServerStream) error {
+		for i := 0; i < count; i++ {
+			// Stop generating if the client is gone.
+			if err := ctx.Err(); err != nil {
+				return status.FromContextError(err).Err()
+			}
+
+			if err := stepSend(msgType, fn(i))(ctx, s); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// stepSendFromReader sends the length-delimited records of the reader, each record is a varint of the length followed by
+// the bytes of the message. The records are read and decoded one by one while sending.
+func stepSendFromReader(msgType reflect.Type, r io.Reader, decode func([]byte) (interface{}, error)) streamStepFunc {
+	if decode == nil {
+		decode = func(data []byte) (interface{}, error) {
+			out := grpcReflect.New(msgType)
+
+			return out, proto.Unmarshal(data, out.(proto.Message)) // nolint: errcheck
+		}
+	}
+
+	return func(ctx context.Context, s grpc.ServerStream) error {
+		br := bufio.NewReader(r)
+
+		for i := 0; ; i++ {
+			// Stop reading if the client is gone.
+			if err := ctx.Err(); err != nil {
+				return status.FromContextError(err).Err()
+			}
+
+			data, err := readDelimited(br)
+			if goErrors.Is(err, io.EOF) {
+				return nil
+			}
+
+			if err != nil {
+				return status.Errorf(codes.Internal, "could not read record #%d: %s", i, err.Error())
+			}
+
+			msg, err := decode(data)
+			if err != nil {
+				return status.Errorf(codes.Internal, "could not decode record #%d: %s", i, err.Error())
+			}
+
+			if err := stepSend(msgType, msg)(ctx, s); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readDelimited reads a length-delimited record. It returns io.EOF only if there is no more record, a trailing
+// incomplete record is reported as io.ErrUnexpectedEOF.
+func readDelimited(r *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, size)
+
+	if _, err := io.ReadFull(r, data); err != nil {
+		if goErrors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	return data, nil
+}
+
 func stepReturnErrorf(code codes.Code, msg string, args ...interface{}) streamStepFunc {
 	return func(context.Context, grpc.ServerStream) error {
 		return status.Errorf(code, msg, args...)
@@ -121,9 +210,13 @@ func stepReturnErrorf(code codes.Code, msg string, args ...interface{}) streamSt
 }
 
 func stepWait(d time.Duration) streamStepFunc {
-	return func(context.Context, grpc.ServerStream) error {
-		time.Sleep(d)
+	return func(ctx context.Context, _ grpc.ServerStream) error {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
 
-		return nil
+		case <-time.After(d):
+			return nil
+		}
 	}
 }
@@ -1,7 +1,10 @@
 package request
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"errors"
 	"testing"
 	"time"
 
@@ -10,8 +13,10 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	grpcMock "github.com/nhatthm/grpcmock/mock/grpc"
+	"github.com/nhatthm/grpcmock/must"
 	"github.com/nhatthm/grpcmock/reflect"
 	"github.com/nhatthm/grpcmock/test/grpctest"
 )
@@ -236,6 +241,208 @@ func TestStepSendMany(t *testing.T) {
 	}
 }
 
+func TestStepSendMany_ContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	// SendMsg is not expected to be called.
+	s := grpcMock.MockServerStream()(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := stepSendMany(reflect.UnwrapType(&grpctest.Item{}), []*grpctest.Item{{Id: 42}}).
+		execute(ctx, s)
+
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func TestStepSendGen(t *testing.T) {
+	t.Parallel()
+
+	msgType := reflect.UnwrapType(grpctest.Item{})
+
+	testCases := []struct {
+		scenario         string
+		mockServerStream grpcMock.ServerStreamMocker
+		gen              func(i int) interface{}
+		expectedError    string
+	}{
+		{
+			scenario:         "wrong type",
+			mockServerStream: grpcMock.NoMockServerStream,
+			gen: func(int) interface{} {
+				return 42
+			},
+			expectedError: `rpc error: code = Internal desc = unsupported data type: got int, want grpctest.Item`,
+		},
+		{
+			scenario: "send error",
+			mockServerStream: grpcMock.MockServerStream(func(s *grpcMock.ServerStream) {
+				s.On("SendMsg", &grpctest.Item{Id: 0}).Once().
+					Return(status.Error(codes.Internal, "send error"))
+			}),
+			gen: func(i int) interface{} {
+				return &grpctest.Item{Id: int32(i)}
+			},
+			expectedError: "rpc error: code = Internal desc = send error",
+		},
+		{
+			scenario: "success",
+			mockServerStream: grpcMock.MockServerStream(func(s *grpcMock.ServerStream) {
+				s.On("SendMsg", &grpctest.Item{Id: 0}).Once().Return(nil)
+				s.On("SendMsg", &grpctest.Item{Id: 1}).Once().Return(nil)
+				s.On("SendMsg", &grpctest.Item{Id: 2}).Once().Return(nil)
+			}),
+			gen: func(i int) interface{} {
+				return &grpctest.Item{Id: int32(i)}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			err := stepSendGen(msgType, 3, tc.gen).
+				execute(context.Background(), tc.mockServerStream(t))
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestStepSendFromReader(t *testing.T) {
+	t.Parallel()
+
+	msgType := reflect.UnwrapType(grpctest.Item{})
+
+	item := func(id int32) interface{} {
+		return mock.MatchedBy(func(v interface{}) bool {
+			return proto.Equal(&grpctest.Item{Id: id}, v.(proto.Message)) // nolint: errcheck
+		})
+	}
+
+	records := delimitedRecords(&grpctest.Item{Id: 1}, &grpctest.Item{Id: 2})
+
+	testCases := []struct {
+		scenario         string
+		mockServerStream grpcMock.ServerStreamMocker
+		data             []byte
+		decode           func([]byte) (interface{}, error)
+		expectedError    string
+	}{
+		{
+			scenario:         "empty",
+			mockServerStream: grpcMock.NoMockServerStream,
+		},
+		{
+			scenario: "success",
+			mockServerStream: grpcMock.MockServerStream(func(s *grpcMock.ServerStream) {
+				s.On("SendMsg", item(1)).Once().Return(nil)
+				s.On("SendMsg", item(2)).Once().Return(nil)
+			}),
+			data: records,
+		},
+		{
+			scenario: "custom decode",
+			mockServerStream: grpcMock.MockServerStream(func(s *grpcMock.ServerStream) {
+				s.On("SendMsg", item(1)).Once().Return(nil)
+			}),
+			data: delimited([]byte(`{"id": 1}`)),
+			decode: func(data []byte) (interface{}, error) {
+				return data, nil
+			},
+		},
+		{
+			scenario:         "decode error",
+			mockServerStream: grpcMock.NoMockServerStream,
+			data:             records,
+			decode: func([]byte) (interface{}, error) {
+				return nil, errors.New("decode error")
+			},
+			expectedError: "rpc error: code = Internal desc = could not decode record #0: decode error",
+		},
+		{
+			scenario: "incomplete record",
+			mockServerStream: grpcMock.MockServerStream(func(s *grpcMock.ServerStream) {
+				s.On("SendMsg", item(1)).Once().Return(nil)
+			}),
+			data:          records[:len(records)-1],
+			expectedError: "rpc error: code = Internal desc = could not read record #1: unexpected EOF",
+		},
+		{
+			scenario:         "incomplete length",
+			mockServerStream: grpcMock.NoMockServerStream,
+			data:             []byte{0x80},
+			expectedError:    "rpc error: code = Internal desc = could not read record #0: unexpected EOF",
+		},
+		{
+			scenario:         "missing body",
+			mockServerStream: grpcMock.NoMockServerStream,
+			data:             []byte{0x02},
+			expectedError:    "rpc error: code = Internal desc = could not read record #0: unexpected EOF",
+		},
+		{
+			scenario: "send error",
+			mockServerStream: grpcMock.MockServerStream(func(s *grpcMock.ServerStream) {
+				s.On("SendMsg", item(1)).Once().
+					Return(status.Error(codes.Internal, "send error"))
+			}),
+			data:          records,
+			expectedError: "rpc error: code = Internal desc = send error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			err := stepSendFromReader(msgType, bytes.NewReader(tc.data), tc.decode).
+				execute(context.Background(), tc.mockServerStream(t))
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func delimited(records ...[]byte) []byte {
+	var buf bytes.Buffer
+
+	size := make([]byte, binary.MaxVarintLen64)
+
+	for _, r := range records {
+		n := binary.PutUvarint(size, uint64(len(r)))
+
+		buf.Write(size[:n])
+		buf.Write(r)
+	}
+
+	return buf.Bytes()
+}
+
+func delimitedRecords(msgs ...proto.Message) []byte {
+	records := make([][]byte, 0, len(msgs))
+
+	for _, msg := range msgs {
+		data, err := proto.Marshal(msg)
+		must.NotFail(err)
+
+		records = append(records, data)
+	}
+
+	return delimited(records...)
+}
+
 func TestStepReturnErrorf(t *testing.T) {
 	t.Parallel()
 
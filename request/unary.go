@@ -10,8 +10,12 @@ import (
 
 	"github.com/nhatthm/go-matcher"
 	"github.com/spf13/afero"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 
 	grpcErrors "github.com/nhatthm/grpcmock/errors"
 	grpcMatcher "github.com/nhatthm/grpcmock/matcher"
@@ -31,6 +35,10 @@ type UnaryRequest struct {
 
 	waitTime time.Duration
 
+	// maxHandlerTime is how long the handler could run before the call fails with codes.DeadlineExceeded, zero means
+	// there is no limit.
+	maxHandlerTime time.Duration
+
 	// Request handler.
 	run func(ctx context.Context, in interface{}) (interface{}, error)
 
@@ -39,6 +47,13 @@ type UnaryRequest struct {
 	// requestPayload is the expected parameters of the given request.
 	requestPayload *grpcMatcher.PayloadMatcher
 
+	// responseHeader is the header that is sent to the client.
+	responseHeader metadata.MD
+	// responseTrailer is the trailer that is sent to the client.
+	responseTrailer metadata.MD
+	// trailersOnly is true when the response has no header, see ReturnTrailersOnly().
+	trailersOnly bool
+
 	// statusCode is the response code when the request is handled.
 	statusCode codes.Code
 	// statusMessage is the error message in case of failure.
@@ -90,6 +105,18 @@ func (r *UnaryRequest) WithHeaders(headers map[string]interface{}) *UnaryRequest
 	return r
 }
 
+// WithHeaderMatcher sets the expected headers of the given request using a matcher.HeaderMatcher.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	WithHeaderMatcher(matcher.Authority("example.com"))
+func (r *UnaryRequest) WithHeaderMatcher(m grpcMatcher.HeaderMatcher) *UnaryRequest {
+	for header, value := range m {
+		r.WithHeader(header, value)
+	}
+
+	return r
+}
+
 // WithPayload sets the expected payload of the given request. It could be []byte, string, or a matcher.Matcher.
 //
 // 	Server.ExpectUnary("grpctest.Service/GetItem").
@@ -127,6 +154,42 @@ func (r *UnaryRequest) WithPayloadf(format string, args ...interface{}) *UnaryRe
 	return r.WithPayload(fmt.Sprintf(format, args...))
 }
 
+// WithReturnHeader adds a header to the response. A key could be added multiple times to send multiple values.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	WithReturnHeader("x-request-id", "42").
+//    	Return(&grpctest.Item{Id: 42})
+func (r *UnaryRequest) WithReturnHeader(key, value string) *UnaryRequest {
+	r.lock()
+	defer r.unlock()
+
+	if r.responseHeader == nil {
+		r.responseHeader = metadata.MD{}
+	}
+
+	r.responseHeader.Append(key, value)
+
+	return r
+}
+
+// WithReturnTrailer adds a trailer to the response. A key could be added multiple times to send multiple values.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	WithReturnTrailer("x-cache", "hit").
+//    	Return(&grpctest.Item{Id: 42})
+func (r *UnaryRequest) WithReturnTrailer(key, value string) *UnaryRequest {
+	r.lock()
+	defer r.unlock()
+
+	if r.responseTrailer == nil {
+		r.responseTrailer = metadata.MD{}
+	}
+
+	r.responseTrailer.Append(key, value)
+
+	return r
+}
+
 // ReturnCode sets the response code.
 //
 //    Server.ExpectUnary("grpctest.Service/GetItem").
@@ -175,15 +238,111 @@ func (r *UnaryRequest) ReturnErrorf(code codes.Code, format string, args ...inte
 	r.ReturnCode(code)
 }
 
-// Return sets the result to return to client.
+// ReturnStatus sets the response status, the details of the status are sent to the client as well.
+//
+//    st, _ := status.New(codes.InvalidArgument, "invalid item").
+//    	WithDetails(&errdetails.BadRequest{})
+//
+//    Server.ExpectUnary("grpctest.Service/CreateItem").
+//    	ReturnStatus(st)
+//
+// See: UnaryRequest.ReturnErr().
+func (r *UnaryRequest) ReturnStatus(s *status.Status) {
+	r.ReturnErr(s.Err())
+}
+
+// ReturnErr sets the response error. If the error carries a status, for example the errors of status.Error(), it is
+// sent to the client unchanged. Otherwise, the client receives codes.Internal with the error message.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	ReturnErr(service.ErrItemNotFound)
+//
+// See: UnaryRequest.ReturnStatus().
+func (r *UnaryRequest) ReturnErr(err error) {
+	r.ReturnCode(codes.OK)
+	r.Run(func(context.Context, interface{}) (interface{}, error) {
+		return nil, err
+	})
+}
+
+// ReturnTrailersOnly responds with a trailers-only response, the status and the trailers are sent in a single frame
+// without any header. The code must not be codes.OK, otherwise ReturnTrailersOnly panics. The headers that are set by
+// WithReturnHeader() are not sent.
+//
+// There is no api for it in grpc-go, but the server sends a trailers-only response when a handler fails before sending
+// any header or message, and there is no header to send. So the handler only sets the trailers and returns the error.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	ReturnTrailersOnly(codes.Unavailable, "server is not ready", metadata.Pairs("retry-after", "1"))
+//
+// See: UnaryRequest.WithReturnTrailer().
+func (r *UnaryRequest) ReturnTrailersOnly(code codes.Code, msg string, trailers metadata.MD) {
+	if code == codes.OK {
+		panic(fmt.Errorf("invalid trailers-only response: code must not be %s", code)) // nolint: goerr113
+	}
+
+	r.lock()
+
+	r.trailersOnly = true
+
+	if r.responseTrailer == nil {
+		r.responseTrailer = metadata.MD{}
+	}
+
+	for k, v := range trailers {
+		r.responseTrailer.Append(k, v...)
+	}
+
+	r.unlock()
+
+	r.ReturnError(code, msg)
+}
+
+// ReturnConnectionError resets the connection of the request instead of responding, so the client sees a transport
+// failure with codes.Unavailable instead of a clean status.
+//
+// The failure is simulated in-process by closing the connection of the request, so all the in-flight calls on the same
+// connection fail, not only the expected one. The connections of a bufconn or a unix socket listener could not be told
+// apart, so all of them are closed. The client could reconnect for the next call.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	ReturnConnectionError()
+//
+// See: UnaryRequest.ReturnErr().
+func (r *UnaryRequest) ReturnConnectionError() {
+	r.ReturnErr(grpcErrors.ErrConnectionReset)
+}
+
+// Return sets the result to return to client. It could be []byte, string, or a value of the output type of the method.
+// If the value is of another type, Return returns errors.ErrResponseTypeMismatch and the call fails with the same error.
 //
 //    Server.ExpectUnary("grpctest.Service/GetItem").
 //    	Return(`{"message": "hello world!"}`)
-func (r *UnaryRequest) Return(v interface{}) {
+func (r *UnaryRequest) Return(v interface{}) error {
+	if err := checkResponseType(r.serviceDesc.Output, v); err != nil {
+		r.ReturnErr(err)
+
+		return err
+	}
+
 	r.ReturnCode(codes.OK)
 	r.Run(func(context.Context, interface{}) (interface{}, error) {
 		return v, nil
 	})
+
+	return nil
+}
+
+// ReturnDefault returns an empty message of the output type of the method with an OK status, which is handy for the
+// methods that only acknowledge the request. Without a Return, the request fails with codes.Unimplemented.
+//
+//    Server.ExpectUnary("grpctest.Service/DeleteItem").
+//    	ReturnDefault()
+func (r *UnaryRequest) ReturnDefault() {
+	r.ReturnCode(codes.OK)
+	r.Run(func(context.Context, interface{}) (interface{}, error) {
+		return reflect.New(r.serviceDesc.Output), nil
+	})
 }
 
 // Returnf formats according to a format specifier and use it as the result to return to client.
@@ -191,20 +350,105 @@ func (r *UnaryRequest) Return(v interface{}) {
 //    Server.ExpectUnary("grpctest.Service/GetItem").
 //    	Returnf(`{"message": %q}`, "hello")
 func (r *UnaryRequest) Returnf(format string, args ...interface{}) {
-	r.Return(fmt.Sprintf(format, args...))
+	r.Return(fmt.Sprintf(format, args...)) // nolint: errcheck
 }
 
 // ReturnJSON marshals the object using json.Marshal and uses it as the result to return to client.
 //
 //    Server.ExpectUnary("grpctest.Service/GetItem").
 //    	ReturnJSON(map[string]string{"foo": "bar"})
+//
+// If the object is a string, it is decoded into the output type of the method using protojson right away, and ReturnJSON
+// panics if the JSON is invalid.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	ReturnJSON(`{"id": 42, "createTime": "2020-01-02T03:04:05Z"}`)
 func (r *UnaryRequest) ReturnJSON(v interface{}) {
+	if s, ok := v.(string); ok {
+		out := newProtoOutputFromJSON(r.serviceDesc.Output, s)
+
+		r.ReturnCode(codes.OK)
+		r.Run(func(context.Context, interface{}) (interface{}, error) {
+			return proto.Clone(out), nil
+		})
+
+		return
+	}
+
 	r.ReturnCode(codes.OK)
 	r.Run(func(context.Context, interface{}) (interface{}, error) {
 		return json.Marshal(v)
 	})
 }
 
+// ReturnSequence sets the results to return to client on successive calls, the first call gets outs[0], the second call
+// gets outs[1], and so on. Once the sequence is exhausted, the last result is returned for the rest of the calls.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	UnlimitedTimes().
+//    	ReturnSequence(
+//    		&grpctest.Item{Id: 42, Name: "Foo"},
+//    		&grpctest.Item{Id: 42, Name: "Bar"},
+//    	)
+//
+// It returns errors.ErrResponseTypeMismatch if one of the results is not of the output type of the method, the calls fail
+// with the same error.
+//
+// See: ReturnSequenceStrict().
+func (r *UnaryRequest) ReturnSequence(outs ...interface{}) error {
+	return r.returnSequence(false, outs...)
+}
+
+// ReturnSequenceStrict is the same as ReturnSequence() but once the sequence is exhausted, the rest of the calls get an
+// error with codes.Internal.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	Times(2).
+//    	ReturnSequenceStrict(
+//    		&grpctest.Item{Id: 42, Name: "Foo"},
+//    		&grpctest.Item{Id: 42, Name: "Bar"},
+//    	)
+//
+// See: ReturnSequence().
+func (r *UnaryRequest) ReturnSequenceStrict(outs ...interface{}) error {
+	return r.returnSequence(true, outs...)
+}
+
+func (r *UnaryRequest) returnSequence(strict bool, outs ...interface{}) error {
+	for _, out := range outs {
+		if err := checkResponseType(r.serviceDesc.Output, out); err != nil {
+			r.ReturnErr(err)
+
+			return err
+		}
+	}
+
+	var (
+		mu   sync.Mutex
+		next int
+	)
+
+	r.ReturnCode(codes.OK)
+	r.Run(func(context.Context, interface{}) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if next >= len(outs) {
+			if strict || len(outs) == 0 {
+				return nil, grpcErrors.ErrSequenceExhausted
+			}
+
+			return outs[len(outs)-1], nil
+		}
+
+		next++
+
+		return outs[next-1], nil
+	})
+
+	return nil
+}
+
 // ReturnFile reads the file and uses its content as the result to return to client.
 //
 //    Server.ExpectUnary("grpctest.Service/GetItem").
@@ -221,6 +465,32 @@ func (r *UnaryRequest) ReturnFile(filePath string) {
 	})
 }
 
+// ReturnFunc sets a function that computes the result from the request. The result must be of the output type of the
+// method. The context is the server-side context of the call, so the values set by the interceptors are available and
+// the request header could be read by using metadata.FromIncomingContext().
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//		ReturnFunc(func(ctx context.Context, in interface{}) (interface{}, error) {
+//			req := in.(*grpctest.GetItemRequest)
+//
+//			return &grpctest.Item{Id: req.Id}, nil
+//		})
+func (r *UnaryRequest) ReturnFunc(fn func(ctx context.Context, in interface{}) (interface{}, error)) {
+	r.ReturnCode(codes.OK)
+	r.Run(func(ctx context.Context, in interface{}) (interface{}, error) {
+		out, err := fn(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+
+		if out == nil || reflect.UnwrapType(out) != reflect.UnwrapType(r.serviceDesc.Output) {
+			return nil, status.Errorf(codes.Internal, "invalid response type, got %T, want %T", out, r.serviceDesc.Output)
+		}
+
+		return out, nil
+	})
+}
+
 // Run sets a custom handler to handle the given request.
 //
 //    Server.ExpectUnary("grpctest.Service/GetItem").
@@ -236,20 +506,19 @@ func (r *UnaryRequest) Run(handler func(ctx context.Context, in interface{}) (in
 
 // handle executes the GRPC request.
 func (r *UnaryRequest) handle(ctx context.Context, in interface{}, out interface{}) error {
-	// Block if specified.
-	if r.waitFor != nil {
-		<-r.waitFor
-	} else {
-		time.Sleep(r.waitTime)
-	}
+	var (
+		resp interface{}
+		err  error
+	)
 
-	if r.statusCode != codes.OK {
-		return status.Error(r.statusCode, r.statusMessage)
+	if r.maxHandlerTime > 0 {
+		resp, err = r.respondWithin(ctx, in, r.maxHandlerTime)
+	} else {
+		resp, err = r.respond(ctx, in)
 	}
 
-	resp, err := r.run(ctx, in)
 	if err != nil {
-		return grpcErrors.StatusError(err)
+		return err
 	}
 
 	if reflect.UnwrapType(out) == reflect.UnwrapType(resp) {
@@ -270,6 +539,147 @@ func (r *UnaryRequest) handle(ctx context.Context, in interface{}, out interface
 	return status.Errorf(codes.Internal, "invalid response type, got %T, want %T", resp, out)
 }
 
+// respond waits if specified, and then runs the handler.
+func (r *UnaryRequest) respond(ctx context.Context, in interface{}) (interface{}, error) {
+	if err := r.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := r.sendMetadata(ctx); err != nil {
+		return nil, err
+	}
+
+	if r.statusCode != codes.OK {
+		return nil, status.Error(r.statusCode, r.statusMessage)
+	}
+
+	resp, err := r.run(ctx, in)
+	if err != nil {
+		return nil, grpcErrors.StatusError(err)
+	}
+
+	return resp, nil
+}
+
+// respondWithin runs respond() and gives up with codes.DeadlineExceeded if it does not finish in time. The handler keeps
+// running in the background, but its context is cancelled and its result is discarded.
+func (r *UnaryRequest) respondWithin(ctx context.Context, in interface{}, d time.Duration) (interface{}, error) {
+	handlerCtx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	type result struct {
+		resp      interface{}
+		err       error
+		recovered interface{}
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		// Pass the panic to the caller, a panic in this goroutine would crash the test.
+		defer func() {
+			if p := recover(); p != nil {
+				done <- result{recovered: p}
+			}
+		}()
+
+		resp, err := r.respond(handlerCtx, in)
+
+		done <- result{resp: resp, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.recovered != nil {
+			panic(res.recovered)
+		}
+
+		return res.resp, res.err
+
+	case <-handlerCtx.Done():
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+
+		return nil, status.Errorf(codes.DeadlineExceeded, "handler did not finish within %s", d)
+	}
+}
+
+// wait blocks until the channel set by WaitUntil() receives a message, or the duration set by After() elapses, or the
+// context is done.
+func (r *UnaryRequest) wait(ctx context.Context) error {
+	waitFor := r.waitFor
+
+	if waitFor == nil {
+		if r.waitTime <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(r.waitTime)
+		defer timer.Stop()
+
+		waitFor = timer.C
+	}
+
+	select {
+	case <-waitFor:
+		return nil
+
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// sendMetadata sends the header and the trailer that are set by WithReturnHeader() and WithReturnTrailer().
+func (r *UnaryRequest) sendMetadata(ctx context.Context) error {
+	if len(r.responseHeader) > 0 && !r.trailersOnly {
+		if err := grpc.SetHeader(ctx, r.responseHeader); err != nil {
+			return status.Errorf(codes.Internal, "could not set header: %s", err.Error())
+		}
+	}
+
+	if len(r.responseTrailer) > 0 {
+		if err := grpc.SetTrailer(ctx, r.responseTrailer); err != nil {
+			return status.Errorf(codes.Internal, "could not set trailer: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+// checkResponseType checks whether the response could be sent as the output of the method.
+func checkResponseType(outputType interface{}, v interface{}) error {
+	if outputType == nil || v == nil {
+		return nil
+	}
+
+	if _, ok := v.(proto.Message); !ok {
+		switch v.(type) {
+		case []byte, string, fmt.Stringer:
+			return nil
+		}
+	}
+
+	if reflect.UnwrapType(v) != reflect.UnwrapType(outputType) {
+		return fmt.Errorf("%w: got %T, want %T", grpcErrors.ErrResponseTypeMismatch, v, outputType)
+	}
+
+	return nil
+}
+
+// newProtoOutputFromJSON decodes the JSON into a new message of the output type, it panics if the output type is not a
+// proto.Message or the JSON is invalid.
+func newProtoOutputFromJSON(outputType interface{}, s string) proto.Message {
+	out, ok := reflect.New(outputType).(proto.Message)
+	if !ok {
+		panic(fmt.Errorf("%w: got %T, want proto.Message", grpcErrors.ErrUnsupportedDataType, outputType))
+	}
+
+	must.NotFail(protojson.Unmarshal([]byte(s), out))
+
+	return out
+}
+
 // Once indicates that the mock should only return the value once.
 //
 //    Server.ExpectUnary("grpctest.Service/GetItem").
@@ -341,6 +751,23 @@ func (r *UnaryRequest) After(d time.Duration) *UnaryRequest {
 	return r
 }
 
+// WithMaxHandlerTime sets how long the server works on the request before giving up, including the delay of After() or
+// WaitUntil() and the handler itself. If the handler does not finish in time, the call fails with
+// codes.DeadlineExceeded, regardless of the deadline of the client.
+//
+//    Server.ExpectUnary("grpctest.Service/GetItem").
+//    	WithMaxHandlerTime(100 * time.Millisecond).
+//    	After(time.Second).
+//    	Return(&grpctest.Item{Id: 42})
+func (r *UnaryRequest) WithMaxHandlerTime(d time.Duration) *UnaryRequest {
+	r.lock()
+	defer r.unlock()
+
+	r.maxHandlerTime = d
+
+	return r
+}
+
 func (r *UnaryRequest) headerMatcher() grpcMatcher.HeaderMatcher {
 	return r.requestHeader
 }
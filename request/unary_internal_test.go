@@ -11,8 +11,12 @@ import (
 	"github.com/nhatthm/go-matcher"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	grpcAssert "github.com/nhatthm/grpcmock/assert"
+	grpcErrors "github.com/nhatthm/grpcmock/errors"
 	srvMatcher "github.com/nhatthm/grpcmock/matcher"
 	"github.com/nhatthm/grpcmock/test"
 	"github.com/nhatthm/grpcmock/test/grpctest"
@@ -44,6 +48,46 @@ func TestUnaryRequest_WithHeaders(t *testing.T) {
 	assert.Equal(t, srvMatcher.HeaderMatcher{"foo": matcher.Exact("bar"), "john": matcher.Exact("doe")}, r.requestHeader)
 }
 
+func TestUnaryRequest_WithHeaderMatcher(t *testing.T) {
+	t.Parallel()
+
+	r := newGetItemRequest()
+	r.WithHeader("foo", "bar").
+		WithHeaderMatcher(srvMatcher.Authority("example.com"))
+
+	expected := srvMatcher.HeaderMatcher{
+		"foo":        matcher.Exact("bar"),
+		":authority": matcher.Exact("example.com"),
+	}
+
+	assert.Equal(t, expected, r.requestHeader)
+}
+
+func TestUnaryRequest_WithReturnHeader(t *testing.T) {
+	t.Parallel()
+
+	r := newGetItemRequest()
+	r.WithReturnHeader("foo", "bar").
+		WithReturnHeader("foo", "baz").
+		WithReturnTrailer("john", "doe")
+
+	assert.Equal(t, metadata.MD{"foo": {"bar", "baz"}}, r.responseHeader)
+	assert.Equal(t, metadata.MD{"john": {"doe"}}, r.responseTrailer)
+}
+
+func TestUnaryRequest_WithReturnHeader_NoStream(t *testing.T) {
+	t.Parallel()
+
+	r := newGetItemRequest()
+	r.WithReturnHeader("foo", "bar").
+		Return(&grpctest.Item{Id: 42})
+
+	err := r.handle(context.Background(), &grpctest.GetItemRequest{Id: 42}, &grpctest.Item{})
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, err.Error(), "could not set header")
+}
+
 func TestUnaryRequest_WithPayload_Panic(t *testing.T) {
 	t.Parallel()
 
@@ -431,18 +475,21 @@ func TestUnaryRequest_Return(t *testing.T) {
 		scenario       string
 		output         interface{}
 		expectedResult *grpctest.Item
+		expectedReturn string
 		expectedError  error
 	}{
 		{
 			scenario:       "integer",
 			output:         42,
-			expectedError:  status.Error(codes.Internal, `invalid response type, got int, want *grpctest.Item`),
+			expectedReturn: `response type mismatch: got int, want *grpctest.Item`,
+			expectedError:  status.Error(codes.Internal, `response type mismatch: got int, want *grpctest.Item`),
 			expectedResult: &grpctest.Item{},
 		},
 		{
 			scenario:       "map",
 			output:         map[string]string{},
-			expectedError:  status.Error(codes.Internal, `invalid response type, got map[string]string, want *grpctest.Item`),
+			expectedReturn: `response type mismatch: got map[string]string, want *grpctest.Item`,
+			expectedError:  status.Error(codes.Internal, `response type mismatch: got map[string]string, want *grpctest.Item`),
 			expectedResult: &grpctest.Item{},
 		},
 		{
@@ -480,7 +527,12 @@ func TestUnaryRequest_Return(t *testing.T) {
 			out := &grpctest.Item{}
 
 			r := newGetItemRequest()
-			r.Return(tc.output)
+
+			if tc.expectedReturn == "" {
+				assert.NoError(t, r.Return(tc.output))
+			} else {
+				assert.EqualError(t, r.Return(tc.output), tc.expectedReturn)
+			}
 
 			err := r.handle(context.Background(), nil, out)
 
@@ -490,6 +542,54 @@ func TestUnaryRequest_Return(t *testing.T) {
 	}
 }
 
+func TestUnaryRequest_Return_TypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	const expected = `response type mismatch: got *grpctest.GetItemRequest, want *grpctest.Item`
+
+	testCases := []struct {
+		scenario string
+		doReturn func(r *UnaryRequest) error
+	}{
+		{
+			scenario: "return",
+			doReturn: func(r *UnaryRequest) error {
+				return r.Return(&grpctest.GetItemRequest{Id: 42})
+			},
+		},
+		{
+			scenario: "return sequence",
+			doReturn: func(r *UnaryRequest) error {
+				return r.ReturnSequence(&grpctest.Item{}, &grpctest.GetItemRequest{Id: 42})
+			},
+		},
+		{
+			scenario: "return sequence strict",
+			doReturn: func(r *UnaryRequest) error {
+				return r.ReturnSequenceStrict(&grpctest.Item{}, &grpctest.GetItemRequest{Id: 42})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			r := newGetItemRequest()
+			err := tc.doReturn(r)
+
+			assert.ErrorIs(t, err, grpcErrors.ErrResponseTypeMismatch)
+			assert.EqualError(t, err, expected)
+
+			// The call fails with the same error.
+			err = r.handle(context.Background(), nil, &grpctest.Item{})
+
+			assert.Equal(t, status.Error(codes.Internal, expected), err)
+		})
+	}
+}
+
 func TestUnaryRequest_ReturnStatusError(t *testing.T) {
 	t.Parallel()
 
@@ -553,6 +653,16 @@ func TestUnaryRequest_ReturnFile_NotFound(t *testing.T) {
 	})
 }
 
+func TestUnaryRequest_ReturnTrailersOnly_OK(t *testing.T) {
+	t.Parallel()
+
+	r := newGetItemRequest()
+
+	assert.PanicsWithError(t, "invalid trailers-only response: code must not be OK", func() {
+		r.ReturnTrailersOnly(codes.OK, "", nil)
+	})
+}
+
 func TestUnaryRequest_Returnf(t *testing.T) {
 	t.Parallel()
 
@@ -587,6 +697,39 @@ func TestUnaryRequest_ReturnJSON(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestUnaryRequest_ReturnJSON_ProtoJSON(t *testing.T) {
+	t.Parallel()
+
+	r := newGetItemRequest()
+
+	r.ReturnJSON(`{"id": 42, "name": "Foobar", "createTime": "2020-01-02T03:04:05Z"}`)
+
+	expected := &grpctest.Item{
+		Id:         42,
+		Name:       "Foobar",
+		CreateTime: timestamppb.New(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+	}
+
+	for i := 0; i < 2; i++ {
+		output := &grpctest.Item{}
+
+		err := r.handle(context.Background(), nil, output)
+
+		assert.NoError(t, err)
+		grpcAssert.EqualMessage(t, expected, output)
+	}
+}
+
+func TestUnaryRequest_ReturnJSON_InvalidProtoJSON(t *testing.T) {
+	t.Parallel()
+
+	r := newGetItemRequest()
+
+	assert.Panics(t, func() {
+		r.ReturnJSON(`{"unknown": 42}`)
+	})
+}
+
 func TestUnaryRequest_Run(t *testing.T) {
 	t.Parallel()
 
@@ -605,6 +748,65 @@ func TestUnaryRequest_Run(t *testing.T) {
 	assert.Equal(t, expectedError, err)
 }
 
+func TestUnaryRequest_ReturnFunc(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario       string
+		returnFunc     func(ctx context.Context, in interface{}) (interface{}, error)
+		expectedResult *grpctest.Item
+		expectedError  error
+	}{
+		{
+			scenario: "error",
+			returnFunc: func(context.Context, interface{}) (interface{}, error) {
+				return nil, status.Error(codes.NotFound, "item not found")
+			},
+			expectedResult: &grpctest.Item{},
+			expectedError:  status.Error(codes.NotFound, "item not found"),
+		},
+		{
+			scenario: "nil result",
+			returnFunc: func(context.Context, interface{}) (interface{}, error) {
+				return nil, nil
+			},
+			expectedResult: &grpctest.Item{},
+			expectedError:  status.Error(codes.Internal, "invalid response type, got <nil>, want *grpctest.Item"),
+		},
+		{
+			scenario: "wrong type",
+			returnFunc: func(context.Context, interface{}) (interface{}, error) {
+				return &grpctest.GetItemRequest{}, nil
+			},
+			expectedResult: &grpctest.Item{},
+			expectedError:  status.Error(codes.Internal, "invalid response type, got *grpctest.GetItemRequest, want *grpctest.Item"),
+		},
+		{
+			scenario: "success",
+			returnFunc: func(_ context.Context, in interface{}) (interface{}, error) {
+				return &grpctest.Item{Id: in.(*grpctest.GetItemRequest).Id}, nil // nolint: errcheck
+			},
+			expectedResult: &grpctest.Item{Id: 42},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			r := newGetItemRequest()
+			r.ReturnFunc(tc.returnFunc)
+
+			out := &grpctest.Item{}
+			err := r.handle(context.Background(), &grpctest.GetItemRequest{Id: 42}, out)
+
+			grpcAssert.EqualMessage(t, tc.expectedResult, out)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
 func TestUnaryRequest_Once(t *testing.T) {
 	t.Parallel()
 
@@ -676,6 +878,103 @@ func TestUnaryRequest_WaitTime(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestUnaryRequest_WaitTime_ContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	r := newGetItemRequest()
+	r.After(time.Minute).Return(&grpctest.Item{Id: 42})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := r.handle(ctx, nil, &grpctest.Item{})
+
+	assert.Equal(t, status.Error(codes.DeadlineExceeded, context.DeadlineExceeded.Error()), err)
+}
+
+func TestUnaryRequest_WithMaxHandlerTime(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		mockRequest   func(r *UnaryRequest)
+		expectedError error
+	}{
+		{
+			scenario: "finished in time",
+			mockRequest: func(r *UnaryRequest) {
+				r.Return(&grpctest.Item{Id: 42})
+			},
+		},
+		{
+			scenario: "after exceeds max handler time",
+			mockRequest: func(r *UnaryRequest) {
+				r.After(time.Minute).Return(&grpctest.Item{Id: 42})
+			},
+			expectedError: status.Error(codes.DeadlineExceeded, "handler did not finish within 50ms"),
+		},
+		{
+			scenario: "wait until exceeds max handler time",
+			mockRequest: func(r *UnaryRequest) {
+				r.WaitUntil(make(chan time.Time)).Return(&grpctest.Item{Id: 42})
+			},
+			expectedError: status.Error(codes.DeadlineExceeded, "handler did not finish within 50ms"),
+		},
+		{
+			scenario: "handler exceeds max handler time",
+			mockRequest: func(r *UnaryRequest) {
+				r.ReturnFunc(func(ctx context.Context, _ interface{}) (interface{}, error) {
+					<-ctx.Done()
+
+					return nil, ctx.Err()
+				})
+			},
+			expectedError: status.Error(codes.DeadlineExceeded, "handler did not finish within 50ms"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			r := newGetItemRequest()
+			r.WithMaxHandlerTime(50 * time.Millisecond)
+
+			tc.mockRequest(r)
+
+			out := &grpctest.Item{}
+			err := r.handle(context.Background(), &grpctest.GetItemRequest{Id: 42}, out)
+
+			assert.Equal(t, tc.expectedError, err)
+
+			if tc.expectedError == nil {
+				assert.Equal(t, int32(42), out.Id)
+			}
+		})
+	}
+}
+
+func TestUnaryRequest_WithMaxHandlerTime_ContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	r := newGetItemRequest()
+	r.WithMaxHandlerTime(time.Minute).
+		After(time.Minute).
+		Return(&grpctest.Item{Id: 42})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	err := r.handle(ctx, nil, &grpctest.Item{})
+
+	assert.Equal(t, status.Error(codes.Canceled, context.Canceled.Error()), err)
+}
+
 func TestUnaryRequest_ServiceMethod(t *testing.T) {
 	t.Parallel()
 
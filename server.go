@@ -14,9 +14,17 @@ import (
 	grpcTags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
 
 	grpcErrors "github.com/nhatthm/grpcmock/errors"
 	"github.com/nhatthm/grpcmock/format"
+	"github.com/nhatthm/grpcmock/internal/rawpayload"
 	"github.com/nhatthm/grpcmock/must"
 	"github.com/nhatthm/grpcmock/planner"
 	grpcReflect "github.com/nhatthm/grpcmock/reflect"
@@ -25,6 +33,11 @@ import (
 	"github.com/nhatthm/grpcmock/streamer"
 )
 
+const (
+	defaultShutdownTimeout = 30 * time.Second
+	defaultBufConnSize     = 1024 * 1024
+)
+
 // Server wraps a grpc server and provides mocking functionalities.
 type Server struct {
 	// test is An optional variable that holds the test struct, to be used when an
@@ -39,11 +52,44 @@ type Server struct {
 
 	serverOpts []grpc.ServerOption
 	services   map[string]*service.Method
+	reflection bool
+	// rawBytes is true when the requests are decoded by the raw bytes codec, see WithRawBytesCodec().
+	rawBytes bool
+
+	echoMetadata         bool
+	echoMetadataPrefixes []string
+
+	shutdownTimeout time.Duration
+	bufConnSize     int
+	strictTest      *strictTest
+	panicHandler    func(recovered interface{})
+	validator       func(method string, req interface{}) error
+	logger          Logger
+
+	// callsChanged is closed when a call is recorded.
+	callsChanged chan struct{}
+	// callSequence is the sequence of the last recorded call.
+	callSequence uint64
 
 	mu sync.Mutex
 
 	// Holds the requested that were made to this server.
 	Requests []request.Request
+	// Holds the calls that were received by this server, including the unexpected ones.
+	Calls []RecordedCall
+}
+
+// RecordedCall holds the information of a call received by the server.
+type RecordedCall struct {
+	// Method is the full method name, for example "/grpctest.ItemService/GetItem".
+	Method string
+	// Deadline is the deadline of the call that was propagated by the client, nil if there is no deadline.
+	Deadline *time.Time
+	// Compressor is the compressor of the request, for example "gzip", empty if the request is not compressed.
+	Compressor string
+	// Sequence is the order in which the server received the call, it starts from 1 and increases for every call, so
+	// the calls could be ordered even when they are concurrent.
+	Sequence uint64
 }
 
 // ServerOption sets up the mocked server.
@@ -58,6 +104,38 @@ func NewServer(opts ...ServerOption) *Server {
 	return srv
 }
 
+// NewInProcess starts a new Server on an in-memory bufconn listener and returns the options for invoking the server.
+// The server options could be mixed with the register functions.
+//
+//    srv, opts := grpcmock.NewInProcess(grpctest.RegisterItemServiceServer, grpcmock.WithBufConnSize(8<<20))
+//    defer srv.Close() // nolint: errcheck
+//
+//    err := grpcmock.InvokeUnary(ctx, "grpctest.ItemService/GetItem", in, out, opts...)
+func NewInProcess(registerFns ...interface{}) (*Server, []InvokeOption) {
+	var buf *bufconn.Listener
+
+	opts := make([]ServerOption, 0, len(registerFns)+1)
+
+	for _, fn := range registerFns {
+		if o, ok := fn.(ServerOption); ok {
+			opts = append(opts, o)
+
+			continue
+		}
+
+		opts = append(opts, RegisterService(fn))
+	}
+
+	opts = append(opts, withBufConnListener(&buf))
+
+	return NewServer(opts...), []InvokeOption{
+		WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return buf.Dial()
+		}),
+		WithInsecure(),
+	}
+}
+
 // NewUnstartedServer returns a new Server but doesn't start it.
 func NewUnstartedServer(opts ...ServerOption) *Server {
 	s := Server{
@@ -74,8 +152,10 @@ func NewUnstartedServer(opts ...ServerOption) *Server {
 				grpcTags.StreamServerInterceptor(),
 			),
 		},
-		closeServer: closeNothing,
-		newListener: newListenerByAddr(":0"),
+		closeServer:     closeNothing,
+		newListener:     newListenerByAddr(":0"),
+		bufConnSize:     defaultBufConnSize,
+		shutdownTimeout: defaultShutdownTimeout,
 	}
 
 	for _, o := range opts {
@@ -199,7 +279,8 @@ func (s *Server) ExpectBidirectionalStream(method string) *request.Bidirectional
 }
 
 // ExpectationsWereMet checks whether all queued expectations were met in order.
-// If any of them was not met - an error is returned.
+// If any of them was not met - an error is returned. It is safe to call while the server is handling requests, the
+// expectations are checked against a snapshot that is taken under the lock of the server.
 func (s *Server) ExpectationsWereMet() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -243,7 +324,213 @@ func (s *Server) ExpectationsWereMet() error {
 	return errors.New(sb.String())
 }
 
-// ResetExpectations resets all the expectations.
+// ExpectationSummary describes an expectation that is registered in the server.
+type ExpectationSummary struct {
+	// Method is the full name of the method, for example "/grpctest.ItemService/GetItem".
+	Method string
+	// MethodType is the type of the method.
+	MethodType service.Type
+	// Header describes the expected headers, it is empty if there is no header expectation.
+	Header string
+	// Payload describes the expected payload, it is empty if there is no payload expectation.
+	Payload string
+	// Times is the number of times that the expectation was configured to be called, 0 means unlimited.
+	Times int
+	// Calls is the number of times that the expectation was called so far.
+	Calls int
+}
+
+// Expectations returns the summary of the remaining expectations, in the order of the planner. The expectations that
+// were fully met are removed by the planner and are not in the summary.
+//
+//    for _, e := range srv.Expectations() {
+//    	t.Logf("%s %s (called: %d/%d)", e.Method, e.Payload, e.Calls, e.Times)
+//    }
+func (s *Server) Expectations() []ExpectationSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remain := s.planner.Remain()
+	result := make([]ExpectationSummary, 0, len(remain))
+
+	for _, expected := range remain {
+		svc := request.ServiceMethod(expected)
+		calls := request.NumCalls(expected)
+		times := 0
+
+		if repeat := request.Repeatability(expected); repeat != request.UnlimitedTimes {
+			times = int(repeat) + calls
+		}
+
+		result = append(result, ExpectationSummary{
+			Method:     svc.FullName(),
+			MethodType: svc.MethodType,
+			Header:     request.HeaderMatcher(expected).String(),
+			Payload:    request.PayloadMatcher(expected).String(),
+			Times:      times,
+			Calls:      calls,
+		})
+	}
+
+	return result
+}
+
+// AssertCalled asserts that the method was called at least once.
+//
+//    Server.AssertCalled(t, "grpctest.Service/GetItem")
+func (s *Server) AssertCalled(t T, method string) bool {
+	if calls := s.numCalls(method); calls == 0 {
+		t.Errorf("method %q was expected to be called, but it was not", methodName(method))
+
+		return false
+	}
+
+	return true
+}
+
+// AssertNotCalled asserts that the method was not called.
+//
+//    Server.AssertNotCalled(t, "grpctest.Service/GetItem")
+func (s *Server) AssertNotCalled(t T, method string) bool {
+	if calls := s.numCalls(method); calls > 0 {
+		t.Errorf("method %q was not expected to be called, but it was called %d time(s)", methodName(method), calls)
+
+		return false
+	}
+
+	return true
+}
+
+// AssertNumberOfCalls asserts that the method was called an expected number of times. The unexpected calls are also
+// counted, like in Server.AssertCalled() and Server.AssertNotCalled().
+//
+//    Server.AssertNumberOfCalls(t, "grpctest.Service/GetItem", 2)
+func (s *Server) AssertNumberOfCalls(t T, method string, expectedCalls int) bool {
+	if calls := s.numCalls(method); calls != expectedCalls {
+		t.Errorf("method %q was expected to be called %d time(s), but it was called %d time(s)", methodName(method), expectedCalls, calls)
+
+		return false
+	}
+
+	return true
+}
+
+// AssertCallOrder asserts that the first call of each method was received in the given order. The calls are ordered
+// by RecordedCall.Sequence, including the unexpected ones.
+//
+//    Server.AssertCallOrder(t, "grpctest.Service/CreateItems", "grpctest.Service/ListItems")
+func (s *Server) AssertCallOrder(t T, methods ...string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var prev RecordedCall
+
+	for i, method := range methods {
+		method = methodName(method)
+
+		call, ok := s.firstCall(method)
+		if !ok {
+			t.Errorf("method %q was expected to be called, but it was not", method)
+
+			return false
+		}
+
+		if i > 0 && call.Sequence < prev.Sequence {
+			t.Errorf("method %q was expected to be called before %q, but it was not", prev.Method, method)
+
+			return false
+		}
+
+		prev = call
+	}
+
+	return true
+}
+
+// firstCall finds the first recorded call of the method, the caller must hold the lock.
+func (s *Server) firstCall(method string) (RecordedCall, bool) {
+	for _, c := range s.Calls {
+		if c.Method == method {
+			return c, true
+		}
+	}
+
+	return RecordedCall{}, false
+}
+
+// WaitForCalls blocks until the method has been called at least n times, or the context is done. The calls are counted
+// the same way as in Server.AssertNumberOfCalls().
+//
+//    go func() {
+//    	_ = grpcmock.InvokeUnary(ctx, "grpctest.Service/GetItem", in, out)
+//    }()
+//
+//    err := Server.WaitForCalls(ctx, "grpctest.Service/GetItem", 1)
+func (s *Server) WaitForCalls(ctx context.Context, method string, n int) error {
+	method = methodName(method)
+
+	for {
+		s.mu.Lock()
+		calls := s.countCalls(method)
+
+		if s.callsChanged == nil {
+			s.callsChanged = make(chan struct{})
+		}
+
+		changed := s.callsChanged
+		s.mu.Unlock()
+
+		if calls >= n {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("method %q was expected to be called %d time(s), but it was called %d time(s): %w", method, n, calls, ctx.Err())
+
+		case <-changed:
+		}
+	}
+}
+
+func (s *Server) numCalls(method string) int {
+	method = methodName(method)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.countCalls(method)
+}
+
+// countCalls counts the recorded calls of the method, including the unexpected ones. The caller must hold the lock.
+func (s *Server) countCalls(method string) int {
+	var calls int
+
+	for _, c := range s.Calls {
+		if c.Method == method {
+			calls++
+		}
+	}
+
+	return calls
+}
+
+// Reset resets all the expectations, the recorded requests and the recorded calls. The server keeps running.
+//
+// See: Server.ResetExpectations(), Server.ResetRecordings().
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Requests = nil
+	s.Calls = nil
+
+	s.planner.Reset()
+}
+
+// ResetExpectations resets all the expectations and the recorded requests.
+//
+// See: Server.Reset(), Server.ResetRecordings().
 func (s *Server) ResetExpectations() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -253,25 +540,68 @@ func (s *Server) ResetExpectations() {
 	s.planner.Reset()
 }
 
-// Address returns server address.
+// ResetRecordings resets all the recorded requests and calls.
+//
+// See: Server.Reset(), Server.ResetExpectations().
+func (s *Server) ResetRecordings() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Requests = nil
+	s.Calls = nil
+}
+
+// Address returns server address. It is "host:port" for a TCP listener, the path of the socket for a unix listener and
+// "bufconn" for a bufconn listener, which must be dialed with WithBufConnDialer().
 func (s *Server) Address() string {
 	return s.listener.Addr().String()
 }
 
+// MethodURL returns the url of a method of a service that could be passed straight to InvokeUnary() and the other
+// Invoke functions.
+//
+//    err := grpcmock.InvokeUnary(ctx, srv.MethodURL("grpctest.ItemService", "GetItem"), in, out, grpcmock.WithInsecure())
+//
+// See: Server.Address().
+func (s *Server) MethodURL(service, method string) string {
+	addr := s.Address()
+
+	if s.listener.Addr().Network() == "unix" {
+		addr = "unix://" + addr
+	}
+
+	return fmt.Sprintf("%s/%s/%s", addr, service, method)
+}
+
 // Serve runs the grpc server.
 func (s *Server) Serve() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	srv, closeServer := buildGRPCServer(s.services, s.handleRequest, s.serverOpts...)
+	srv, closeServer := buildGRPCServer(s.services, s.requestHandler(), s.rawBytes, s.shutdownTimeout, s.serverOpts...)
 	l, closeListener := s.newListener()
 
+	if s.reflection {
+		reflection.Register(srv)
+	}
+
 	var ready <-chan struct{}
 
-	s.closeServer = closeServer
+	done := make(chan struct{})
+
+	s.closeServer = func() error {
+		err := closeServer()
+
+		// Wait for the listener to be closed, even if the server was forced to stop.
+		<-done
+
+		return err
+	}
 	s.listener, ready = newListenerWithReadySignal(l)
 
 	go func(l net.Listener) {
+		defer close(done)
+
 		//goland:noinspection GoUnhandledErrorResult
 		defer closeListener() // nolint: errcheck
 
@@ -285,41 +615,241 @@ func (s *Server) Serve() {
 	}
 }
 
-// Close stops and closes all open connections and listeners.
+// Close stops and closes all open connections and listeners. The server waits for all the in-flight requests to finish,
+// if they do not finish within the shutdown timeout, the server is forced to stop and an error is returned.
+//
+// See: WithShutdownTimeout().
 func (s *Server) Close() error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	closeServer := s.closeServer
+	s.closeServer = closeNothing
+	s.mu.Unlock()
 
-	defer func() {
-		s.closeServer = closeNothing
-	}()
+	return closeServer()
+}
+
+// requestHandler returns the handler of the requests, the requests are logged if a logger is set by
+// WithServerLogger().
+func (s *Server) requestHandler() func(ctx context.Context, svc service.Method, in interface{}, out interface{}) error {
+	if s.logger == nil {
+		return s.handleRequest
+	}
+
+	return func(ctx context.Context, svc service.Method, in interface{}, out interface{}) error {
+		start := time.Now()
+		err := s.handleRequest(ctx, svc, in, out)
 
-	return s.closeServer()
+		s.logger.Log("method", svc.FullName(), "type", string(svc.MethodType), "code", status.Code(err).String(), "duration", time.Since(start))
+
+		return err
+	}
 }
 
 func (s *Server) handleRequest(ctx context.Context, svc service.Method, in interface{}, out interface{}) error {
+	t, expected, err := s.planRequest(ctx, svc, in)
+	if err != nil {
+		return err
+	}
+
+	// The server is not locked while handling the request, so a slow handler does not block the other requests, the
+	// assertions or Close(). The expectations must not be changed while they are being handled.
+	err = s.handle(ctx, expected, in, out)
+
+	if errors.Is(err, grpcErrors.ErrConnectionReset) {
+		s.resetConnection(ctx)
+
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	assert.NoError(t, err)
+
+	return err
+}
+
+// planRequest records the call and finds the expectation of the request. It also returns the test of the server, so it
+// is not read without the lock.
+func (s *Server) planRequest(ctx context.Context, svc service.Method, in interface{}) (T, request.Request, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.callSequence++
+
+	call := newRecordedCall(ctx, svc)
+	call.Sequence = s.callSequence
+
+	s.Calls = append(s.Calls, call)
+
+	if s.callsChanged != nil {
+		close(s.callsChanged)
+		s.callsChanged = nil
+	}
+
+	if s.echoMetadata {
+		echoMetadataAsTrailers(ctx, s.echoMetadataPrefixes)
+	}
+
+	if err := s.validateRequest(svc, in); err != nil {
+		return nil, nil, err
+	}
+
 	if s.planner.IsEmpty() {
-		return planner.UnexpectedRequestError(svc, in)
+		err := planner.UnexpectedRequestError(svc, in)
+
+		s.strictTest.reportUnexpectedCall(ctx, svc, in, err)
+
+		return nil, nil, err
 	}
 
 	expected, err := s.planner.Plan(ctx, svc, in)
-	assert.NoError(s.test, err)
-
 	if err != nil {
-		return grpcErrors.StatusError(err)
+		// The call is reported once, by the strict mode if it is enabled.
+		if s.strictTest != nil {
+			s.strictTest.reportUnexpectedCall(ctx, svc, in, err)
+		} else {
+			assert.NoError(s.test, err)
+		}
+
+		return nil, nil, grpcErrors.StatusError(err)
 	}
 
 	// Log the request.
 	request.CountCall(expected)
 	s.Requests = append(s.Requests, expected)
 
-	err = request.Handle(ctx, expected, in, out)
-	assert.NoError(s.test, err)
+	return s.test, expected, nil
+}
 
-	return err
+// resetConnection closes the connection of the request, so the client sees a transport failure.
+func (s *Server) resetConnection(ctx context.Context) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	l, ok := s.listener.(*listener)
+	s.mu.Unlock()
+
+	if ok {
+		l.closeConns(p.Addr)
+	}
+}
+
+// validateRequest runs the request validator, if any. The messages of a client stream are read and validated one by one,
+// the bidirectional streams are not validated.
+func (s *Server) validateRequest(svc service.Method, in interface{}) error {
+	if s.validator == nil {
+		return nil
+	}
+
+	msgs := []interface{}{in}
+
+	// nolint: exhaustive
+	switch svc.MethodType {
+	case service.TypeClientStream:
+		payload, err := streamer.ClientStreamerPayload(in.(*streamer.ClientStreamer)) // nolint: errcheck
+		if err != nil {
+			return grpcErrors.StatusError(err)
+		}
+
+		msgs = toMessages(payload)
+
+	case service.TypeBidirectionalStream:
+		return nil
+	}
+
+	for _, msg := range msgs {
+		if err := s.validator(svc.FullName(), msg); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// handle runs the handler of the expectation. A panic in the handler fails the call with codes.Internal instead of
+// crashing the test.
+func (s *Server) handle(ctx context.Context, expected request.Request, in interface{}, out interface{}) (err error) {
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+
+		if s.panicHandler != nil {
+			s.panicHandler(p)
+		}
+
+		err = status.Errorf(codes.Internal, "handler panicked: %v", p)
+	}()
+
+	return request.Handle(ctx, expected, in, out)
+}
+
+// incomingCompressor returns the "grpc-encoding" of the request. The header is reserved, so it is read from the
+// transport stream instead of the metadata.
+func incomingCompressor(ctx context.Context) string {
+	if s, ok := grpc.ServerTransportStreamFromContext(ctx).(interface{ RecvCompress() string }); ok {
+		return s.RecvCompress()
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("grpc-encoding"); len(values) > 0 {
+			return values[0]
+		}
+	}
+
+	return ""
+}
+
+func newRecordedCall(ctx context.Context, svc service.Method) RecordedCall {
+	call := RecordedCall{Method: svc.FullName()}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		call.Deadline = &deadline
+	}
+
+	call.Compressor = incomingCompressor(ctx)
+
+	return call
+}
+
+// echoMetadataAsTrailers copies the incoming metadata, which match one of the prefixes, to the trailers. If there is no
+// prefix, all the metadata are copied, except the reserved ones.
+func echoMetadataAsTrailers(ctx context.Context, prefixes []string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return
+	}
+
+	trailer := metadata.MD{}
+
+	for k, v := range md {
+		if isEchoedMetadata(k, prefixes) {
+			trailer[k] = v
+		}
+	}
+
+	if trailer.Len() > 0 {
+		_ = grpc.SetTrailer(ctx, trailer) // nolint: errcheck
+	}
+}
+
+func isEchoedMetadata(key string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return !strings.HasPrefix(key, ":") &&
+			!strings.HasPrefix(key, "grpc-") &&
+			key != "content-type" &&
+			key != "user-agent"
+	}
+
+	for _, p := range prefixes {
+		if strings.HasPrefix(key, strings.ToLower(p)) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (s *Server) registerServiceMethod(svc service.Method) {
@@ -341,19 +871,23 @@ func (s *Server) registerService(id string, svc interface{}) {
 func buildGRPCServer(
 	services map[string]*service.Method,
 	handler func(ctx context.Context, svc service.Method, in interface{}, out interface{}) error,
+	rawBytes bool,
+	shutdownTimeout time.Duration,
 	opts ...grpc.ServerOption,
 ) (*grpc.Server, func() error) {
 	srv := grpc.NewServer(opts...)
 
-	for _, desc := range buildServiceDescriptions(services, handler) {
+	for _, desc := range buildServiceDescriptions(services, handler, rawBytes) {
 		srv.RegisterService(desc, nil)
 	}
 
 	return srv, func() error {
-		return closeGRPCServer(srv, time.Second*30)
+		return closeGRPCServer(srv, shutdownTimeout)
 	}
 }
 
+// closeGRPCServer stops the server gracefully. If the server could not stop within the timeout, it is forced to stop and
+// an error is returned.
 func closeGRPCServer(srv *grpc.Server, timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -368,16 +902,25 @@ func closeGRPCServer(srv *grpc.Server, timeout time.Duration) error {
 
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		srv.Stop()
+
+		// Stop() interrupts GracefulStop().
+		<-signal
+
+		return fmt.Errorf("could not stop server gracefully, forced to stop: %w", ctx.Err())
 
 	case <-signal:
 		return nil
 	}
 }
 
+// buildServiceDescriptions groups the methods by their service, so the methods that share the same name in different
+// services are routed by their full name "/package.Service/Method". If rawBytes is true, the wire bytes of the unary and
+// server-stream requests are kept for matching, see WithRawBytesCodec().
 func buildServiceDescriptions(
 	services map[string]*service.Method,
 	handler func(ctx context.Context, svc service.Method, in interface{}, out interface{}) error,
+	rawBytes bool,
 ) []*grpc.ServiceDesc {
 	result := make([]*grpc.ServiceDesc, 0, len(services))
 	list := make(map[string]*grpc.ServiceDesc, len(services))
@@ -397,14 +940,14 @@ func buildServiceDescriptions(
 
 			desc.Streams = append(desc.Streams, grpc.StreamDesc{
 				StreamName:    svc.MethodName,
-				Handler:       newStreamHandler(*svc, handler),
-				ServerStreams: isClientStream,
-				ClientStreams: isServerStream,
+				Handler:       newStreamHandler(*svc, handler, rawBytes),
+				ServerStreams: isServerStream,
+				ClientStreams: isClientStream,
 			})
 		} else {
 			desc.Methods = append(desc.Methods, grpc.MethodDesc{
 				MethodName: svc.MethodName,
-				Handler:    newUnaryHandler(*svc, handler),
+				Handler:    newUnaryHandler(*svc, handler, rawBytes),
 			})
 		}
 
@@ -423,19 +966,29 @@ func buildServiceDescriptions(
 func newUnaryHandler(
 	svc service.Method,
 	handle func(ctx context.Context, svc service.Method, in interface{}, out interface{}) error,
+	rawBytes bool,
 ) func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	// The types are resolved once, so a call only allocates its messages. The messages are not pooled because the handlers
+	// could keep them after the call.
+	inType, outType := grpcReflect.UnwrapType(svc.Input), grpcReflect.UnwrapType(svc.Output)
+
 	return func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-		in := grpcReflect.New(svc.Input)
+		in := grpcReflect.New(inType)
 
-		if err := dec(in); err != nil {
-			return grpcReflect.NewZero(svc.Output), grpcErrors.StatusError(err)
+		raw, err := decodeRequest(in, dec, rawBytes)
+		if err != nil {
+			return grpcReflect.NewZero(outType), grpcErrors.StatusError(err)
+		}
+
+		if raw != nil {
+			ctx = rawpayload.NewContext(ctx, raw)
 		}
 
 		intercept := func(ctx context.Context, in interface{}) (interface{}, error) {
-			out := grpcReflect.New(svc.Output)
+			out := grpcReflect.New(outType)
 
 			if err := handle(ctx, svc, in, out); err != nil {
-				return grpcReflect.NewZero(svc.Output), err
+				return grpcReflect.NewZero(outType), err
 			}
 
 			return out, nil
@@ -459,34 +1012,64 @@ func newUnaryHandler(
 func newStreamHandler(
 	svc service.Method,
 	handle func(ctx context.Context, svc service.Method, in interface{}, out interface{}) error,
+	rawBytes bool,
 ) func(_ interface{}, s grpc.ServerStream) error {
+	// The types are resolved once, see newUnaryHandler().
+	inType, outType := grpcReflect.UnwrapType(svc.Input), grpcReflect.UnwrapType(svc.Output)
+
 	return func(_ interface{}, s grpc.ServerStream) error {
 		var (
 			in  interface{}
 			out interface{}
+			raw []byte
+			err error
 		)
 
 		// nolint: exhaustive
 		switch svc.MethodType {
 		case service.TypeServerStream:
-			in = grpcReflect.New(svc.Input)
-			if err := s.RecvMsg(in); err != nil {
+			in = grpcReflect.New(inType)
+
+			if raw, err = decodeRequest(in, s.RecvMsg, rawBytes); err != nil {
 				return grpcErrors.StatusError(err)
 			}
 
-			out = streamer.NewServerStreamer(s, grpcReflect.UnwrapType(svc.Output))
+			out = streamer.NewServerStreamer(s, outType)
 
 		case service.TypeClientStream:
-			in = streamer.NewClientStreamer(s, grpcReflect.UnwrapType(svc.Input), grpcReflect.UnwrapType(svc.Output))
-			out = grpcReflect.New(svc.Output)
+			in = streamer.NewClientStreamer(s, inType, outType)
+			out = grpcReflect.New(outType)
 
 		default:
-			in = streamer.NewBidirectionalStreamer(s, grpcReflect.UnwrapType(svc.Input), grpcReflect.UnwrapType(svc.Output))
+			in = streamer.NewBidirectionalStreamer(s, inType, outType)
 			out = in
 		}
 
-		return handle(s.Context(), svc, in, out)
+		ctx := s.Context()
+
+		if raw != nil {
+			ctx = rawpayload.NewContext(ctx, raw)
+		}
+
+		return handle(ctx, svc, in, out)
+	}
+}
+
+// decodeRequest decodes the request. If rawBytes is true, the request is decoded by the raw bytes codec into a
+// *rawpayload.Message and its wire bytes are returned for matching, otherwise the returned bytes are nil.
+func decodeRequest(in interface{}, dec func(interface{}) error, rawBytes bool) ([]byte, error) {
+	msg, ok := in.(proto.Message)
+	if !rawBytes || !ok {
+		return nil, dec(in)
+	}
+
+	raw := &rawpayload.Message{Message: msg}
+
+	if err := dec(raw); err != nil {
+		return nil, err
 	}
+
+	return raw.Raw, nil
 }
 
 // WithPlanner sets the expectations' planner.
@@ -515,7 +1098,7 @@ func WithPlanner(p planner.Planner) ServerOption {
 //    	},
 //    )(t)
 //
-// See: RegisterServiceFromInstance(), RegisterServiceFromMethods().
+// See: RegisterServices(), RegisterServiceFromInstance(), RegisterServiceFromMethods().
 func RegisterService(registerFunc interface{}) ServerOption {
 	return func(s *Server) {
 		serviceDesc, svc := grpcReflect.ParseRegisterFunc(registerFunc)
@@ -524,6 +1107,33 @@ func RegisterService(registerFunc interface{}) ServerOption {
 	}
 }
 
+// RegisterServices registers several services using their generated register functions, so they are served at the same
+// address. The methods are routed by their full name "/package.Service/Method", so the services could have methods
+// with the same name.
+//
+//    grpcmock.MockUnstartedServer(
+//    	grpcmock.RegisterServices(
+//    		authpb.RegisterAuthServiceServer,
+//    		datapb.RegisterDataServiceServer,
+//    	),
+//    	func(s *grpcmock.Server) {
+//    		s.ExpectUnary("auth.AuthService/Get").
+//    			Return(&authpb.Token{})
+//
+//    		s.ExpectUnary("data.DataService/Get").
+//    			Return(&datapb.Record{})
+//    	},
+//    )(t)
+//
+// See: RegisterService().
+func RegisterServices(registerFuncs ...interface{}) ServerOption {
+	return func(s *Server) {
+		for _, registerFunc := range registerFuncs {
+			RegisterService(registerFunc)(s)
+		}
+	}
+}
+
 // RegisterServiceFromInstance registers a new service using the generated server interface.
 //
 //    grpcmock.MockUnstartedServer(
@@ -578,6 +1188,51 @@ func WithPort(port int) ServerOption {
 	return WithAddress(fmt.Sprintf(":%d", port))
 }
 
+// WithUnixSocket sets the server to listen on a unix socket. The socket file is removed when the server is closed.
+//
+//    grpcmock.MockServer(
+//    	grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+//    	grpcmock.WithUnixSocket("/tmp/grpcmock.sock"),
+//    )(t)
+//
+// The server could be reached with "unix:///tmp/grpcmock.sock/grpctest.ItemService/GetItem".
+func WithUnixSocket(path string) ServerOption {
+	return func(srv *Server) {
+		srv.newListener = newListenerByUnixSocket(path)
+	}
+}
+
+// WithShutdownTimeout sets the maximum duration to wait for the in-flight requests to finish when closing the server.
+// Default is 30 seconds.
+func WithShutdownTimeout(d time.Duration) ServerOption {
+	return func(srv *Server) {
+		srv.shutdownTimeout = d
+	}
+}
+
+// WithReflection enables the grpc reflection service on the server.
+func WithReflection() ServerOption {
+	return func(srv *Server) {
+		srv.reflection = true
+	}
+}
+
+// WithMetadataEcho copies the incoming metadata to the response trailers, which is handy for debugging the metadata
+// propagation. If prefixes are provided, only the metadata whose key starts with one of them are copied.
+//
+//    grpcmock.MockServer(
+//    	grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+//    	grpcmock.WithMetadataEcho("x-echo-"),
+//    )(t)
+//
+// See: WithTrailerCapture().
+func WithMetadataEcho(prefixes ...string) ServerOption {
+	return func(srv *Server) {
+		srv.echoMetadata = true
+		srv.echoMetadataPrefixes = prefixes
+	}
+}
+
 // WithListener sets the listener. Server does not need to start a new one.
 func WithListener(l net.Listener) ServerOption {
 	return func(srv *Server) {
@@ -589,6 +1244,97 @@ func WithListener(l net.Listener) ServerOption {
 	}
 }
 
+// WithBufConnSize sets the buffer size of the bufconn listener that is created by NewInProcess() or
+// MockServerWithBufConn(). Default is 1MB.
+//
+// The writes to a bufconn listener block when the buffer is full, so a test that sends large messages without reading
+// them at the same time, for example a handler that sends a large stream before the client starts receiving, could
+// deadlock with an undersized buffer.
+func WithBufConnSize(n int) ServerOption {
+	return func(srv *Server) {
+		srv.bufConnSize = n
+	}
+}
+
+// withBufConnListener creates a new bufconn listener when the server starts, the size is read at that time so the order
+// of the options does not matter.
+func withBufConnListener(l **bufconn.Listener) ServerOption {
+	return func(srv *Server) {
+		srv.newListener = func() (net.Listener, func() error) {
+			*l = bufconn.Listen(srv.bufConnSize)
+
+			return *l, (*l).Close
+		}
+	}
+}
+
+// WithRawBytesCodec keeps the wire bytes of the unary and server-stream requests, so they could be matched with
+// matcher.RawBytes(). The requests are still decoded, a payload that is not a valid proto message fails the call.
+//
+//    grpcmock.MockServer(
+//    	grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+//    	grpcmock.WithRawBytesCodec(),
+//    )(t)
+//
+// See: WithRawBytesPayload().
+func WithRawBytesCodec() ServerOption {
+	return func(srv *Server) {
+		srv.rawBytes = true
+		srv.serverOpts = append(srv.serverOpts, grpc.ForceServerCodec(rawBytesCodec{}))
+	}
+}
+
+// WithPanicHandler sets a function that is called with the recovered value when a handler panics, for example to log
+// the stack trace. The call fails with codes.Internal either way.
+//
+//    grpcmock.MockServer(
+//    	grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+//    	grpcmock.WithPanicHandler(func(recovered interface{}) {
+//    		t.Logf("handler panicked: %v\n%s", recovered, debug.Stack())
+//    	}),
+//    )(t)
+func WithPanicHandler(fn func(recovered interface{})) ServerOption {
+	return func(srv *Server) {
+		srv.panicHandler = fn
+	}
+}
+
+// WithRequestValidator sets a validator that runs before matching the expectations, a request is rejected with
+// codes.InvalidArgument and the message of the error if the validator returns an error. The validator receives the
+// full method name, for example "/grpctest.ItemService/GetItem", and the request. The messages of a client stream are
+// validated one by one, the bidirectional streams are not validated.
+//
+//    grpcmock.MockServer(
+//    	grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+//    	grpcmock.WithRequestValidator(func(method string, req interface{}) error {
+//    		if v, ok := req.(interface{ Validate() error }); ok {
+//    			return v.Validate()
+//    		}
+//
+//    		return nil
+//    	}),
+//    )(t)
+func WithRequestValidator(fn func(method string, req interface{}) error) ServerOption {
+	return func(srv *Server) {
+		srv.validator = fn
+	}
+}
+
+// WithServerLogger logs every call that is dispatched by the server with the method, the method type, the status code
+// and the duration.
+//
+//    grpcmock.MockServer(
+//    	grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+//    	grpcmock.WithServerLogger(logger),
+//    )(t)
+//
+// See: WithLogger().
+func WithServerLogger(l Logger) ServerOption {
+	return func(srv *Server) {
+		srv.logger = l
+	}
+}
+
 // FindServerMethod finds a method in the given server.
 func FindServerMethod(srv *Server, method string) *service.Method {
 	srv.mu.Lock()
@@ -506,8 +506,8 @@ func ExampleNewServer_serverStreamMethod_customStreamBehaviors() {
 	fmt.Printf("error: %s", err)
 
 	// Output:
-	// received items: 0
-	// error: rpc error: code = Aborted desc = server aborted the transaction
+	// received items: 1
+	// error: /grpctest.ItemService/ListItems: recv response: rpc error: code = Aborted desc = server aborted the transaction
 }
 
 func ExampleNewServer_bidirectionalStreamMethod() {
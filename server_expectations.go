@@ -0,0 +1,296 @@
+package grpcmock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+
+	grpcErrors "github.com/nhatthm/grpcmock/errors"
+	grpcMatcher "github.com/nhatthm/grpcmock/matcher"
+	grpcReflect "github.com/nhatthm/grpcmock/reflect"
+	"github.com/nhatthm/grpcmock/request"
+	"github.com/nhatthm/grpcmock/service"
+)
+
+// expectationsDocument describes the expectations that are loaded by Server.LoadExpectations().
+type expectationsDocument struct {
+	Expectations []expectationSpec `yaml:"expectations"`
+}
+
+// expectationSpec describes an expectation. The payload and the response are written in JSON, or in YAML, and are
+// converted to the types of the method using protojson.
+type expectationSpec struct {
+	Method   string      `yaml:"method"`
+	Payload  interface{} `yaml:"payload"`
+	Response interface{} `yaml:"response"`
+	Times    uint32      `yaml:"times"`
+}
+
+// LoadExpectations reads a YAML or JSON document and adds the expectations that it describes. The payload and the
+// response are converted to the input and the output types of the method, a list is expected for the stream side of a
+// client-stream or a server-stream method. An expectation is expected once if times is not set.
+//
+//    expectations:
+//      - method: grpctest.ItemService/GetItem
+//        payload: {"id": 42}
+//        response: {"id": 42, "name": "Foobar"}
+//        times: 2
+//      - method: grpctest.ItemService/ListItems
+//        response: [{"id": 41}, {"id": 42}]
+//
+// The document is validated before adding any expectations, so nothing is added if it returns an error. Bidirectional
+// stream methods are not supported.
+func (s *Server) LoadExpectations(r io.Reader) error {
+	var doc expectationsDocument
+
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("could not decode expectations: %w", err)
+	}
+
+	expects := make([]func(), 0, len(doc.Expectations))
+
+	for i, spec := range doc.Expectations {
+		expect, err := s.newExpectation(spec)
+		if err != nil {
+			return fmt.Errorf("could not load expectation #%d: %w", i, err)
+		}
+
+		expects = append(expects, expect)
+	}
+
+	for _, expect := range expects {
+		expect()
+	}
+
+	return nil
+}
+
+// ExpectFromGolden adds an expectation of a unary method from a pair of golden files, the request is read from
+// <path>.request.json and the response is read from <path>.response.json. Both files are written in protojson. The
+// request must match the golden request exactly, and the golden response is returned.
+//
+//    err := s.ExpectFromGolden("grpctest.ItemService/GetItem", "resources/fixtures/get_item")
+//
+// See: Server.LoadExpectations().
+func (s *Server) ExpectFromGolden(method, path string) error {
+	method = methodName(method)
+
+	s.mu.Lock()
+	svc, ok := s.services[method]
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", grpcErrors.ErrMethodNotFound, method)
+	}
+
+	if svc.MethodType != service.TypeUnary {
+		return fmt.Errorf("%w: %s is %s", grpcErrors.ErrUnsupportedMethodType, method, svc.MethodType)
+	}
+
+	in, err := readGoldenMessage(svc.Input, path+".request.json")
+	if err != nil {
+		return fmt.Errorf("could not read golden request: %w", err)
+	}
+
+	out, err := readGoldenMessage(svc.Output, path+".response.json")
+	if err != nil {
+		return fmt.Errorf("could not read golden response: %w", err)
+	}
+
+	s.ExpectUnary(svc.FullName()).
+		WithPayload(in).
+		Return(out)
+
+	return nil
+}
+
+// newExpectation converts the payload and the response of the expectation to the types of the method, and returns a
+// function that adds the expectation to the server.
+func (s *Server) newExpectation(spec expectationSpec) (func(), error) {
+	method := methodName(spec.Method)
+
+	s.mu.Lock()
+	svc, ok := s.services[method]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", grpcErrors.ErrMethodNotFound, method)
+	}
+
+	times := request.RepeatedTime(1)
+	if spec.Times > 0 {
+		times = request.RepeatedTime(spec.Times)
+	}
+
+	switch svc.MethodType {
+	case service.TypeUnary:
+		return s.newUnaryExpectation(svc, spec, times)
+
+	case service.TypeClientStream:
+		return s.newClientStreamExpectation(svc, spec, times)
+
+	case service.TypeServerStream:
+		return s.newServerStreamExpectation(svc, spec, times)
+	}
+
+	return nil, fmt.Errorf("%w: %s is %s", grpcErrors.ErrUnsupportedMethodType, method, svc.MethodType)
+}
+
+func (s *Server) newUnaryExpectation(svc *service.Method, spec expectationSpec, times request.RepeatedTime) (func(), error) {
+	in, err := newExpectationMessage(svc.Input, spec.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	out, err := newExpectationMessage(svc.Output, spec.Response)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return func() {
+		r := s.ExpectUnary(svc.FullName()).Times(times)
+
+		if in != nil {
+			r.WithPayload(in)
+		}
+
+		r.Return(newExpectationResponse(svc.Output, out)) // nolint: errcheck
+	}, nil
+}
+
+func (s *Server) newClientStreamExpectation(svc *service.Method, spec expectationSpec, times request.RepeatedTime) (func(), error) {
+	in, err := newExpectationMessages(svc.Input, spec.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	out, err := newExpectationMessage(svc.Output, spec.Response)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return func() {
+		r := s.ExpectClientStream(svc.FullName()).Times(times)
+
+		if in != nil {
+			r.WithPayloads(grpcMatcher.SequenceEq(in...))
+		}
+
+		r.Return(newExpectationResponse(svc.Output, out))
+	}, nil
+}
+
+func (s *Server) newServerStreamExpectation(svc *service.Method, spec expectationSpec, times request.RepeatedTime) (func(), error) {
+	in, err := newExpectationMessage(svc.Input, spec.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	out, err := newExpectationMessages(svc.Output, spec.Response)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	// The messages are sent from a []*T so that they match the output type of the method.
+	msgs := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(grpcReflect.New(svc.Output))), 0, len(out))
+
+	for _, msg := range out {
+		msgs = reflect.Append(msgs, reflect.ValueOf(msg))
+	}
+
+	return func() {
+		r := s.ExpectServerStream(svc.FullName()).Times(times)
+
+		if in != nil {
+			r.WithPayload(in)
+		}
+
+		r.Return(msgs.Interface())
+	}, nil
+}
+
+// newExpectationResponse returns the response of an expectation, an empty message is returned if the response is not
+// set.
+func newExpectationResponse(msgType interface{}, out interface{}) interface{} {
+	if out == nil {
+		return grpcReflect.New(msgType)
+	}
+
+	return out
+}
+
+// newExpectationMessage converts a value decoded from the document to a message of the given type. It returns nil if the
+// value is not set.
+func newExpectationMessage(msgType interface{}, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil // nolint: nilnil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, ok := grpcReflect.New(msgType).(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", grpcErrors.ErrUnsupportedDataType, msgType)
+	}
+
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// newExpectationMessages converts a list decoded from the document to messages of the given type. It returns nil if the
+// value is not set.
+func newExpectationMessages(msgType interface{}, v interface{}) ([]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	values, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: got %T, want a list", grpcErrors.ErrUnsupportedDataType, v)
+	}
+
+	msgs := make([]interface{}, 0, len(values))
+
+	for i, v := range values {
+		msg, err := newExpectationMessage(msgType, v)
+		if err != nil {
+			return nil, fmt.Errorf("message #%d: %w", i, err)
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, nil
+}
+
+// readGoldenMessage reads a message of the given type from a protojson file.
+func readGoldenMessage(msgType interface{}, path string) (proto.Message, error) {
+	data, err := os.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+
+	msg, ok := grpcReflect.New(msgType).(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", grpcErrors.ErrUnsupportedDataType, msgType)
+	}
+
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("could not decode %s: %w", path, err)
+	}
+
+	return msg, nil
+}
@@ -3,15 +3,25 @@ package grpcmock
 import (
 	"context"
 	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
 
+	grpcAssert "github.com/nhatthm/grpcmock/assert"
+	grpcMatcher "github.com/nhatthm/grpcmock/matcher"
 	grpcMock "github.com/nhatthm/grpcmock/mock/grpc"
 	"github.com/nhatthm/grpcmock/planner"
 	"github.com/nhatthm/grpcmock/service"
@@ -57,6 +67,211 @@ func TestServer_HandleRequest_Unexpected(t *testing.T) {
 	}
 }
 
+type subjectCtxKey struct{}
+
+func TestServer_ReturnFunc_ContextValues(t *testing.T) {
+	t.Parallel()
+
+	buf := bufconn.Listen(1024 * 1024)
+
+	s := NewServer(
+		RegisterService(grpctest.RegisterItemServiceServer),
+		WithListener(buf),
+		func(s *Server) {
+			s.serverOpts = append(s.serverOpts, grpc.ChainUnaryInterceptor(
+				func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+					var subject string
+
+					if md, ok := metadata.FromIncomingContext(ctx); ok {
+						if values := md.Get("authorization"); len(values) > 0 {
+							subject = values[0]
+						}
+					}
+
+					return handler(context.WithValue(ctx, subjectCtxKey{}, subject), req)
+				},
+			))
+		},
+		func(s *Server) {
+			s.ExpectUnary("grpctest.ItemService/GetItem").UnlimitedTimes().
+				ReturnFunc(func(ctx context.Context, in interface{}) (interface{}, error) {
+					if subject, _ := ctx.Value(subjectCtxKey{}).(string); subject != "admin" {
+						return nil, status.Error(codes.PermissionDenied, "permission denied")
+					}
+
+					return &grpctest.Item{Id: in.(*grpctest.GetItemRequest).Id}, nil // nolint: errcheck
+				})
+		},
+	)
+
+	defer s.Close() // nolint: errcheck
+
+	getItem := func(subject string) (*grpctest.Item, error) {
+		out := &grpctest.Item{}
+
+		err := InvokeUnary(context.Background(),
+			"grpctest.ItemService/GetItem",
+			&grpctest.GetItemRequest{Id: 42}, out,
+			WithHeader("authorization", subject),
+			WithBufConnDialer(buf),
+			WithInsecure(),
+		)
+
+		return out, err
+	}
+
+	_, err := getItem("guest")
+
+	assert.Equal(t, status.Error(codes.PermissionDenied, "permission denied"), err)
+
+	out, err := getItem("admin")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(42), out.Id)
+}
+
+// inPayloadCounter counts the request messages that are decoded by the server.
+type inPayloadCounter struct {
+	count int32
+}
+
+func (c *inPayloadCounter) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (c *inPayloadCounter) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if _, ok := s.(*stats.InPayload); ok {
+		atomic.AddInt32(&c.count, 1)
+	}
+}
+
+func (c *inPayloadCounter) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (c *inPayloadCounter) HandleConn(context.Context, stats.ConnStats) {}
+
+func TestServer_WithRawBytesCodec_DecodeOnce(t *testing.T) {
+	t.Parallel()
+
+	buf := bufconn.Listen(1024 * 1024)
+	counter := &inPayloadCounter{}
+
+	s := NewServer(
+		RegisterService(grpctest.RegisterItemServiceServer),
+		WithListener(buf),
+		WithRawBytesCodec(),
+		func(s *Server) {
+			s.serverOpts = append(s.serverOpts, grpc.StatsHandler(counter))
+
+			s.ExpectUnary("grpctest.ItemService/GetItem").
+				WithPayload(grpcMatcher.RawBytes([]byte{0x08, 0x2a})).
+				Return(&grpctest.Item{Id: 42})
+		},
+	)
+
+	defer s.Close() // nolint: errcheck
+
+	out := &grpctest.Item{}
+
+	err := InvokeUnary(context.Background(),
+		"grpctest.ItemService/GetItem",
+		&grpctest.GetItemRequest{Id: 42}, out,
+		WithBufConnDialer(buf),
+		WithInsecure(),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(42), out.Id)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&counter.count))
+}
+
+func TestServer_WithRawBytesCodec_Streams(t *testing.T) {
+	t.Parallel()
+
+	buf := bufconn.Listen(1024 * 1024)
+
+	var (
+		mu       sync.Mutex
+		received []interface{}
+	)
+
+	receive := func(msg interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		received = append(received, msg)
+	}
+
+	s := NewServer(
+		RegisterService(grpctest.RegisterItemServiceServer),
+		WithListener(buf),
+		WithRawBytesCodec(),
+		func(s *Server) {
+			s.ExpectServerStream("grpctest.ItemService/ListItems").
+				Run(func(_ context.Context, in interface{}, _ grpc.ServerStream) error {
+					receive(in)
+
+					return nil
+				})
+
+			s.ExpectClientStream("grpctest.ItemService/CreateItems").
+				Run(func(_ context.Context, s grpc.ServerStream) (interface{}, error) {
+					for {
+						msg := &grpctest.Item{}
+
+						if err := s.RecvMsg(msg); err != nil {
+							if errors.Is(err, io.EOF) {
+								return &grpctest.CreateItemsResponse{}, nil
+							}
+
+							return nil, err
+						}
+
+						receive(msg)
+					}
+				})
+		},
+	)
+
+	defer s.Close() // nolint: errcheck
+
+	err := InvokeServerStream(context.Background(),
+		"grpctest.ItemService/ListItems",
+		&grpctest.ListItemsRequest{}, RecvAll(&[]*grpctest.Item{}),
+		WithBufConnDialer(buf),
+		WithInsecure(),
+	)
+
+	assert.NoError(t, err)
+
+	err = InvokeClientStream(context.Background(),
+		"grpctest.ItemService/CreateItems",
+		SendAll([]*grpctest.Item{{Id: 41}, {Id: 42}}), &grpctest.CreateItemsResponse{},
+		WithBufConnDialer(buf),
+		WithInsecure(),
+	)
+
+	assert.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The handlers receive the decoded messages, not the wrappers of the raw bytes codec.
+	expected := []proto.Message{
+		&grpctest.ListItemsRequest{},
+		&grpctest.Item{Id: 41},
+		&grpctest.Item{Id: 42},
+	}
+
+	require.Len(t, received, len(expected))
+
+	for i, msg := range received {
+		require.IsType(t, expected[i], msg)
+		grpcAssert.EqualMessage(t, expected[i], msg.(proto.Message))
+	}
+}
+
 func TestCloseGRPCServer_Error(t *testing.T) {
 	t.Parallel()
 
@@ -65,12 +280,12 @@ func TestCloseGRPCServer_Error(t *testing.T) {
 		func(s *Server) {
 			s.ExpectUnary("grpctest.ItemService/GetItem").
 				After(time.Millisecond * 300).
-				Return(&grpctest.GetItemRequest{Id: 42})
+				Return(&grpctest.Item{Id: 42})
 		},
 	)
 
 	buf := bufconn.Listen(1024 * 1024)
-	srv, _ := buildGRPCServer(s.services, s.handleRequest, s.serverOpts...)
+	srv, _ := buildGRPCServer(s.services, s.handleRequest, false, s.shutdownTimeout, s.serverOpts...)
 
 	go func() {
 		defer buf.Close() // nolint: errcheck
@@ -98,6 +313,84 @@ func TestCloseGRPCServer_Error(t *testing.T) {
 	err := closeGRPCServer(srv, time.Millisecond*100)
 
 	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualError(t, err, "could not stop server gracefully, forced to stop: context deadline exceeded")
+}
+
+func TestServer_Close_ForcedStop_ClosesListener(t *testing.T) {
+	t.Parallel()
+
+	buf := bufconn.Listen(1024 * 1024)
+	closed := make(chan struct{})
+
+	s := NewUnstartedServer(
+		RegisterService(grpctest.RegisterItemServiceServer),
+		WithShutdownTimeout(50*time.Millisecond),
+		func(s *Server) {
+			s.newListener = func() (net.Listener, func() error) {
+				return buf, func() error {
+					close(closed)
+
+					return nil
+				}
+			}
+
+			s.ExpectUnary("grpctest.ItemService/GetItem").
+				After(time.Second).
+				Return(&grpctest.Item{Id: 42})
+		},
+	)
+
+	s.Serve()
+
+	go func() {
+		// nolint: errcheck
+		_ = InvokeUnary(context.Background(),
+			"grpctest.ItemService/GetItem",
+			&grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+			WithBufConnDialer(buf),
+			WithInsecure(),
+		)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, s.WaitForCalls(ctx, "grpctest.ItemService/GetItem", 1))
+
+	err := s.Close()
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The listener is closed when Close() returns, even if the server is forced to stop.
+	select {
+	case <-closed:
+	default:
+		t.Error("listener is not closed")
+	}
+}
+
+func TestBuildServiceDescriptions_Streams(t *testing.T) {
+	t.Parallel()
+
+	s := NewUnstartedServer(RegisterService(grpctest.RegisterItemServiceServer))
+
+	descs := buildServiceDescriptions(s.services, s.handleRequest, false)
+
+	assert.Len(t, descs, 1)
+
+	actual := make(map[string][2]bool)
+
+	for _, desc := range descs[0].Streams {
+		actual[desc.StreamName] = [2]bool{desc.ClientStreams, desc.ServerStreams}
+	}
+
+	expected := map[string][2]bool{
+		"ListItems":      {false, true},
+		"CreateItems":    {true, false},
+		"TransformItems": {true, true},
+	}
+
+	assert.Equal(t, expected, actual)
 }
 
 func TestNewUnaryHandler(t *testing.T) {
@@ -168,7 +461,7 @@ func TestNewUnaryHandler(t *testing.T) {
 		t.Run(tc.scenario, func(t *testing.T) {
 			t.Parallel()
 
-			result, err := newUnaryHandler(svc, tc.handle)(nil, context.Background(), tc.decode, tc.interceptor)
+			result, err := newUnaryHandler(svc, tc.handle, false)(nil, context.Background(), tc.decode, tc.interceptor)
 
 			assert.Equal(t, tc.expectedResult, result)
 			assert.Equal(t, tc.expectedError, err)
@@ -238,7 +531,7 @@ func TestNewStreamHandler_ServerStream(t *testing.T) {
 		t.Run(tc.scenario, func(t *testing.T) {
 			t.Parallel()
 
-			err := newStreamHandler(svc, tc.handle)(nil, tc.mockStream(t))
+			err := newStreamHandler(svc, tc.handle, false)(nil, tc.mockStream(t))
 
 			assert.Equal(t, tc.expectedError, err)
 		})
@@ -288,7 +581,7 @@ func TestNewStreamHandler_ClientStream(t *testing.T) {
 		t.Run(tc.scenario, func(t *testing.T) {
 			t.Parallel()
 
-			err := newStreamHandler(svc, tc.handle)(nil, tc.mockStream(t))
+			err := newStreamHandler(svc, tc.handle, false)(nil, tc.mockStream(t))
 
 			assert.Equal(t, tc.expectedError, err)
 		})
@@ -338,7 +631,7 @@ func TestNewStreamHandler_BidirectionalStream(t *testing.T) {
 		t.Run(tc.scenario, func(t *testing.T) {
 			t.Parallel()
 
-			err := newStreamHandler(svc, tc.handle)(nil, tc.mockStream(t))
+			err := newStreamHandler(svc, tc.handle, false)(nil, tc.mockStream(t))
 
 			assert.Equal(t, tc.expectedError, err)
 		})
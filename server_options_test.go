@@ -0,0 +1,58 @@
+//go:build !testcoverage
+
+package grpcmock_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nhatthm/grpcmock"
+	grpcAssert "github.com/nhatthm/grpcmock/assert"
+	"github.com/nhatthm/grpcmock/test/grpctest"
+)
+
+func TestServer_ExpectServerStream_InitialWindowSize(t *testing.T) {
+	t.Parallel()
+
+	const windowSize = 64 * 1024
+
+	// The stream is 16 times larger than the windows, the server has to wait for the client to read.
+	expected := make([]*grpctest.Item, 64)
+
+	for i := range expected {
+		expected[i] = &grpctest.Item{Id: int32(i), Name: strings.Repeat("x", 16*1024)}
+	}
+
+	_, d := mockItemServiceServer(t,
+		grpcmock.InitialWindowSize(windowSize),
+		grpcmock.InitialConnWindowSize(windowSize),
+		func(s *grpcmock.Server) {
+			s.ExpectServerStream(grpcTestServiceListItems).
+				Return(expected)
+		},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	actual := make([]*grpctest.Item, 0)
+
+	err := grpcmock.InvokeServerStream(ctx, grpcTestServiceListItems, &grpctest.ListItemsRequest{},
+		grpcmock.RecvAll(&actual),
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+		grpcmock.WithInitialWindowSize(windowSize),
+		grpcmock.WithInitialConnWindowSize(windowSize),
+	)
+
+	require.NoError(t, err)
+	require.Len(t, actual, len(expected))
+
+	for i := range expected {
+		grpcAssert.EqualMessage(t, expected[i], actual[i])
+	}
+}
@@ -0,0 +1,101 @@
+package grpcmock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/nhatthm/grpcmock/format"
+	"github.com/nhatthm/grpcmock/service"
+	"github.com/nhatthm/grpcmock/value"
+)
+
+// strictTest reports the unexpected calls to a test. It stops reporting once the test finishes because the calls are
+// handled in the goroutines of the server, which may outlive the test.
+type strictTest struct {
+	t T
+
+	mu   sync.Mutex
+	done bool
+}
+
+func newStrictTest(t T) *strictTest {
+	st := &strictTest{t: t}
+
+	t.Cleanup(func() {
+		st.mu.Lock()
+		defer st.mu.Unlock()
+
+		st.done = true
+	})
+
+	return st
+}
+
+func (st *strictTest) reportUnexpectedCall(ctx context.Context, svc service.Method, in interface{}, err error) {
+	if st == nil {
+		return
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.done {
+		return
+	}
+
+	var sb strings.Builder
+
+	format.Request(&sb, svc, incomingHeaders(ctx), unexpectedPayload(in))
+
+	st.t.Errorf("unexpected call: %sError: %s", sb.String(), err.Error())
+}
+
+// incomingHeaders returns the first value of the incoming metadata, except the reserved ones.
+func incomingHeaders(ctx context.Context) map[string]string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	header := make(map[string]string, md.Len())
+
+	for k, v := range md {
+		if len(v) > 0 && isEchoedMetadata(k, nil) {
+			header[k] = v[0]
+		}
+	}
+
+	return header
+}
+
+func unexpectedPayload(in interface{}) interface{} {
+	if in == nil {
+		return nil
+	}
+
+	payload, err := value.Marshal(in)
+	if err != nil {
+		return fmt.Sprintf("could not read request payload: %s", err.Error())
+	}
+
+	return payload
+}
+
+// WithStrictMode fails the test when the server receives a call that does not match any expectation, in addition to
+// returning an error to the client. The method, the payload and the metadata of the call are reported. The calls that
+// are received after the test finishes are not reported. A call that does not match the expectations is only reported
+// by the strict mode, not by the test of the server.
+//
+//    grpcmock.MockServer(
+//    	grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+//    	grpcmock.WithStrictMode(t),
+//    )(t)
+func WithStrictMode(t T) ServerOption {
+	return func(s *Server) {
+		s.strictTest = newStrictTest(t)
+	}
+}
@@ -2,23 +2,39 @@ package grpcmock_test
 
 import (
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	goMatcher "github.com/nhatthm/go-matcher"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/nhatthm/grpcmock"
 	grpcAssert "github.com/nhatthm/grpcmock/assert"
+	grpcErrors "github.com/nhatthm/grpcmock/errors"
+	"github.com/nhatthm/grpcmock/matcher"
 	"github.com/nhatthm/grpcmock/mock/planner"
+	grpcPlanner "github.com/nhatthm/grpcmock/planner"
+	"github.com/nhatthm/grpcmock/request"
 	"github.com/nhatthm/grpcmock/service"
+	"github.com/nhatthm/grpcmock/stream"
 	testSrv "github.com/nhatthm/grpcmock/test"
 	"github.com/nhatthm/grpcmock/test/grpctest"
 )
@@ -30,6 +46,66 @@ const (
 	grpcTestServiceTransformItems = "grpctest.ItemService/TransformItems"
 )
 
+func TestNewInProcess(t *testing.T) {
+	t.Parallel()
+
+	s, opts := grpcmock.NewInProcess(grpctest.RegisterItemServiceServer)
+
+	defer s.Close() // nolint: errcheck
+
+	s.ExpectUnary(grpcTestServiceGetItem).
+		WithPayload(&grpctest.GetItemRequest{Id: 42}).
+		Return(&grpctest.Item{Id: 42, Name: "Foobar"})
+
+	actual := &grpctest.Item{}
+	err := grpcmock.InvokeUnary(context.Background(), grpcTestServiceGetItem, &grpctest.GetItemRequest{Id: 42}, actual, opts...)
+
+	expected := &grpctest.Item{Id: 42, Name: "Foobar"}
+
+	grpcAssert.EqualMessage(t, expected, actual)
+	assert.NoError(t, err)
+	assert.NoError(t, s.ExpectationsWereMet())
+}
+
+func TestNewInProcess_WithBufConnSize(t *testing.T) {
+	t.Parallel()
+
+	// The message is larger than the default buffer.
+	name := strings.Repeat("a", 2*1024*1024)
+
+	s, opts := grpcmock.NewInProcess(grpctest.RegisterItemServiceServer, grpcmock.WithBufConnSize(4*1024*1024))
+
+	defer s.Close() // nolint: errcheck
+
+	s.ExpectServerStream(grpcTestServiceListItems).
+		Return([]*grpctest.Item{{Id: 42, Name: name}})
+
+	var actual []*grpctest.Item
+
+	err := grpcmock.InvokeServerStream(context.Background(), grpcTestServiceListItems, &grpctest.ListItemsRequest{}, grpcmock.RecvAll(&actual), opts...)
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, 1)
+	assert.Len(t, actual[0].Name, len(name))
+	assert.NoError(t, s.ExpectationsWereMet())
+}
+
+func TestMockServerWithBufConn_WithBufConnSize(t *testing.T) {
+	t.Parallel()
+
+	name := strings.Repeat("a", 2*1024*1024)
+
+	_, d := mockItemServiceServer(t, grpcmock.WithBufConnSize(4*1024*1024), func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			Return(&grpctest.Item{Id: 42, Name: name})
+	})
+
+	actual, err := getItem(d, 42)
+
+	assert.NoError(t, err)
+	assert.Len(t, actual.GetName(), len(name))
+}
+
 func TestServer_WithPlanner(t *testing.T) {
 	t.Parallel()
 
@@ -205,75 +281,147 @@ func TestServer_ExpectUnary_Success(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestServer_ExpectUnary_WrongPayload(t *testing.T) {
+func TestServer_ExpectUnary_ReturnFunc(t *testing.T) {
 	t.Parallel()
 
-	_, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
-		s.ExpectUnary(grpcTestServiceGetItem).
-			WithHeader("locale", "en-US").
-			WithPayload(&grpctest.GetItemRequest{Id: 1})
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).Twice().
+			ReturnFunc(func(_ context.Context, in interface{}) (interface{}, error) {
+				req := in.(*grpctest.GetItemRequest) // nolint: errcheck
+
+				return &grpctest.Item{Id: req.Id, Name: fmt.Sprintf("Item #%d", req.Id)}, nil
+			})
 	})
 
-	actual, err := getItem(d, 42)
+	for _, id := range []int32{1, 2} {
+		actual, err := getItem(d, id)
 
-	expectedErrorMessage := `Expected: Unary /grpctest.ItemService/GetItem
-    with header:
-        locale: en-US
-    with payload using matcher.JSONMatcher
-        {"id":1}
-Actual: Unary /grpctest.ItemService/GetItem
-    with header:
-        locale: en-US
-    with payload
-        {"id":42}
-Error: expected request payload: {"id":1}, received: {"id":42}
-`
-	expectedError := status.Error(codes.Internal, expectedErrorMessage)
+		expected := &grpctest.Item{Id: id, Name: fmt.Sprintf("Item #%d", id)}
 
-	assert.Nil(t, actual)
-	assert.Equal(t, expectedError, err)
+		grpcAssert.EqualMessage(t, expected, actual)
+		assert.NoError(t, err)
+	}
 }
 
-func TestServer_ExpectServerStream_Unexpected(t *testing.T) {
+func TestServer_ExpectUnary_ReturnDefault(t *testing.T) {
 	t.Parallel()
 
-	_, d := mockItemServiceServer(t)
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithPayload(&grpctest.GetItemRequest{Id: 42}).
+			ReturnDefault()
+	})
 
-	actual, err := listItems(d)
+	actual, err := getItem(d, 42)
+
+	assert.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.Item{}, actual)
+}
+
+func TestServer_ExpectUnary_NoReturn(t *testing.T) {
+	t.Parallel()
 
-	expectedError := `rpc error: code = FailedPrecondition desc = unexpected request received: "/grpctest.ItemService/ListItems", payload: {}`
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem)
+	})
+
+	actual, err := getItem(d, 42)
 
 	assert.Nil(t, actual)
-	assert.EqualError(t, err, expectedError)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
 }
 
-func TestServer_ExpectServerStream_Panic(t *testing.T) {
+func TestServer_WithRequestValidator(t *testing.T) {
 	t.Parallel()
 
+	validator := grpcmock.WithRequestValidator(func(method string, req interface{}) error {
+		switch req := req.(type) {
+		case *grpctest.GetItemRequest:
+			if req.Id <= 0 {
+				return fmt.Errorf("%s: invalid id %d", method, req.Id) // nolint: goerr113
+			}
+
+		case *grpctest.Item:
+			if req.Name == "" {
+				return fmt.Errorf("%s: missing name of item %d", method, req.Id) // nolint: goerr113
+			}
+		}
+
+		return nil
+	})
+
 	testCases := []struct {
-		scenario string
-		method   string
-		expected string
+		scenario        string
+		mockServer      grpcmock.ServerOption
+		invoke          func(d grpcmock.ContextDialer) error
+		expectedCode    codes.Code
+		expectedMessage string
 	}{
 		{
-			scenario: "not found",
-			method:   "unknown",
-			expected: `method not found: /unknown`,
+			scenario: "unary is valid",
+			mockServer: func(s *grpcmock.Server) {
+				s.ExpectUnary(grpcTestServiceGetItem).
+					Return(&grpctest.Item{Id: 42})
+			},
+			invoke: func(d grpcmock.ContextDialer) error {
+				_, err := getItem(d, 42)
+
+				return err
+			},
+			expectedCode: codes.OK,
 		},
 		{
-			scenario: "unary",
-			method:   grpcTestServiceGetItem,
-			expected: `method is not server-stream: grpctest.ItemService/GetItem`,
+			scenario: "unary is invalid",
+			mockServer: func(s *grpcmock.Server) {
+				s.ExpectUnary(grpcTestServiceGetItem).
+					Return(&grpctest.Item{Id: 42})
+			},
+			invoke: func(d grpcmock.ContextDialer) error {
+				_, err := getItem(d, -1)
+
+				return err
+			},
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "/grpctest.ItemService/GetItem: invalid id -1",
 		},
 		{
-			scenario: "client stream",
-			method:   grpcTestServiceCreateItems,
-			expected: `method is not server-stream: grpctest.ItemService/CreateItems`,
+			scenario:   "unary is invalid without expectations",
+			mockServer: func(*grpcmock.Server) {},
+			invoke: func(d grpcmock.ContextDialer) error {
+				_, err := getItem(d, -1)
+
+				return err
+			},
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "/grpctest.ItemService/GetItem: invalid id -1",
 		},
 		{
-			scenario: "bidirectional stream",
-			method:   grpcTestServiceTransformItems,
-			expected: `method is not server-stream: grpctest.ItemService/TransformItems`,
+			scenario: "client stream is valid",
+			mockServer: func(s *grpcmock.Server) {
+				s.ExpectClientStream(grpcTestServiceCreateItems).
+					WithPayload(grpcmock.MatchClientStreamMsgCount(2)).
+					Return(&grpctest.CreateItemsResponse{NumItems: 2})
+			},
+			invoke: func(d grpcmock.ContextDialer) error {
+				_, err := createItems(d, &grpctest.Item{Id: 41, Name: "Foo"}, &grpctest.Item{Id: 42, Name: "Bar"})
+
+				return err
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			scenario: "client stream is invalid",
+			mockServer: func(s *grpcmock.Server) {
+				s.ExpectClientStream(grpcTestServiceCreateItems).
+					Return(&grpctest.CreateItemsResponse{NumItems: 2})
+			},
+			invoke: func(d grpcmock.ContextDialer) error {
+				_, err := createItems(d, &grpctest.Item{Id: 41, Name: "Foo"}, &grpctest.Item{Id: 42})
+
+				return err
+			},
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "/grpctest.ItemService/CreateItems: missing name of item 42",
 		},
 	}
 
@@ -282,75 +430,96 @@ func TestServer_ExpectServerStream_Panic(t *testing.T) {
 		t.Run(tc.scenario, func(t *testing.T) {
 			t.Parallel()
 
-			assert.PanicsWithError(t, tc.expected, func() {
-				mockItemServiceServer(t, func(s *grpcmock.Server) {
-					s.ExpectServerStream(tc.method)
-				})
-			})
+			s, d := mockItemServiceServer(grpcmock.NoOpT(), tc.mockServer, validator)
+
+			err := tc.invoke(d)
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+			assert.Equal(t, tc.expectedMessage, status.Convert(err).Message())
+
+			if tc.expectedCode != codes.OK {
+				assert.Empty(t, s.Requests)
+			}
 		})
 	}
 }
 
-func TestServer_ExpectServerStream_Success(t *testing.T) {
+func TestServer_ExpectUnary_ExactExcept(t *testing.T) {
 	t.Parallel()
 
-	expected := testSrv.DefaultItems()
 	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
-		s.ExpectServerStream(grpcTestServiceListItems).
-			ReturnStream().
-			SendMany(expected)
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithPayload(matcher.ExactExcept(&grpctest.GetItemRequest{Id: 1}, "id")).
+			Return(&grpctest.Item{Id: 42})
 	})
 
-	actual, err := listItems(d)
+	actual, err := getItem(d, 42)
 
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, actual)
 	assert.NoError(t, err)
-	assert.Len(t, actual, len(expected))
-
-	for i := 0; i < len(expected); i++ {
-		grpcAssert.EqualMessage(t, expected[i], actual[i])
-	}
 }
 
-func TestServer_ExpectClientStream_Unexpected(t *testing.T) {
+func TestServer_ExpectUnary_JSONEqWith(t *testing.T) {
 	t.Parallel()
 
-	_, d := mockItemServiceServer(t)
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithPayload(matcher.JSONEqWith(`{"id": 42, "unknown": "field"}`, matcher.IgnoreUnknownFields())).
+			Return(&grpctest.Item{Id: 42})
+	})
 
-	actual, err := createItems(d, &grpctest.Item{Id: 42})
+	actual, err := getItem(d, 42)
+
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, actual)
+	assert.NoError(t, err)
+}
 
-	expectedError := `rpc error: code = FailedPrecondition desc = unexpected request received: "/grpctest.ItemService/CreateItems", payload: [{"id":42}]`
+func TestServer_ExpectUnary_AllOf(t *testing.T) {
+	t.Parallel()
 
-	assert.Nil(t, actual)
-	assert.EqualError(t, err, expectedError)
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithPayload(matcher.AllOf(
+				goMatcher.JSON(`{"id": "<ignore-diff>"}`),
+				matcher.Not(goMatcher.JSON(`{"id": 41}`)),
+			)).
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	actual, err := getItem(d, 42)
+
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, actual)
+	assert.NoError(t, err)
 }
 
-func TestServer_ExpectClientStream_Panic(t *testing.T) {
+func TestServer_ExpectUnary_Combinators_ProtoMatchers(t *testing.T) {
 	t.Parallel()
 
 	testCases := []struct {
 		scenario string
-		method   string
-		expected string
+		matcher  goMatcher.Matcher
 	}{
 		{
-			scenario: "not found",
-			method:   "unknown",
-			expected: `method not found: /unknown`,
+			scenario: "all of exact except",
+			matcher:  matcher.AllOf(matcher.ExactExcept(&grpctest.GetItemRequest{Id: 1}, "id")),
 		},
 		{
-			scenario: "unary",
-			method:   grpcTestServiceGetItem,
-			expected: `method is not client-stream: grpctest.ItemService/GetItem`,
+			scenario: "not field",
+			matcher:  matcher.Not(matcher.Field("id", goMatcher.Exact(int32(41)))),
 		},
 		{
-			scenario: "server stream",
-			method:   grpcTestServiceListItems,
-			expected: `method is not client-stream: grpctest.ItemService/ListItems`,
+			scenario: "any of field",
+			matcher: matcher.AnyOf(
+				matcher.Field("id", goMatcher.Exact(int32(41))),
+				matcher.Field("id", goMatcher.Exact(int32(42))),
+			),
 		},
 		{
-			scenario: "bidirectional stream",
-			method:   grpcTestServiceTransformItems,
-			expected: `method is not client-stream: grpctest.ItemService/TransformItems`,
+			scenario: "json and proto matchers",
+			matcher: matcher.AllOf(
+				goMatcher.JSON(`{"id": 42}`),
+				matcher.Not(matcher.Field("id", goMatcher.Exact(int32(41)))),
+			),
 		},
 	}
 
@@ -359,175 +528,2644 @@ func TestServer_ExpectClientStream_Panic(t *testing.T) {
 		t.Run(tc.scenario, func(t *testing.T) {
 			t.Parallel()
 
-			assert.PanicsWithError(t, tc.expected, func() {
-				mockItemServiceServer(t, func(s *grpcmock.Server) {
-					s.ExpectClientStream(tc.method)
-				})
+			_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+				s.ExpectUnary(grpcTestServiceGetItem).
+					WithPayload(tc.matcher).
+					Return(&grpctest.Item{Id: 42})
 			})
+
+			actual, err := getItem(d, 42)
+
+			assert.NoError(t, err)
+			grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, actual)
 		})
 	}
 }
 
-func TestServer_ExpectClientStream_Success(t *testing.T) {
+func TestServer_ExpectClientStream_WithPayload_ProtoMatchers(t *testing.T) {
 	t.Parallel()
 
-	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
-		s.ExpectClientStream(grpcTestServiceCreateItems).
-			WithHeader(`locale`, `en-US`).
-			WithPayload([]*grpctest.Item{{Id: 42}}).
-			Run(func(_ context.Context, s grpc.ServerStream) (interface{}, error) {
-				cnt := int64(0)
-
-				for {
-					err := s.RecvMsg(&grpctest.Item{})
+	testCases := []struct {
+		scenario      string
+		matcher       goMatcher.Matcher
+		expectedError bool
+	}{
+		{
+			scenario: "field",
+			matcher:  matcher.Field("locale", goMatcher.Exact("en-US")),
+		},
+		{
+			scenario:      "field of one message",
+			matcher:       matcher.Field("id", goMatcher.Exact(int32(42))),
+			expectedError: true,
+		},
+		{
+			scenario: "exact except",
+			matcher:  matcher.ExactExcept(&grpctest.Item{Locale: "en-US"}, "id", "name"),
+		},
+		{
+			scenario: "json contains",
+			matcher:  matcher.JSONContains(`{"locale": "en-US"}`),
+		},
+		{
+			scenario: "payload size",
+			matcher:  matcher.PayloadSize(1, 64),
+		},
+		{
+			scenario: "combinator",
+			matcher: matcher.AllOf(
+				matcher.Field("locale", goMatcher.Exact("en-US")),
+				matcher.Not(matcher.Field("id", goMatcher.Exact(int32(0)))),
+			),
+		},
+	}
 
-					if errors.Is(err, io.EOF) {
-						break
-					}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
 
-					if err != nil {
-						return nil, err
-					}
+			_, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
+				s.ExpectClientStream(grpcTestServiceCreateItems).
+					WithPayload(tc.matcher).
+					Return(&grpctest.CreateItemsResponse{NumItems: 2})
+			})
 
-					cnt++
-				}
+			actual, err := createItems(d,
+				&grpctest.Item{Id: 41, Locale: "en-US", Name: "Foo"},
+				&grpctest.Item{Id: 42, Locale: "en-US", Name: "Bar"},
+			)
 
-				return &grpctest.CreateItemsResponse{NumItems: cnt}, nil
-			})
-	})
+			if tc.expectedError {
+				assert.Equal(t, codes.Internal, status.Code(err))
 
-	actual, err := createItems(d, &grpctest.Item{Id: 42})
-	expected := &grpctest.CreateItemsResponse{NumItems: 1}
+				return
+			}
 
-	assert.NoError(t, err)
-	grpcAssert.EqualMessage(t, expected, actual)
+			require.NoError(t, err)
+			grpcAssert.EqualMessage(t, &grpctest.CreateItemsResponse{NumItems: 2}, actual)
+		})
+	}
 }
 
-func TestServer_ExpectClientStream_CustomMatcher_Success(t *testing.T) {
+func TestServer_ExpectUnary_WithPayload_Field(t *testing.T) {
 	t.Parallel()
 
 	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
-		s.ExpectClientStream(grpcTestServiceCreateItems).
-			WithHeader(`locale`, `en-US`).
-			WithPayload(grpcmock.MatchClientStreamMsgCount(1)).
-			Run(func(_ context.Context, s grpc.ServerStream) (interface{}, error) {
-				cnt := int64(0)
-
-				for {
-					err := s.RecvMsg(&grpctest.Item{})
-
-					if errors.Is(err, io.EOF) {
-						break
-					}
-
-					if err != nil {
-						return nil, err
-					}
-
-					cnt++
-				}
-
-				return &grpctest.CreateItemsResponse{NumItems: cnt}, nil
-			})
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithPayload(matcher.Field("id", goMatcher.Exact(int32(42)))).
+			Return(&grpctest.Item{Id: 42})
 	})
 
-	actual, err := createItems(d, &grpctest.Item{Id: 42})
-	expected := &grpctest.CreateItemsResponse{NumItems: 1}
+	actual, err := getItem(d, 42)
 
 	assert.NoError(t, err)
-	grpcAssert.EqualMessage(t, expected, actual)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, actual)
 }
 
-func TestServer_ExpectClientStream_MatchMsgCount_Mismatched(t *testing.T) {
+func TestServer_ExpectUnary_WithPayload_PayloadSize(t *testing.T) {
 	t.Parallel()
 
-	_, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
-		s.ExpectClientStream(grpcTestServiceCreateItems).
-			WithPayload(grpcmock.MatchClientStreamMsgCount(2))
-	})
+	testCases := []struct {
+		scenario string
+		id       int32
+		min      int
+		max      int
+	}{
+		{
+			scenario: "empty message",
+			id:       0,
+			min:      0,
+			max:      0,
+		},
+		{
+			scenario: "within range",
+			id:       42,
+			min:      1,
+			max:      1024,
+		},
+	}
 
-	actual, err := createItems(d, &grpctest.Item{Id: 42})
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
 
-	expected := `Expected: ClientStream /grpctest.ItemService/CreateItems
-    with payload
-        has 2 message(s)
-Actual: ClientStream /grpctest.ItemService/CreateItems
-    with payload
-        [{"id":42}]
-Error: expected request payload: has 2 message(s), received: [{"id":42}]
-`
+			_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+				s.ExpectUnary(grpcTestServiceGetItem).
+					WithPayload(matcher.PayloadSize(tc.min, tc.max)).
+					Return(&grpctest.Item{Id: tc.id})
+			})
+
+			actual, err := getItem(d, tc.id)
+
+			assert.NoError(t, err)
+			grpcAssert.EqualMessage(t, &grpctest.Item{Id: tc.id}, actual)
+		})
+	}
+}
+
+func TestServer_ExpectUnary_WithPayload_JSONContains(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		expected string
+		id       int32
+	}{
+		{
+			scenario: "zero value",
+			expected: `{"id": 0}`,
+			id:       0,
+		},
+		{
+			scenario: "non zero value",
+			expected: `{"id": 42}`,
+			id:       42,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+				s.ExpectUnary(grpcTestServiceGetItem).
+					WithPayload(matcher.JSONContains(tc.expected)).
+					Return(&grpctest.Item{Id: tc.id})
+			})
+
+			actual, err := getItem(d, tc.id)
+
+			assert.NoError(t, err)
+			grpcAssert.EqualMessage(t, &grpctest.Item{Id: tc.id}, actual)
+		})
+	}
+}
+
+func TestServer_ExpectServerStream_WithPayload_JSONContains(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectServerStream(grpcTestServiceListItems).
+			WithPayload(matcher.JSONContains(`{"pageSize": 10}`)).
+			Return([]*grpctest.Item{{Id: 42}})
+	})
+
+	var actual []*grpctest.Item
+
+	err := grpcmock.InvokeServerStream(context.Background(),
+		grpcTestServiceListItems,
+		&grpctest.ListItemsRequest{PageSize: 10},
+		grpcmock.RecvAll(&actual),
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, 1)
+}
+
+func TestServer_ExpectUnary_ReturnStatus(t *testing.T) {
+	t.Parallel()
+
+	badRequest := &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "id", Description: "must be positive"},
+		},
+	}
+
+	st, err := status.New(codes.InvalidArgument, "invalid item").WithDetails(badRequest)
+	require.NoError(t, err)
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			ReturnStatus(st)
+	})
+
+	actual, err := getItem(d, -1)
 
 	assert.Nil(t, actual)
-	assert.Error(t, err)
-	assert.Equal(t, status.Convert(err).Message(), expected)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = invalid item")
+
+	details, err := grpcmock.ErrorDetails(err)
+	require.NoError(t, err)
+	require.Len(t, details, 1)
+
+	grpcAssert.EqualMessage(t, badRequest, details[0])
 }
 
-func TestServer_ExpectClientStream_CustomStreamMatcher_Mismatched(t *testing.T) {
+func TestServer_ExpectUnary_WithPayload_RawBytes(t *testing.T) {
+	t.Parallel()
+
+	// The id 42 and an unknown field 83 with "blob", the unknown field is lost after decoding.
+	blob := []byte{0x08, 0x2a, 0x9a, 0x05, 0x04, 'b', 'l', 'o', 'b'}
+
+	testCases := []struct {
+		scenario        string
+		payload         []byte
+		expectedItem    *grpctest.Item
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		{
+			scenario:     "matched",
+			payload:      blob,
+			expectedItem: &grpctest.Item{Id: 42},
+		},
+		{
+			scenario:        "not matched",
+			payload:         []byte{0x08, 0x2a},
+			expectedCode:    codes.Internal,
+			expectedMessage: `expected request payload: raw bytes "\b*\x9a\x05\x04blob"`,
+		},
+		{
+			scenario:        "not a proto message",
+			payload:         []byte("opaque blob"),
+			expectedCode:    codes.Internal,
+			expectedMessage: "error unmarshalling request",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			_, d := mockItemServiceServer(grpcmock.NoOpT(),
+				grpcmock.WithRawBytesCodec(),
+				func(s *grpcmock.Server) {
+					s.ExpectUnary(grpcTestServiceGetItem).
+						WithPayload(matcher.RawBytes(blob)).
+						Return(&grpctest.Item{Id: 42})
+				},
+			)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			out := &grpctest.Item{}
+
+			err := grpcmock.InvokeUnary(ctx,
+				grpcTestServiceGetItem,
+				tc.payload, out,
+				grpcmock.WithContextDialer(d),
+				grpcmock.WithInsecure(),
+				grpcmock.WithRawBytesPayload(),
+			)
+
+			if tc.expectedItem == nil {
+				assert.Equal(t, tc.expectedCode, status.Code(err))
+				assert.Contains(t, status.Convert(err).Message(), tc.expectedMessage)
+
+				return
+			}
+
+			require.NoError(t, err)
+			grpcAssert.EqualMessage(t, tc.expectedItem, out)
+		})
+	}
+}
+
+func TestServer_ExpectUnary_WithPayload_RawBytes_NoCodec(t *testing.T) {
 	t.Parallel()
 
 	_, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
-		s.ExpectClientStream(grpcTestServiceCreateItems).
-			WithPayload(func(interface{}) (bool, error) {
-				return false, nil
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithPayload(matcher.RawBytes([]byte{0x08, 0x2a})).
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	_, err := getItem(d, 42)
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "raw bytes are not available")
+}
+
+func TestServer_ExpectUnary_WithMaxHandlerTime(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithMaxHandlerTime(50 * time.Millisecond).
+			After(time.Minute).
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	// The client deadline is longer than the max handler time.
+	actual, err := getItem(d, 42)
+
+	assert.Nil(t, actual)
+	assert.Equal(t, status.Error(codes.DeadlineExceeded, "handler did not finish within 50ms"), err)
+}
+
+func TestServer_HandlerPanics(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		expect   grpcmock.ServerOption
+		invoke   func(d grpcmock.ContextDialer) error
+	}{
+		{
+			scenario: "unary",
+			expect: func(s *grpcmock.Server) {
+				s.ExpectUnary(grpcTestServiceGetItem).
+					Run(func(context.Context, interface{}) (interface{}, error) {
+						panic("item is gone")
+					})
+			},
+			invoke: func(d grpcmock.ContextDialer) error {
+				_, err := getItem(d, 42)
+
+				return err
+			},
+		},
+		{
+			scenario: "unary with max handler time",
+			expect: func(s *grpcmock.Server) {
+				s.ExpectUnary(grpcTestServiceGetItem).
+					WithMaxHandlerTime(time.Second).
+					Run(func(context.Context, interface{}) (interface{}, error) {
+						panic("item is gone")
+					})
+			},
+			invoke: func(d grpcmock.ContextDialer) error {
+				_, err := getItem(d, 42)
+
+				return err
+			},
+		},
+		{
+			scenario: "server stream",
+			expect: func(s *grpcmock.Server) {
+				s.ExpectServerStream(grpcTestServiceListItems).
+					Run(func(context.Context, interface{}, grpc.ServerStream) error {
+						panic("item is gone")
+					})
+			},
+			invoke: func(d grpcmock.ContextDialer) error {
+				_, err := listItems(d)
+
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu        sync.Mutex
+				recovered []interface{}
+			)
+
+			_, d := mockItemServiceServer(grpcmock.NoOpT(), tc.expect, grpcmock.WithPanicHandler(func(p interface{}) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				recovered = append(recovered, p)
+			}))
+
+			err := tc.invoke(d)
+
+			assert.Equal(t, codes.Internal, status.Code(err))
+			assert.Equal(t, "handler panicked: item is gone", status.Convert(err).Message())
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			assert.Equal(t, []interface{}{"item is gone"}, recovered)
+		})
+	}
+}
+
+func TestServer_HandlerPanics_WithTest(t *testing.T) {
+	t.Parallel()
+
+	test := &recordingT{}
+
+	_, d := mockItemServiceServer(test, func(s *grpcmock.Server) {
+		s.WithTest(test).
+			ExpectUnary(grpcTestServiceGetItem).
+			Run(func(context.Context, interface{}) (interface{}, error) {
+				panic("item is gone")
 			})
 	})
 
-	actual, err := createItems(d, &grpctest.Item{Id: 42})
+	_, err := getItem(d, 42)
 
-	expected := `Expected: ClientStream /grpctest.ItemService/CreateItems
-    with payload
-        matches custom expectation
-Actual: ClientStream /grpctest.ItemService/CreateItems
-    with payload
-        [{"id":42}]
-Error: payload does not match expectation, received: [{"id":42}]
-`
+	assert.Equal(t, codes.Internal, status.Code(err))
+	require.Len(t, test.recordedErrors(), 1)
+	assert.Contains(t, test.recordedErrors()[0], "handler panicked: item is gone")
+
+	test.runCleanups()
+}
+
+func TestServer_ExpectUnary_ReturnErr(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		err           error
+		expectedError error
+	}{
+		{
+			scenario:      "status error",
+			err:           status.Error(codes.NotFound, "item not found"),
+			expectedError: status.Error(codes.NotFound, "item not found"),
+		},
+		{
+			scenario:      "unknown status error",
+			err:           status.Error(codes.Unknown, "unknown"),
+			expectedError: status.Error(codes.Unknown, "unknown"),
+		},
+		{
+			scenario:      "generic error",
+			err:           errors.New("item not found"),
+			expectedError: status.Error(codes.Internal, "item not found"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+				s.ExpectUnary(grpcTestServiceGetItem).
+					ReturnErr(tc.err)
+			})
+
+			actual, err := getItem(d, 42)
+
+			assert.Nil(t, actual)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestServer_ExpectUnary_Return_TypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	expected := `response type mismatch: got *grpctest.GetItemRequest, want *grpctest.Item`
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		err := s.ExpectUnary(grpcTestServiceGetItem).
+			Return(&grpctest.GetItemRequest{Id: 42})
+
+		assert.ErrorIs(t, err, grpcErrors.ErrResponseTypeMismatch)
+		assert.EqualError(t, err, expected)
+	})
+
+	// The call fails with the same error.
+	_, err := getItem(d, 42)
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, expected, status.Convert(err).Message())
+}
+
+func TestServer_ExpectUnary_ReturnSequence(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		mockServer    func(s *grpcmock.Server)
+		expectedLast  *grpctest.Item
+		expectedError string
+	}{
+		{
+			scenario: "repeat the last response",
+			mockServer: func(s *grpcmock.Server) {
+				s.ExpectUnary(grpcTestServiceGetItem).Times(4).
+					ReturnSequence(
+						&grpctest.Item{Id: 1},
+						`{"id": 2}`,
+						&grpctest.Item{Id: 3},
+					)
+			},
+			expectedLast: &grpctest.Item{Id: 3},
+		},
+		{
+			scenario: "strict",
+			mockServer: func(s *grpcmock.Server) {
+				s.ExpectUnary(grpcTestServiceGetItem).Times(4).
+					ReturnSequenceStrict(
+						&grpctest.Item{Id: 1},
+						`{"id": 2}`,
+						&grpctest.Item{Id: 3},
+					)
+			},
+			expectedError: "rpc error: code = Internal desc = sequence is exhausted",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			_, d := mockItemServiceServer(t, tc.mockServer)
+
+			for id := int32(1); id <= 3; id++ {
+				actual, err := getItem(d, 42)
+
+				grpcAssert.EqualMessage(t, &grpctest.Item{Id: id}, actual)
+				assert.NoError(t, err)
+			}
+
+			actual, err := getItem(d, 42)
+
+			if tc.expectedError == "" {
+				grpcAssert.EqualMessage(t, tc.expectedLast, actual)
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestServer_ExpectUnary_WithReturnHeader(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithReturnHeader("x-request-id", "42").
+			WithReturnHeader("x-tag", "foo").
+			WithReturnHeader("x-tag", "bar").
+			WithReturnTrailer("x-cache", "hit").
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	var header, trailer metadata.MD
+
+	actual := &grpctest.Item{}
+
+	err := grpcmock.InvokeUnary(context.Background(), grpcTestServiceGetItem, &grpctest.GetItemRequest{Id: 42}, actual,
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+		grpcmock.WithHeaderCapture(&header),
+		grpcmock.WithTrailerCapture(&trailer),
+	)
+
+	assert.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, actual)
+	assert.Equal(t, []string{"42"}, header.Get("x-request-id"))
+	assert.Equal(t, []string{"foo", "bar"}, header.Get("x-tag"))
+	assert.Equal(t, []string{"hit"}, trailer.Get("x-cache"))
+}
+
+func TestServer_ExpectUnary_ReturnTrailersOnly(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithReturnHeader("x-request-id", "42").
+			ReturnTrailersOnly(codes.Unavailable, "server is not ready", metadata.Pairs("retry-after", "1"))
+	})
+
+	var header, trailer metadata.MD
+
+	err := grpcmock.InvokeUnary(context.Background(), grpcTestServiceGetItem, &grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+		grpcmock.WithHeaderCapture(&header),
+		grpcmock.WithTrailerCapture(&trailer),
+	)
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, "server is not ready", status.Convert(err).Message())
+	assert.Empty(t, header)
+	assert.Equal(t, []string{"1"}, trailer.Get("retry-after"))
+}
+
+func TestServer_ExpectUnary_ReturnJSON(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			ReturnJSON(`{"id": 42, "name": "Foobar", "createTime": "2020-01-02T03:04:05Z"}`)
+	})
+
+	actual, err := getItem(d, 42)
+
+	expected := &grpctest.Item{
+		Id:         42,
+		Name:       "Foobar",
+		CreateTime: timestamppb.New(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+	}
+
+	assert.NoError(t, err)
+	grpcAssert.EqualMessage(t, expected, actual)
+}
+
+func TestServer_ExpectUnary_WithAuthority(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		authority     string
+		expectedError bool
+	}{
+		{
+			scenario:  "matched",
+			authority: "api.example.com",
+		},
+		{
+			scenario:      "mismatched",
+			authority:     "localhost",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			_, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
+				s.ExpectUnary(grpcTestServiceGetItem).
+					WithHeaderMatcher(matcher.Authority("api.example.com")).
+					Return(&grpctest.Item{Id: 42})
+			})
+
+			out := &grpctest.Item{}
+
+			err := grpcmock.InvokeUnary(context.Background(), grpcTestServiceGetItem, &grpctest.GetItemRequest{Id: 42}, out,
+				grpcmock.WithAuthority(tc.authority),
+				grpcmock.WithContextDialer(d),
+				grpcmock.WithInsecure(),
+			)
+
+			if tc.expectedError {
+				assert.Contains(t, status.Convert(err).Message(), `header ":authority" with value "api.example.com" expected, "localhost" received`)
+			} else {
+				assert.NoError(t, err)
+				grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, out)
+			}
+		})
+	}
+}
+
+func TestServer_ExpectUnary_WithHeaderPresence(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		matcher       matcher.HeaderMatcher
+		header        map[string]string
+		expectedError string
+	}{
+		{
+			scenario: "present",
+			matcher:  matcher.HeaderPresent("authorization"),
+			header:   map[string]string{"authorization": "Bearer token"},
+		},
+		{
+			scenario:      "not present",
+			matcher:       matcher.HeaderPresent("authorization"),
+			expectedError: `header "authorization" expected to be present`,
+		},
+		{
+			scenario: "absent",
+			matcher:  matcher.HeaderAbsent("authorization"),
+		},
+		{
+			scenario:      "credentials leaked",
+			matcher:       matcher.HeaderAbsent("authorization"),
+			header:        map[string]string{"authorization": "Bearer token"},
+			expectedError: `header "authorization" expected to be absent`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			srv, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
+				s.ExpectUnary(grpcTestServiceGetItem).
+					WithHeaderMatcher(tc.matcher).
+					Return(&grpctest.Item{Id: 42})
+			})
+
+			out := &grpctest.Item{}
+
+			err := grpcmock.InvokeUnary(context.Background(), grpcTestServiceGetItem, &grpctest.GetItemRequest{Id: 42}, out,
+				grpcmock.WithHeaders(tc.header),
+				grpcmock.WithContextDialer(d),
+				grpcmock.WithInsecure(),
+			)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.NoError(t, srv.ExpectationsWereMet())
+				grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, out)
+
+				return
+			}
+
+			assert.Equal(t, codes.Internal, status.Code(err))
+			assert.Contains(t, status.Convert(err).Message(), tc.expectedError)
+			assert.Error(t, srv.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestServer_ExpectUnary_WithHeaderPresence_WrongValue(t *testing.T) {
+	t.Parallel()
+
+	srv, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithHeaderMatcher(matcher.HeaderAbsent("authorization")).
+			WithHeader("locale", "en-US").
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	out := &grpctest.Item{}
+
+	err := grpcmock.InvokeUnary(context.Background(), grpcTestServiceGetItem, &grpctest.GetItemRequest{Id: 42}, out,
+		grpcmock.WithHeader("locale", "vi-VN"),
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), `header "locale" with value "en-US" expected, "vi-VN" received`)
+	assert.Error(t, srv.ExpectationsWereMet())
+}
+
+func TestServer_WithStrictMode(t *testing.T) {
+	t.Parallel()
+
+	strictT := &recordingT{}
+
+	_, d := mockItemServiceServer(grpcmock.NoOpT(), grpcmock.WithStrictMode(strictT))
+
+	out := &grpctest.Item{}
+
+	err := grpcmock.InvokeUnary(context.Background(), grpcTestServiceGetItem, &grpctest.GetItemRequest{Id: 42}, out,
+		grpcmock.WithHeader("locale", "en-US"),
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	expectedError := `unexpected call: Unary /grpctest.ItemService/GetItem
+    with header:
+        locale: en-US
+    with payload
+        {"id":42}
+Error: rpc error: code = FailedPrecondition desc = unexpected request received: "/grpctest.ItemService/GetItem", payload: {"id":42}`
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, []string{expectedError}, strictT.recordedErrors())
+
+	// The calls after the test finishes are not reported.
+	strictT.runCleanups()
+
+	_, err = getItem(d, 42)
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Len(t, strictT.recordedErrors(), 1)
+}
+
+func TestServer_WithStrictMode_Mismatched(t *testing.T) {
+	t.Parallel()
+
+	strictT := &recordingT{}
+
+	_, d := mockItemServiceServer(grpcmock.NoOpT(),
+		grpcmock.WithStrictMode(strictT),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary(grpcTestServiceGetItem).
+				WithPayload(&grpctest.GetItemRequest{Id: 41}).
+				Return(&grpctest.Item{Id: 41})
+		},
+	)
+
+	_, err := getItem(d, 42)
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	errs := strictT.recordedErrors()
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "unexpected call: Unary /grpctest.ItemService/GetItem")
+	assert.Contains(t, errs[0], `expected request payload: {"id":41}, received: {"id":42}`)
+}
+
+func TestServer_WithStrictMode_ReportOnce(t *testing.T) {
+	t.Parallel()
+
+	strictT := &recordingT{}
+
+	s, d := mockItemServiceServer(strictT,
+		grpcmock.WithStrictMode(strictT),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary(grpcTestServiceGetItem).
+				WithPayload(&grpctest.GetItemRequest{Id: 41}).
+				Return(&grpctest.Item{Id: 41})
+		},
+	)
+
+	defer strictT.runCleanups()
+
+	// The mismatch is not reported again by the test of the server.
+	s.WithTest(strictT)
+
+	_, err := getItem(d, 42)
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	errs := strictT.recordedErrors()
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0], "unexpected call: Unary /grpctest.ItemService/GetItem")
+}
+
+func TestServer_ExpectUnary_WrongPayload(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithHeader("locale", "en-US").
+			WithPayload(&grpctest.GetItemRequest{Id: 1})
+	})
+
+	actual, err := getItem(d, 42)
+
+	expectedErrorMessage := `Expected: Unary /grpctest.ItemService/GetItem
+    with header:
+        locale: en-US
+    with payload using matcher.JSONMatcher
+        {"id":1}
+Actual: Unary /grpctest.ItemService/GetItem
+    with header:
+        locale: en-US
+    with payload
+        {"id":42}
+Error: expected request payload: {"id":1}, received: {"id":42}
+`
+	expectedError := status.Error(codes.Internal, expectedErrorMessage)
+
+	assert.Nil(t, actual)
+	assert.Equal(t, expectedError, err)
+}
+
+func TestServer_ExpectServerStream_Unexpected(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t)
+
+	actual, err := listItems(d)
+
+	expectedError := `/grpctest.ItemService/ListItems: recv response: rpc error: code = FailedPrecondition desc = unexpected request received: "/grpctest.ItemService/ListItems", payload: {}`
+
+	assert.Nil(t, actual)
+	assert.EqualError(t, err, expectedError)
+}
+
+func TestServer_ExpectServerStream_Panic(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		method   string
+		expected string
+	}{
+		{
+			scenario: "not found",
+			method:   "unknown",
+			expected: `method not found: /unknown`,
+		},
+		{
+			scenario: "unary",
+			method:   grpcTestServiceGetItem,
+			expected: `method is not server-stream: grpctest.ItemService/GetItem`,
+		},
+		{
+			scenario: "client stream",
+			method:   grpcTestServiceCreateItems,
+			expected: `method is not server-stream: grpctest.ItemService/CreateItems`,
+		},
+		{
+			scenario: "bidirectional stream",
+			method:   grpcTestServiceTransformItems,
+			expected: `method is not server-stream: grpctest.ItemService/TransformItems`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			assert.PanicsWithError(t, tc.expected, func() {
+				mockItemServiceServer(t, func(s *grpcmock.Server) {
+					s.ExpectServerStream(tc.method)
+				})
+			})
+		})
+	}
+}
+
+func TestServer_ExpectServerStream_Success(t *testing.T) {
+	t.Parallel()
+
+	expected := testSrv.DefaultItems()
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectServerStream(grpcTestServiceListItems).
+			ReturnStream().
+			SendMany(expected)
+	})
+
+	actual, err := listItems(d)
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, len(expected))
+
+	for i := 0; i < len(expected); i++ {
+		grpcAssert.EqualMessage(t, expected[i], actual[i])
+	}
+}
+
+func TestServer_ExpectServerStream_ClientCancelled(t *testing.T) {
+	t.Parallel()
+
+	s, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
+		s.ExpectServerStream(grpcTestServiceListItems).
+			ReturnStream().
+			Send(&grpctest.Item{Id: 41}).
+			WaitFor(time.Hour).
+			Send(&grpctest.Item{Id: 42})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := grpcmock.InvokeServerStream(ctx, grpcTestServiceListItems,
+		&grpctest.ListItemsRequest{},
+		func(s grpc.ClientStream) error {
+			defer cancel()
+
+			return s.RecvMsg(&grpctest.Item{})
+		},
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	assert.NoError(t, err)
+
+	// The server waits for the handlers to finish before closing, so it must not be blocked by the WaitFor().
+	startTime := time.Now()
+
+	assert.NoError(t, s.Close())
+	assert.Less(t, time.Since(startTime), 5*time.Second)
+}
+
+func TestServer_ExpectServerStream_ReturnAndClose(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectServerStream(grpcTestServiceListItems).
+			ReturnAndClose([]interface{}{&grpctest.Item{Id: 41}, `{"id": 42}`})
+	})
+
+	actual, err := listItems(d)
+
+	expected := []*grpctest.Item{{Id: 41}, {Id: 42}}
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, len(expected))
+
+	for i := range expected {
+		grpcAssert.EqualMessage(t, expected[i], actual[i])
+	}
+}
+
+func TestServer_ExpectServerStream_ReturnFromReader(t *testing.T) {
+	t.Parallel()
+
+	expected := []*grpctest.Item{
+		{Id: 40, Name: "Item #40"},
+		{Id: 41, Name: "Item #41"},
+		{Id: 42, Name: "Item #42"},
+	}
+
+	var data []byte
+
+	for _, item := range expected {
+		b, err := proto.Marshal(item)
+		require.NoError(t, err)
+
+		size := make([]byte, binary.MaxVarintLen64)
+		n := binary.PutUvarint(size, uint64(len(b)))
+
+		data = append(data, size[:n]...)
+		data = append(data, b...)
+	}
+
+	path := filepath.Join(t.TempDir(), "items.bin")
+
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	f, err := os.Open(filepath.Clean(path))
+	require.NoError(t, err)
+
+	defer f.Close() // nolint: errcheck
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectServerStream(grpcTestServiceListItems).
+			ReturnFromReader(f, nil)
+	})
+
+	actual, err := listItems(d)
+
+	require.NoError(t, err)
+	require.Len(t, actual, len(expected))
+
+	for i := range expected {
+		grpcAssert.EqualMessage(t, expected[i], actual[i])
+	}
+}
+
+func TestServer_ExpectServerStream_ReturnGen(t *testing.T) {
+	t.Parallel()
+
+	const numItems = 1000
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectServerStream(grpcTestServiceListItems).
+			ReturnGen(numItems, func(i int) interface{} {
+				return &grpctest.Item{Id: int32(i)}
+			})
+	})
+
+	actual, err := listItems(d)
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, numItems)
+
+	for i := range actual {
+		assert.Equal(t, int32(i), actual[i].Id)
+	}
+}
+
+func TestServer_ExpectServerStream_ReturnGen_TypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectServerStream(grpcTestServiceListItems).
+			ReturnGen(2, func(i int) interface{} {
+				if i == 0 {
+					return `{"id": 42}`
+				}
+
+				return &grpctest.GetItemRequest{Id: 43}
+			})
+	})
+
+	actual := make([]*grpctest.Item, 0)
+
+	err := grpcmock.InvokeServerStream(context.Background(), grpcTestServiceListItems,
+		&grpctest.ListItemsRequest{},
+		grpcmock.RecvAll(&actual),
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "unsupported data type: got *grpctest.GetItemRequest, want grpctest.Item", status.Convert(err).Message())
+	assert.Len(t, actual, 1)
+}
+
+func TestServer_ExpectServerStream_RecvAll_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	sent := make(chan struct{})
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectServerStream(grpcTestServiceListItems).
+			Run(func(ctx context.Context, _ interface{}, s grpc.ServerStream) error {
+				if err := s.SendMsg(&grpctest.Item{Id: 42}); err != nil {
+					return err
+				}
+
+				close(sent)
+
+				<-ctx.Done()
+
+				return ctx.Err()
+			})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-sent
+		cancel()
+	}()
+
+	actual := make([]*grpctest.Item, 0)
+	start := time.Now()
+
+	err := grpcmock.InvokeServerStream(ctx, grpcTestServiceListItems,
+		&grpctest.ListItemsRequest{},
+		grpcmock.RecvAll(&actual),
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestServer_ExpectUnary_NewMessagePerCall(t *testing.T) {
+	t.Parallel()
+
+	var received []*grpctest.GetItemRequest
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			UnlimitedTimes().
+			ReturnFunc(func(_ context.Context, in interface{}) (interface{}, error) {
+				req := in.(*grpctest.GetItemRequest) // nolint: errcheck
+
+				received = append(received, req)
+
+				if req.Id == 2 {
+					return &grpctest.Item{Id: req.Id, Name: "Foobar"}, nil
+				}
+
+				return &grpctest.Item{Id: req.Id}, nil
+			})
+	})
+
+	for id := int32(1); id <= 3; id++ {
+		actual, err := getItem(d, id)
+
+		require.NoError(t, err)
+
+		if id == 2 {
+			grpcAssert.EqualMessage(t, &grpctest.Item{Id: id, Name: "Foobar"}, actual)
+		} else {
+			grpcAssert.EqualMessage(t, &grpctest.Item{Id: id}, actual)
+		}
+	}
+
+	// The requests that are kept by the handler are not reused by the next calls.
+	require.Len(t, received, 3)
+
+	for i, req := range received {
+		grpcAssert.EqualMessage(t, &grpctest.GetItemRequest{Id: int32(i + 1)}, req)
+	}
+}
+
+func BenchmarkServer_ExpectUnary(b *testing.B) {
+	_, d := mockItemServiceServer(b, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).UnlimitedTimes().
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	// The connection is reused, so only the calls are measured.
+	i, err := grpcmock.NewInvoker("bufconn",
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	defer i.Close() // nolint: errcheck
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		if err := i.Unary(context.Background(), grpcTestServiceGetItem, &grpctest.GetItemRequest{Id: 42}, &grpctest.Item{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestServer_ExpectUnary_ReturnConnectionError(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			ReturnConnectionError()
+
+		s.ExpectUnary(grpcTestServiceGetItem).
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	actual, err := getItem(d, 42)
+
+	assert.Nil(t, actual)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.NotEqual(t, "connection reset", status.Convert(err).Message())
+
+	// The client reconnects for the next call.
+	actual, err = getItem(d, 42)
+
+	assert.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, actual)
+}
+
+func TestServer_ExpectServerStream_ReturnErrorAfter(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectServerStream(grpcTestServiceListItems).
+			ReturnErrorAfter([]interface{}{&grpctest.Item{Id: 41}, &grpctest.Item{Id: 42}}, codes.Unavailable, "server is going away")
+	})
+
+	actual := make([]*grpctest.Item, 0)
+
+	err := grpcmock.InvokeServerStream(context.Background(), grpcTestServiceListItems,
+		&grpctest.ListItemsRequest{},
+		grpcmock.RecvAll(&actual),
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	expected := []*grpctest.Item{{Id: 41}, {Id: 42}}
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, "server is going away", status.Convert(err).Message())
+	assert.Len(t, actual, len(expected))
+
+	for i := range expected {
+		grpcAssert.EqualMessage(t, expected[i], actual[i])
+	}
+}
+
+func TestServer_ExpectServerStream_ReturnHeaderThenError(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectServerStream(grpcTestServiceListItems).
+			ReturnHeaderThenError(metadata.Pairs("x-request-id", "42"), codes.Unavailable, "server is going away")
+	})
+
+	var header metadata.MD
+
+	actual := make([]*grpctest.Item, 0)
+
+	err := grpcmock.InvokeServerStream(context.Background(), grpcTestServiceListItems,
+		&grpctest.ListItemsRequest{},
+		grpcmock.RecvAll(&actual),
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+		grpcmock.WithStreamHeaderCapture(&header),
+	)
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, "server is going away", status.Convert(err).Message())
+	assert.Equal(t, []string{"42"}, header.Get("x-request-id"))
+	assert.Empty(t, actual)
+}
+
+func TestServer_ExpectClientStream_Unexpected(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t)
+
+	actual, err := createItems(d, &grpctest.Item{Id: 42})
+
+	expectedError := `/grpctest.ItemService/CreateItems: recv response: rpc error: code = FailedPrecondition desc = unexpected request received: "/grpctest.ItemService/CreateItems", payload: [{"id":42}]`
+
+	assert.Nil(t, actual)
+	assert.EqualError(t, err, expectedError)
+}
+
+func TestServer_ExpectClientStream_Panic(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		method   string
+		expected string
+	}{
+		{
+			scenario: "not found",
+			method:   "unknown",
+			expected: `method not found: /unknown`,
+		},
+		{
+			scenario: "unary",
+			method:   grpcTestServiceGetItem,
+			expected: `method is not client-stream: grpctest.ItemService/GetItem`,
+		},
+		{
+			scenario: "server stream",
+			method:   grpcTestServiceListItems,
+			expected: `method is not client-stream: grpctest.ItemService/ListItems`,
+		},
+		{
+			scenario: "bidirectional stream",
+			method:   grpcTestServiceTransformItems,
+			expected: `method is not client-stream: grpctest.ItemService/TransformItems`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			assert.PanicsWithError(t, tc.expected, func() {
+				mockItemServiceServer(t, func(s *grpcmock.Server) {
+					s.ExpectClientStream(tc.method)
+				})
+			})
+		})
+	}
+}
+
+func TestServer_ExpectClientStream_Success(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectClientStream(grpcTestServiceCreateItems).
+			WithHeader(`locale`, `en-US`).
+			WithPayload([]*grpctest.Item{{Id: 42}}).
+			Run(func(_ context.Context, s grpc.ServerStream) (interface{}, error) {
+				cnt := int64(0)
+
+				for {
+					err := s.RecvMsg(&grpctest.Item{})
+
+					if errors.Is(err, io.EOF) {
+						break
+					}
+
+					if err != nil {
+						return nil, err
+					}
+
+					cnt++
+				}
+
+				return &grpctest.CreateItemsResponse{NumItems: cnt}, nil
+			})
+	})
+
+	actual, err := createItems(d, &grpctest.Item{Id: 42})
+	expected := &grpctest.CreateItemsResponse{NumItems: 1}
+
+	assert.NoError(t, err)
+	grpcAssert.EqualMessage(t, expected, actual)
+}
+
+func TestServer_ExpectClientStream_CustomMatcher_Success(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectClientStream(grpcTestServiceCreateItems).
+			WithHeader(`locale`, `en-US`).
+			WithPayload(grpcmock.MatchClientStreamMsgCount(1)).
+			Run(func(_ context.Context, s grpc.ServerStream) (interface{}, error) {
+				cnt := int64(0)
+
+				for {
+					err := s.RecvMsg(&grpctest.Item{})
+
+					if errors.Is(err, io.EOF) {
+						break
+					}
+
+					if err != nil {
+						return nil, err
+					}
+
+					cnt++
+				}
+
+				return &grpctest.CreateItemsResponse{NumItems: cnt}, nil
+			})
+	})
+
+	actual, err := createItems(d, &grpctest.Item{Id: 42})
+	expected := &grpctest.CreateItemsResponse{NumItems: 1}
+
+	assert.NoError(t, err)
+	grpcAssert.EqualMessage(t, expected, actual)
+}
+
+func TestServer_ExpectClientStream_WithPayloads(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		items         []*grpctest.Item
+		expectedError bool
+	}{
+		{
+			scenario: "same sequence",
+			items:    []*grpctest.Item{{Id: 41}, {Id: 42}, {Id: 43}},
+		},
+		{
+			scenario:      "reordered sequence",
+			items:         []*grpctest.Item{{Id: 42}, {Id: 41}, {Id: 43}},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			_, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
+				s.ExpectClientStream(grpcTestServiceCreateItems).
+					WithPayloads(matcher.SequenceEq(
+						&grpctest.Item{Id: 41},
+						&grpctest.Item{Id: 42},
+						&grpctest.Item{Id: 43},
+					)).
+					Return(&grpctest.CreateItemsResponse{NumItems: 3})
+			})
+
+			actual, err := createItems(d, tc.items...)
+
+			if tc.expectedError {
+				assert.Nil(t, actual)
+				assert.Equal(t, codes.Internal, status.Code(err))
+				assert.Contains(t, status.Convert(err).Message(), `expected request payload: [{"id":41},{"id":42},{"id":43}]`)
+			} else {
+				assert.NoError(t, err)
+				grpcAssert.EqualMessage(t, &grpctest.CreateItemsResponse{NumItems: 3}, actual)
+			}
+		})
+	}
+}
+
+func TestServer_ExpectClientStream_MatchMsgCount_Mismatched(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
+		s.ExpectClientStream(grpcTestServiceCreateItems).
+			WithPayload(grpcmock.MatchClientStreamMsgCount(2))
+	})
+
+	actual, err := createItems(d, &grpctest.Item{Id: 42})
+
+	expected := `Expected: ClientStream /grpctest.ItemService/CreateItems
+    with payload
+        has 2 message(s)
+Actual: ClientStream /grpctest.ItemService/CreateItems
+    with payload
+        [{"id":42}]
+Error: expected request payload: has 2 message(s), received: [{"id":42}]
+`
+
+	assert.Nil(t, actual)
+	assert.Error(t, err)
+	assert.Equal(t, status.Convert(err).Message(), expected)
+}
+
+func TestServer_ExpectClientStream_ReturnDefault(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectClientStream(grpcTestServiceCreateItems).
+			ReturnDefault()
+	})
+
+	actual, err := createItems(d, &grpctest.Item{Id: 42})
+
+	assert.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.CreateItemsResponse{}, actual)
+}
+
+func TestServer_ExpectClientStream_WithMessageCount(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		min           int
+		max           int
+		items         []*grpctest.Item
+		expectedError string
+	}{
+		{
+			scenario:      "under count",
+			min:           2,
+			max:           3,
+			items:         []*grpctest.Item{{Id: 41}},
+			expectedError: "unexpected number of messages, got 1, want between 2 and 3",
+		},
+		{
+			scenario: "in range",
+			min:      2,
+			max:      3,
+			items:    []*grpctest.Item{{Id: 41}, {Id: 42}},
+		},
+		{
+			scenario:      "over count",
+			min:           2,
+			max:           3,
+			items:         []*grpctest.Item{{Id: 41}, {Id: 42}, {Id: 43}, {Id: 44}},
+			expectedError: "unexpected number of messages, got 4, want between 2 and 3",
+		},
+		{
+			scenario: "unbounded max",
+			min:      1,
+			max:      -1,
+			items:    []*grpctest.Item{{Id: 41}, {Id: 42}, {Id: 43}, {Id: 44}},
+		},
+		{
+			scenario:      "unbounded min",
+			min:           -1,
+			max:           1,
+			items:         []*grpctest.Item{{Id: 41}, {Id: 42}},
+			expectedError: "unexpected number of messages, got 2, want at most 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			_, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
+				s.ExpectClientStream(grpcTestServiceCreateItems).
+					WithMessageCount(tc.min, tc.max).
+					Run(func(_ context.Context, srv grpc.ServerStream) (interface{}, error) {
+						out := make([]*grpctest.Item, 0)
+
+						if err := stream.RecvAll(srv, &out); err != nil {
+							return nil, err
+						}
+
+						return &grpctest.CreateItemsResponse{NumItems: int64(len(out))}, nil
+					})
+			})
+
+			actual, err := createItems(d, tc.items...)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				grpcAssert.EqualMessage(t, &grpctest.CreateItemsResponse{NumItems: int64(len(tc.items))}, actual)
+			} else {
+				assert.Nil(t, actual)
+				assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+				assert.Equal(t, tc.expectedError, status.Convert(err).Message())
+			}
+		})
+	}
+}
+
+func TestServer_ExpectClientStream_WithMessageCount_Panic(t *testing.T) {
+	t.Parallel()
+
+	s := grpcmock.NewServer(grpcmock.RegisterService(grpctest.RegisterItemServiceServer))
+
+	assert.Panics(t, func() {
+		s.ExpectClientStream(grpcTestServiceCreateItems).WithMessageCount(3, 2)
+	})
+}
+
+func TestServer_ExpectClientStream_CustomStreamMatcher_Mismatched(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
+		s.ExpectClientStream(grpcTestServiceCreateItems).
+			WithPayload(func(interface{}) (bool, error) {
+				return false, nil
+			})
+	})
+
+	actual, err := createItems(d, &grpctest.Item{Id: 42})
+
+	expected := `Expected: ClientStream /grpctest.ItemService/CreateItems
+    with payload
+        matches custom expectation
+Actual: ClientStream /grpctest.ItemService/CreateItems
+    with payload
+        [{"id":42}]
+Error: payload does not match expectation, received: [{"id":42}]
+`
+
+	assert.Nil(t, actual)
+	assert.Error(t, err)
+	assert.Equal(t, status.Convert(err).Message(), expected)
+}
+
+func TestServer_ExpectBidirectionalStream_Unexpected(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t)
+
+	actual, err := transformItems(d, &grpctest.Item{Id: 42})
+
+	expectedError := `rpc error: code = FailedPrecondition desc = unexpected request received: "/grpctest.ItemService/TransformItems"`
+
+	assert.Nil(t, actual)
+	assert.EqualError(t, err, expectedError)
+}
+
+func TestServer_ExpectBidirectionalStream_Panic(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		method   string
+		expected string
+	}{
+		{
+			scenario: "not found",
+			method:   "unknown",
+			expected: `method not found: /unknown`,
+		},
+		{
+			scenario: "unary",
+			method:   grpcTestServiceGetItem,
+			expected: `method is not bidirectional-stream: grpctest.ItemService/GetItem`,
+		},
+		{
+			scenario: "client stream",
+			method:   grpcTestServiceCreateItems,
+			expected: `method is not bidirectional-stream: grpctest.ItemService/CreateItems`,
+		},
+		{
+			scenario: "server stream",
+			method:   grpcTestServiceListItems,
+			expected: `method is not bidirectional-stream: grpctest.ItemService/ListItems`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			assert.PanicsWithError(t, tc.expected, func() {
+				mockItemServiceServer(t, func(s *grpcmock.Server) {
+					s.ExpectBidirectionalStream(tc.method)
+				})
+			})
+		})
+	}
+}
+
+func TestServer_ExpectBidirectionalStream_Success(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectBidirectionalStream(grpcTestServiceTransformItems).
+			WithHeader(`locale`, `en-US`).
+			Run(func(ctx context.Context, s grpc.ServerStream) error {
+				for {
+					item := &grpctest.Item{}
+					err := s.RecvMsg(item)
+
+					if errors.Is(err, io.EOF) {
+						return nil
+					}
+
+					if err != nil {
+						return err
+					}
+
+					item.Name = fmt.Sprintf("Modified #%d", item.Id)
+
+					if err := s.SendMsg(item); err != nil {
+						return err
+					}
+				}
+			})
+	})
+
+	actual, err := transformItems(d, &grpctest.Item{Id: 40}, &grpctest.Item{Id: 41}, &grpctest.Item{Id: 42})
+	expected := []*grpctest.Item{
+		{Id: 40, Name: "Modified #40"},
+		{Id: 41, Name: "Modified #41"},
+		{Id: 42, Name: "Modified #42"},
+	}
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, len(expected))
+
+	for i := 0; i < len(expected); i++ {
+		grpcAssert.EqualMessage(t, expected[i], actual[i])
+	}
+}
+
+func TestServer_ExpectBidirectionalStream_Script(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario        string
+		items           []*grpctest.Item
+		expectedResult  []*grpctest.Item
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		{
+			scenario:       "success",
+			items:          []*grpctest.Item{{Id: 41}, {Id: 42}},
+			expectedResult: []*grpctest.Item{{Id: 41, Name: "Foobar"}},
+			expectedCode:   codes.OK,
+		},
+		{
+			scenario:        "first message mismatched",
+			items:           []*grpctest.Item{{Id: 40}, {Id: 42}},
+			expectedCode:    codes.FailedPrecondition,
+			expectedMessage: `step #0: expected message: {"id":41}, received: {"id":40}`,
+		},
+		{
+			scenario:        "last message mismatched",
+			items:           []*grpctest.Item{{Id: 41}, {Id: 43}},
+			expectedCode:    codes.FailedPrecondition,
+			expectedMessage: `step #2: expected message: field "id" (42), received: {"id":43}`,
+		},
+		{
+			scenario:        "missing message",
+			items:           []*grpctest.Item{{Id: 41}},
+			expectedCode:    codes.FailedPrecondition,
+			expectedMessage: `step #2: expected message: field "id" (42), received: EOF`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+				s.ExpectBidirectionalStream(grpcTestServiceTransformItems).
+					Script(
+						request.Recv(&grpctest.Item{Id: 41}),
+						request.Send(&grpctest.Item{Id: 41, Name: "Foobar"}),
+						request.Recv(matcher.Field("id", goMatcher.Exact(int32(42)))),
+					)
+			})
+
+			actual, err := transformItems(d, tc.items...)
+
+			assert.Equal(t, tc.expectedCode, status.Code(err))
+
+			if tc.expectedCode == codes.OK {
+				require.Len(t, actual, len(tc.expectedResult))
+
+				for i := range tc.expectedResult {
+					grpcAssert.EqualMessage(t, tc.expectedResult[i], actual[i])
+				}
+			} else {
+				assert.Equal(t, tc.expectedMessage, status.Convert(err).Message())
+			}
+		})
+	}
+}
+
+func TestServer_ExpectBidirectionalStream_Echo(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectBidirectionalStream(grpcTestServiceTransformItems).
+			Echo()
+	})
+
+	expected := []*grpctest.Item{
+		{Id: 40, Name: "Item #40"},
+		{Id: 41, Name: "Item #41"},
+		{Id: 42, Name: "Item #42"},
+	}
+
+	actual, err := transformItems(d, expected...)
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, len(expected))
+
+	for i := range expected {
+		grpcAssert.EqualMessage(t, expected[i], actual[i])
+	}
+}
+
+func TestServer_ExpectBidirectionalStream_Echo_SendAndRecvAll(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectBidirectionalStream(grpcTestServiceTransformItems).
+			Echo()
+	})
+
+	expected := make([]*grpctest.Item, 1000)
+
+	for i := range expected {
+		expected[i] = &grpctest.Item{Id: int32(i), Name: strings.Repeat("x", 1024)}
+	}
+
+	actual, err := transformItems(d, expected...)
+
+	require.NoError(t, err)
+	require.Len(t, actual, len(expected))
+
+	for i := range expected {
+		grpcAssert.EqualMessage(t, expected[i], actual[i])
+	}
+}
+
+func TestServer_ExpectBidirectionalStream_SendAndRecvAll_ServerError(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectBidirectionalStream(grpcTestServiceTransformItems).
+			Run(func(_ context.Context, s grpc.ServerStream) error {
+				if err := s.RecvMsg(&grpctest.Item{}); err != nil {
+					return err
+				}
+
+				return status.Error(codes.ResourceExhausted, "too many items")
+			})
+	})
+
+	items := make([]*grpctest.Item, 1000)
+
+	for i := range items {
+		items[i] = &grpctest.Item{Id: int32(i), Name: strings.Repeat("x", 1024)}
+	}
+
+	_, err := transformItems(d, items...)
+
+	assert.Equal(t, status.Error(codes.ResourceExhausted, "too many items"), err)
+}
+
+func TestServer_MultipleServices_SameMethodName(t *testing.T) {
+	t.Parallel()
+
+	newGetMethod := func(serviceName string) service.Method {
+		return service.Method{
+			ServiceName: serviceName,
+			MethodName:  "Get",
+			MethodType:  service.TypeUnary,
+			Input:       &grpctest.GetItemRequest{},
+			Output:      &grpctest.Item{},
+		}
+	}
+
+	s, d := grpcmock.MockServerWithBufConn(
+		grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+		grpcmock.RegisterServiceFromMethods(
+			newGetMethod("grpctest.AuthService"),
+			newGetMethod("grpctest.DataService"),
+		),
+		grpcmock.WithPlanner(grpcPlanner.FirstMatch()),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary("grpctest.DataService/Get").
+				WithPayload(&grpctest.GetItemRequest{Id: 42}).
+				Return(&grpctest.Item{Id: 42, Name: "data"})
+
+			s.ExpectUnary("grpctest.AuthService/Get").
+				WithPayload(&grpctest.GetItemRequest{Id: 42}).
+				Return(&grpctest.Item{Id: 42, Name: "auth"})
+		},
+	)(t)
+
+	for _, tc := range []struct {
+		method   string
+		expected *grpctest.Item
+	}{
+		{method: "grpctest.AuthService/Get", expected: &grpctest.Item{Id: 42, Name: "auth"}},
+		{method: "grpctest.DataService/Get", expected: &grpctest.Item{Id: 42, Name: "data"}},
+	} {
+		actual := &grpctest.Item{}
+
+		err := grpcmock.InvokeUnary(context.Background(), tc.method, &grpctest.GetItemRequest{Id: 42}, actual,
+			grpcmock.WithContextDialer(d),
+			grpcmock.WithInsecure(),
+		)
+
+		assert.NoError(t, err)
+		grpcAssert.EqualMessage(t, tc.expected, actual)
+	}
+
+	assert.NoError(t, s.ExpectationsWereMet())
+	assert.NotNil(t, grpcmock.FindServerMethod(s, grpcTestServiceGetItem))
+}
+
+func TestServer_RegisterServices_SameMethodName(t *testing.T) {
+	t.Parallel()
+
+	// The item service is registered again as "grpctest.DataService", so both services have a GetItem method.
+	registerDataService := func(s grpc.ServiceRegistrar, srv grpctest.ItemServiceServer) {
+		desc := grpctest.ItemService_ServiceDesc
+		desc.ServiceName = "grpctest.DataService"
+
+		s.RegisterService(&desc, srv)
+	}
+
+	s, d := grpcmock.MockServerWithBufConn(
+		grpcmock.RegisterServices(grpctest.RegisterItemServiceServer, registerDataService),
+		grpcmock.WithPlanner(grpcPlanner.FirstMatch()),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary("grpctest.DataService/GetItem").
+				Return(&grpctest.Item{Id: 42, Name: "data"})
+
+			s.ExpectUnary(grpcTestServiceGetItem).
+				Return(&grpctest.Item{Id: 42, Name: "item"})
+		},
+	)(t)
+
+	for _, tc := range []struct {
+		method   string
+		expected *grpctest.Item
+	}{
+		{method: grpcTestServiceGetItem, expected: &grpctest.Item{Id: 42, Name: "item"}},
+		{method: "grpctest.DataService/GetItem", expected: &grpctest.Item{Id: 42, Name: "data"}},
+	} {
+		actual := &grpctest.Item{}
+
+		err := grpcmock.InvokeUnary(context.Background(), tc.method, &grpctest.GetItemRequest{Id: 42}, actual,
+			grpcmock.WithContextDialer(d),
+			grpcmock.WithInsecure(),
+		)
+
+		assert.NoError(t, err)
+		grpcAssert.EqualMessage(t, tc.expected, actual)
+	}
+
+	assert.NoError(t, s.ExpectationsWereMet())
+	assert.NotNil(t, grpcmock.FindServerMethod(s, "grpctest.DataService/GetItem"))
+}
+
+func TestServer_ExpectationsWereNotMet_LimitedRequest(t *testing.T) {
+	t.Parallel()
+
+	s, d := mockItemServiceServer(grpcmock.NoOpT(),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary(grpcTestServiceGetItem).Twice().
+				WithPayload(&grpctest.GetItemRequest{Id: 42}).
+				Return(&grpctest.Item{Id: 42})
+
+			s.ExpectServerStream(grpcTestServiceListItems)
+		},
+	)
+
+	// 1st request is ok.
+	_, err := getItem(d, 42)
+
+	assert.NoError(t, err)
+
+	expectedErr := `there are remaining expectations that were not met:
+- Unary /grpctest.ItemService/GetItem (called: 1 time(s), remaining: 1 time(s))
+    with payload using matcher.JSONMatcher
+        {"id":42}
+- ServerStream /grpctest.ItemService/ListItems
+`
+	assert.EqualError(t, s.ExpectationsWereMet(), expectedErr)
+
+	// 2nd request is ok.
+	_, err = getItem(d, 42)
+
+	assert.NoError(t, err)
+
+	expectedErr = `there are remaining expectations that were not met:
+- ServerStream /grpctest.ItemService/ListItems
+`
+	assert.EqualError(t, s.ExpectationsWereMet(), expectedErr)
+}
+
+func TestServer_ExpectationsWereNotMet_UnlimitedRequest(t *testing.T) {
+	t.Parallel()
+
+	s, d := mockItemServiceServer(grpcmock.NoOpT(),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary(grpcTestServiceGetItem).UnlimitedTimes().
+				Return(&grpctest.Item{})
+
+			s.ExpectServerStream(grpcTestServiceListItems)
+		},
+	)
+
+	// 1st request is ok.
+	_, err := getItem(d, 42)
+
+	assert.NoError(t, err)
+
+	expectedErr := `there are remaining expectations that were not met:
+- ServerStream /grpctest.ItemService/ListItems
+`
+	assert.EqualError(t, s.ExpectationsWereMet(), expectedErr)
+
+	// 2nd request is ok.
+	_, err = getItem(d, 42)
+
+	assert.NoError(t, err)
+
+	expectedErr = `there are remaining expectations that were not met:
+- ServerStream /grpctest.ItemService/ListItems
+`
+	assert.EqualError(t, s.ExpectationsWereMet(), expectedErr)
+}
+
+func TestServer_ExpectationsWereMet_UnlimitedRequest(t *testing.T) {
+	t.Parallel()
+
+	s, d := mockItemServiceServer(grpcmock.NoOpT(),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary(grpcTestServiceGetItem).UnlimitedTimes().
+				Return(&grpctest.Item{})
+		},
+	)
+
+	_, err := getItem(d, 42)
+
+	assert.NoError(t, err)
+	assert.NoError(t, s.ExpectationsWereMet())
+}
+
+func TestServer_AssertCalls(t *testing.T) {
+	t.Parallel()
+
+	s, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).Twice().
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	ft := &fakeT{T: grpcmock.NoOpT()}
+
+	// Nothing is called.
+	assert.False(t, s.AssertCalled(ft, grpcTestServiceGetItem))
+	assert.True(t, s.AssertNotCalled(ft, grpcTestServiceGetItem))
+	assert.True(t, s.AssertNumberOfCalls(ft, grpcTestServiceGetItem, 0))
+
+	_, err := getItem(d, 42)
+	assert.NoError(t, err)
+
+	_, err = getItem(d, 42)
+	assert.NoError(t, err)
+
+	assert.True(t, s.AssertCalled(ft, grpcTestServiceGetItem))
+	assert.False(t, s.AssertNotCalled(ft, grpcTestServiceGetItem))
+	assert.True(t, s.AssertNumberOfCalls(ft, grpcTestServiceGetItem, 2))
+	assert.False(t, s.AssertNumberOfCalls(ft, grpcTestServiceGetItem, 1))
+	assert.True(t, s.AssertNotCalled(ft, grpcTestServiceListItems))
+
+	expected := []string{
+		`method "/grpctest.ItemService/GetItem" was expected to be called, but it was not`,
+		`method "/grpctest.ItemService/GetItem" was not expected to be called, but it was called 2 time(s)`,
+		`method "/grpctest.ItemService/GetItem" was expected to be called 1 time(s), but it was called 2 time(s)`,
+	}
+
+	assert.Equal(t, expected, ft.errors)
+}
+
+func TestServer_WithServerLogger(t *testing.T) {
+	t.Parallel()
+
+	logger := &fakeLogger{}
+
+	_, d := mockItemServiceServer(t,
+		grpcmock.WithServerLogger(logger),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary(grpcTestServiceGetItem).
+				ReturnError(codes.NotFound, "item not found")
+
+			s.ExpectServerStream(grpcTestServiceListItems).
+				Return([]*grpctest.Item{{Id: 42}})
+		},
+	)
+
+	_, err := getItem(d, 42)
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = listItems(d)
+	require.NoError(t, err)
+
+	expected := [][]interface{}{
+		{"method", "/grpctest.ItemService/GetItem", "type", "Unary", "code", "NotFound", "duration", "<duration>"},
+		{"method", "/grpctest.ItemService/ListItems", "type", "ServerStream", "code", "OK", "duration", "<duration>"},
+	}
+
+	assert.Equal(t, expected, logger.loggedRecords())
+}
+
+func TestServer_AssertCallOrder(t *testing.T) {
+	t.Parallel()
+
+	s, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			Return(&grpctest.Item{Id: 42})
+
+		s.ExpectServerStream(grpcTestServiceListItems).
+			Return([]*grpctest.Item{{Id: 42}})
+
+		s.ExpectUnary(grpcTestServiceGetItem).
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	_, err := getItem(d, 42)
+	require.NoError(t, err)
+
+	_, err = listItems(d)
+	require.NoError(t, err)
+
+	_, err = getItem(d, 42)
+	require.NoError(t, err)
+
+	ft := &fakeT{T: grpcmock.NoOpT()}
+
+	assert.True(t, s.AssertCallOrder(ft, grpcTestServiceGetItem, grpcTestServiceListItems))
+	assert.False(t, s.AssertCallOrder(ft, grpcTestServiceListItems, grpcTestServiceGetItem))
+	assert.False(t, s.AssertCallOrder(ft, grpcTestServiceGetItem, grpcTestServiceCreateItems))
+
+	expected := []string{
+		`method "/grpctest.ItemService/ListItems" was expected to be called before "/grpctest.ItemService/GetItem", but it was not`,
+		`method "/grpctest.ItemService/CreateItems" was expected to be called, but it was not`,
+	}
+
+	assert.Equal(t, expected, ft.errors)
+
+	require.Len(t, s.Calls, 3)
+
+	for i, c := range s.Calls {
+		assert.Equal(t, uint64(i+1), c.Sequence)
+	}
+}
+
+func TestServer_AssertCalls_UnexpectedCall(t *testing.T) {
+	t.Parallel()
+
+	s, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	ft := &fakeT{T: grpcmock.NoOpT()}
+
+	// The call is not expected, but it is still counted.
+	_, err := listItems(d)
+	assert.Error(t, err)
+
+	assert.True(t, s.AssertCalled(ft, grpcTestServiceListItems))
+	assert.False(t, s.AssertNotCalled(ft, grpcTestServiceListItems))
+	assert.True(t, s.AssertNumberOfCalls(ft, grpcTestServiceListItems, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, s.WaitForCalls(ctx, grpcTestServiceListItems, 1))
+
+	expected := []string{
+		`method "/grpctest.ItemService/ListItems" was not expected to be called, but it was called 1 time(s)`,
+	}
+
+	assert.Equal(t, expected, ft.errors)
+}
+
+func TestServer_WaitForCalls(t *testing.T) {
+	t.Parallel()
+
+	const numCalls = 5
+
+	s, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).Times(numCalls).
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	for i := 0; i < numCalls; i++ {
+		go func() {
+			_, _ = getItem(d, 42) // nolint: errcheck
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.WaitForCalls(ctx, grpcTestServiceGetItem, numCalls)
+
+	assert.NoError(t, err)
+	assert.True(t, s.AssertNumberOfCalls(t, grpcTestServiceGetItem, numCalls))
+}
+
+func TestServer_WaitForCalls_ContextDone(t *testing.T) {
+	t.Parallel()
+
+	s, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	_, err := getItem(d, 42)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = s.WaitForCalls(ctx, grpcTestServiceGetItem, 2)
+
+	expected := `method "/grpctest.ItemService/GetItem" was expected to be called 2 time(s), but it was called 1 time(s): context deadline exceeded`
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualError(t, err, expected)
+}
+
+func TestServer_ResetExpectations(t *testing.T) {
+	t.Parallel()
+
+	s := grpcmock.MockUnstartedServer(
+		grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary(grpcTestServiceGetItem).
+				WithPayload(&grpctest.GetItemRequest{Id: 1}).
+				Return(&grpctest.Item{Id: 1, Name: "Foobar"})
+		},
+	)(t)
+
+	s.ResetExpectations()
+
+	assert.NoError(t, s.ExpectationsWereMet())
+}
+
+func TestServer_ResetExpectations_Requests(t *testing.T) {
+	t.Parallel()
+
+	s, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	_, err := getItem(d, 42)
+
+	assert.NoError(t, err)
+	assert.Len(t, s.Requests, 1)
+
+	s.ResetExpectations()
+
+	assert.Empty(t, s.Requests)
+	assert.NoError(t, s.ExpectationsWereMet())
+}
+
+func TestServer_WithUnixSocket(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "grpcmock.sock")
+
+	s := grpcmock.NewServer(
+		grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+		grpcmock.WithUnixSocket(socket),
+		func(s *grpcmock.Server) {
+			s.ExpectUnary(grpcTestServiceGetItem).
+				WithPayload(&grpctest.GetItemRequest{Id: 42}).
+				Return(&grpctest.Item{Id: 42, Name: "Foobar"})
+		},
+	)
+
+	assert.Equal(t, socket, s.Address())
+
+	out := &grpctest.Item{}
+	err := grpcmock.InvokeUnary(context.Background(),
+		fmt.Sprintf("unix://%s/%s", socket, grpcTestServiceGetItem),
+		&grpctest.GetItemRequest{Id: 42}, out,
+		grpcmock.WithInsecure(),
+	)
+
+	expected := &grpctest.Item{Id: 42, Name: "Foobar"}
+
+	assert.NoError(t, err)
+	grpcAssert.EqualMessage(t, expected, out)
+	assert.NoError(t, s.ExpectationsWereMet())
+
+	// The socket file is removed after closing the server.
+	assert.NoError(t, s.Close())
+
+	_, err = os.Stat(socket)
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestServer_Expectations(t *testing.T) {
+	t.Parallel()
+
+	s, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithHeader("locale", "en-US").
+			WithPayload(&grpctest.GetItemRequest{Id: 42}).
+			Times(2).
+			Return(&grpctest.Item{Id: 42})
+
+		s.ExpectServerStream(grpcTestServiceListItems).
+			UnlimitedTimes().
+			Return([]*grpctest.Item{})
+	})
+
+	err := grpcmock.InvokeUnary(context.Background(), grpcTestServiceGetItem,
+		&grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+		grpcmock.WithHeader("locale", "en-US"),
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	require.NoError(t, err)
+
+	expected := []grpcmock.ExpectationSummary{
+		{
+			Method:     "/grpctest.ItemService/GetItem",
+			MethodType: service.TypeUnary,
+			Header:     "locale: en-US",
+			Payload:    `{"id":42}`,
+			Times:      2,
+			Calls:      1,
+		},
+		{
+			Method:     "/grpctest.ItemService/ListItems",
+			MethodType: service.TypeServerStream,
+		},
+	}
+
+	assert.Equal(t, expected, s.Expectations())
+
+	s.ResetExpectations()
+
+	assert.Empty(t, s.Expectations())
+}
+
+func TestServer_LoadExpectations(t *testing.T) {
+	t.Parallel()
+
+	const doc = `
+expectations:
+  - method: grpctest.ItemService/GetItem
+    payload: {"id": 42}
+    response: {"id": 42, "name": "Foobar"}
+  - method: /grpctest.ItemService/ListItems
+    response:
+      - id: 41
+      - id: 42
+        name: Foobar
+`
+
+	s, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		require.NoError(t, s.LoadExpectations(strings.NewReader(doc)))
+	})
+
+	item := &grpctest.Item{}
+	err := grpcmock.InvokeUnary(context.Background(), grpcTestServiceGetItem,
+		&grpctest.GetItemRequest{Id: 42}, item,
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	require.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42, Name: "Foobar"}, item)
+
+	items := make([]*grpctest.Item, 0)
+	err = grpcmock.InvokeServerStream(context.Background(), grpcTestServiceListItems,
+		&grpctest.ListItemsRequest{},
+		grpcmock.RecvAll(&items),
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 41}, items[0])
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42, Name: "Foobar"}, items[1])
+	assert.NoError(t, s.ExpectationsWereMet())
+}
+
+func TestServer_LoadExpectations_Error(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		doc           string
+		expectedError string
+	}{
+		{
+			scenario:      "malformed document",
+			doc:           `expectations: {`,
+			expectedError: "could not decode expectations: yaml: line 1: did not find expected node content",
+		},
+		{
+			scenario: "unknown method",
+			doc: `{"expectations": [
+				{"method": "grpctest.ItemService/GetItem"},
+				{"method": "grpctest.ItemService/DeleteItem"}
+			]}`,
+			expectedError: "could not load expectation #1: method not found: /grpctest.ItemService/DeleteItem",
+		},
+		{
+			scenario:      "invalid payload",
+			doc:           `{"expectations": [{"method": "grpctest.ItemService/GetItem", "payload": {"unknown": 42}}]}`,
+			expectedError: `could not load expectation #0: invalid payload: proto:`,
+		},
+		{
+			scenario:      "response is not a list",
+			doc:           `{"expectations": [{"method": "grpctest.ItemService/ListItems", "response": {"id": 42}}]}`,
+			expectedError: `could not load expectation #0: invalid response: unsupported data type: got map[string]interface {}, want a list`,
+		},
+		{
+			scenario:      "bidirectional stream",
+			doc:           `{"expectations": [{"method": "grpctest.ItemService/TransformItems"}]}`,
+			expectedError: `could not load expectation #0: unsupported method type: /grpctest.ItemService/TransformItems is BidirectionalStream`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			s := grpcmock.NewUnstartedServer(grpcmock.RegisterService(grpctest.RegisterItemServiceServer))
+
+			err := s.LoadExpectations(strings.NewReader(tc.doc))
+
+			// The protojson errors are not stable, so only the prefix is checked.
+			assert.ErrorContains(t, err, tc.expectedError)
+			// Nothing is added if the document is invalid.
+			assert.NoError(t, s.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestServer_ExpectFromGolden(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		require.NoError(t, s.ExpectFromGolden(grpcTestServiceGetItem, "resources/fixtures/get_item"))
+	})
+
+	actual, err := getItem(d, 42)
+
+	require.NoError(t, err)
+	grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42, Locale: "en-US", Name: "Foobar"}, actual)
+}
+
+func TestServer_ExpectFromGolden_Mismatched(t *testing.T) {
+	t.Parallel()
+
+	_, d := mockItemServiceServer(grpcmock.NoOpT(), func(s *grpcmock.Server) {
+		require.NoError(t, s.ExpectFromGolden(grpcTestServiceGetItem, "resources/fixtures/get_item"))
+	})
+
+	actual, err := getItem(d, 41)
+
+	assert.Nil(t, actual)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "Error: expected request payload: {\"id\":42}")
+}
+
+func TestServer_ExpectFromGolden_Error(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		method        string
+		path          string
+		expectedError string
+	}{
+		{
+			scenario:      "unknown method",
+			method:        "grpctest.ItemService/DeleteItem",
+			path:          "resources/fixtures/get_item",
+			expectedError: "method not found: /grpctest.ItemService/DeleteItem",
+		},
+		{
+			scenario:      "not a unary method",
+			method:        grpcTestServiceListItems,
+			path:          "resources/fixtures/get_item",
+			expectedError: "unsupported method type: /grpctest.ItemService/ListItems is ServerStream",
+		},
+		{
+			scenario:      "missing request",
+			method:        grpcTestServiceGetItem,
+			path:          "resources/fixtures/unknown",
+			expectedError: "could not read golden request: open resources/fixtures/unknown.request.json: no such file or directory",
+		},
+		{
+			scenario:      "invalid response",
+			method:        grpcTestServiceGetItem,
+			path:          "resources/fixtures/get_item_invalid",
+			expectedError: "could not read golden response: could not decode resources/fixtures/get_item_invalid.response.json: proto:",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			s := grpcmock.NewUnstartedServer(grpcmock.RegisterService(grpctest.RegisterItemServiceServer))
+
+			err := s.ExpectFromGolden(tc.method, tc.path)
+
+			assert.ErrorContains(t, err, tc.expectedError)
+			assert.NoError(t, s.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestServer_MethodURL(t *testing.T) {
+	t.Parallel()
+
+	expect := func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithPayload(&grpctest.GetItemRequest{Id: 42}).
+			Return(&grpctest.Item{Id: 42, Name: "Foobar"})
+	}
+
+	testCases := []struct {
+		scenario    string
+		startServer func(t *testing.T) (*grpcmock.Server, []grpcmock.InvokeOption)
+		expectedURL func(s *grpcmock.Server) string
+	}{
+		{
+			scenario: "tcp",
+			startServer: func(t *testing.T) (*grpcmock.Server, []grpcmock.InvokeOption) {
+				t.Helper()
+
+				s := grpcmock.NewServer(grpcmock.RegisterService(grpctest.RegisterItemServiceServer), expect)
+
+				return s, []grpcmock.InvokeOption{grpcmock.WithInsecure()}
+			},
+			expectedURL: func(s *grpcmock.Server) string {
+				return s.Address() + "/grpctest.ItemService/GetItem"
+			},
+		},
+		{
+			scenario: "unix socket",
+			startServer: func(t *testing.T) (*grpcmock.Server, []grpcmock.InvokeOption) {
+				t.Helper()
+
+				s := grpcmock.NewServer(
+					grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+					grpcmock.WithUnixSocket(filepath.Join(t.TempDir(), "grpcmock.sock")),
+					expect,
+				)
+
+				return s, []grpcmock.InvokeOption{grpcmock.WithInsecure()}
+			},
+			expectedURL: func(s *grpcmock.Server) string {
+				return "unix://" + s.Address() + "/grpctest.ItemService/GetItem"
+			},
+		},
+		{
+			scenario: "bufconn",
+			startServer: func(t *testing.T) (*grpcmock.Server, []grpcmock.InvokeOption) {
+				t.Helper()
+
+				s, opts := grpcmock.NewInProcess(grpctest.RegisterItemServiceServer, grpcmock.ServerOption(expect))
+
+				return s, opts
+			},
+			expectedURL: func(*grpcmock.Server) string {
+				return "bufconn/grpctest.ItemService/GetItem"
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			s, opts := tc.startServer(t)
 
-	assert.Nil(t, actual)
-	assert.Error(t, err)
-	assert.Equal(t, status.Convert(err).Message(), expected)
-}
+			defer s.Close() // nolint: errcheck
 
-func TestServer_ExpectBidirectionalStream_Unexpected(t *testing.T) {
-	t.Parallel()
+			url := s.MethodURL("grpctest.ItemService", "GetItem")
 
-	_, d := mockItemServiceServer(t)
+			assert.Equal(t, tc.expectedURL(s), url)
 
-	actual, err := transformItems(d, &grpctest.Item{Id: 42})
+			out := &grpctest.Item{}
+			err := grpcmock.InvokeUnary(context.Background(), url, &grpctest.GetItemRequest{Id: 42}, out, opts...)
 
-	expectedError := `rpc error: code = FailedPrecondition desc = unexpected request received: "/grpctest.ItemService/TransformItems"`
+			expected := &grpctest.Item{Id: 42, Name: "Foobar"}
 
-	assert.Nil(t, actual)
-	assert.EqualError(t, err, expectedError)
+			assert.NoError(t, err)
+			grpcAssert.EqualMessage(t, expected, out)
+			assert.NoError(t, s.ExpectationsWereMet())
+		})
+	}
 }
 
-func TestServer_ExpectBidirectionalStream_Panic(t *testing.T) {
+func TestServer_WithMetadataEcho(t *testing.T) {
 	t.Parallel()
 
 	testCases := []struct {
 		scenario string
-		method   string
-		expected string
+		prefixes []string
+		expected metadata.MD
 	}{
 		{
-			scenario: "not found",
-			method:   "unknown",
-			expected: `method not found: /unknown`,
-		},
-		{
-			scenario: "unary",
-			method:   grpcTestServiceGetItem,
-			expected: `method is not bidirectional-stream: grpctest.ItemService/GetItem`,
+			scenario: "all",
+			expected: metadata.MD{"locale": {"en-US"}, "x-echo-id": {"42"}},
 		},
 		{
-			scenario: "client stream",
-			method:   grpcTestServiceCreateItems,
-			expected: `method is not bidirectional-stream: grpctest.ItemService/CreateItems`,
-		},
-		{
-			scenario: "server stream",
-			method:   grpcTestServiceListItems,
-			expected: `method is not bidirectional-stream: grpctest.ItemService/ListItems`,
+			scenario: "with prefix",
+			prefixes: []string{"X-Echo-"},
+			expected: metadata.MD{"x-echo-id": {"42"}},
 		},
 	}
 
@@ -536,159 +3174,358 @@ func TestServer_ExpectBidirectionalStream_Panic(t *testing.T) {
 		t.Run(tc.scenario, func(t *testing.T) {
 			t.Parallel()
 
-			assert.PanicsWithError(t, tc.expected, func() {
-				mockItemServiceServer(t, func(s *grpcmock.Server) {
-					s.ExpectBidirectionalStream(tc.method)
-				})
-			})
+			_, d := mockItemServiceServer(t,
+				grpcmock.WithMetadataEcho(tc.prefixes...),
+				func(s *grpcmock.Server) {
+					s.ExpectUnary(grpcTestServiceGetItem).
+						Return(&grpctest.Item{Id: 42})
+				},
+			)
+
+			var trailer metadata.MD
+
+			err := grpcmock.InvokeUnary(context.Background(), grpcTestServiceGetItem,
+				&grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+				grpcmock.WithHeader("locale", "en-US"),
+				grpcmock.WithHeader("x-echo-id", "42"),
+				grpcmock.WithTrailerCapture(&trailer),
+				grpcmock.WithContextDialer(d),
+				grpcmock.WithInsecure(),
+			)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, trailer)
 		})
 	}
 }
 
-func TestServer_ExpectBidirectionalStream_Success(t *testing.T) {
+func TestServer_HandleRequest_Concurrently(t *testing.T) {
 	t.Parallel()
 
-	_, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
-		s.ExpectBidirectionalStream(grpcTestServiceTransformItems).
-			WithHeader(`locale`, `en-US`).
-			Run(func(ctx context.Context, s grpc.ServerStream) error {
-				for {
-					item := &grpctest.Item{}
-					err := s.RecvMsg(item)
-
-					if errors.Is(err, io.EOF) {
-						return nil
-					}
+	const numCalls = 5
 
-					if err != nil {
-						return err
-					}
+	release := make(chan struct{})
 
-					item.Name = fmt.Sprintf("Modified #%d", item.Id)
+	s, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).
+			UnlimitedTimes().
+			Run(func(context.Context, interface{}) (interface{}, error) {
+				<-release
 
-					if err := s.SendMsg(item); err != nil {
-						return err
-					}
-				}
+				return &grpctest.Item{Id: 42}, nil
 			})
 	})
 
-	actual, err := transformItems(d, &grpctest.Item{Id: 40}, &grpctest.Item{Id: 41}, &grpctest.Item{Id: 42})
-	expected := []*grpctest.Item{
-		{Id: 40, Name: "Modified #40"},
-		{Id: 41, Name: "Modified #41"},
-		{Id: 42, Name: "Modified #42"},
-	}
+	var wg sync.WaitGroup
 
-	assert.NoError(t, err)
-	assert.Len(t, actual, len(expected))
+	for i := 0; i < numCalls; i++ {
+		wg.Add(1)
 
-	for i := 0; i < len(expected); i++ {
-		grpcAssert.EqualMessage(t, expected[i], actual[i])
+		go func() {
+			defer wg.Done()
+
+			actual, err := getItem(d, 42)
+
+			assert.NoError(t, err)
+			grpcAssert.EqualMessage(t, &grpctest.Item{Id: 42}, actual)
+		}()
 	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// All the requests are in-flight at the same time and the server can be inspected while they are being handled.
+	require.NoError(t, s.WaitForCalls(ctx, grpcTestServiceGetItem, numCalls))
+
+	s.AssertNumberOfCalls(t, grpcTestServiceGetItem, numCalls)
+	assert.NoError(t, s.ExpectationsWereMet())
+
+	close(release)
+	wg.Wait()
 }
 
-func TestServer_ExpectationsWereNotMet_LimitedRequest(t *testing.T) {
+func TestServer_Close_ShutdownTimeout(t *testing.T) {
 	t.Parallel()
 
-	s, d := mockItemServiceServer(grpcmock.NoOpT(),
-		func(s *grpcmock.Server) {
-			s.ExpectUnary(grpcTestServiceGetItem).Twice().
-				WithPayload(&grpctest.GetItemRequest{Id: 42}).
-				Return(&grpctest.Item{Id: 42})
+	buf := bufconn.Listen(1024 * 1024)
 
-			s.ExpectServerStream(grpcTestServiceListItems)
+	s := grpcmock.NewServer(
+		grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+		grpcmock.WithListener(buf),
+		grpcmock.WithShutdownTimeout(50*time.Millisecond),
+		func(s *grpcmock.Server) {
+			s.ExpectServerStream(grpcTestServiceListItems).
+				ReturnStream().
+				Send(&grpctest.Item{Id: 42}).
+				WaitFor(5 * time.Second)
 		},
 	)
 
-	// 1st request is ok.
-	_, err := getItem(d, 42)
+	received := make(chan struct{})
 
-	assert.NoError(t, err)
+	go func() {
+		_ = grpcmock.InvokeServerStream(context.Background(), // nolint: errcheck
+			grpcTestServiceListItems,
+			&grpctest.ListItemsRequest{},
+			func(s grpc.ClientStream) error {
+				if err := s.RecvMsg(&grpctest.Item{}); err != nil {
+					return err
+				}
 
-	expectedErr := `there are remaining expectations that were not met:
-- Unary /grpctest.ItemService/GetItem (called: 1 time(s), remaining: 1 time(s))
-    with payload using matcher.JSONMatcher
-        {"id":42}
-- ServerStream /grpctest.ItemService/ListItems
-`
-	assert.EqualError(t, s.ExpectationsWereMet(), expectedErr)
+				close(received)
 
-	// 2nd request is ok.
-	_, err = getItem(d, 42)
+				// Keep the stream open until the server is stopped.
+				return s.RecvMsg(&grpctest.Item{})
+			},
+			grpcmock.WithBufConnDialer(buf),
+			grpcmock.WithInsecure(),
+		)
+	}()
 
-	assert.NoError(t, err)
+	// Wait until the stream is in-flight.
+	<-received
 
-	expectedErr = `there are remaining expectations that were not met:
-- ServerStream /grpctest.ItemService/ListItems
-`
-	assert.EqualError(t, s.ExpectationsWereMet(), expectedErr)
+	start := time.Now()
+	err := s.Close()
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
 }
 
-func TestServer_ExpectationsWereNotMet_UnlimitedRequest(t *testing.T) {
+func TestServer_Close_ShutdownTimeout_UnixSocket(t *testing.T) {
 	t.Parallel()
 
-	s, d := mockItemServiceServer(grpcmock.NoOpT(),
-		func(s *grpcmock.Server) {
-			s.ExpectUnary(grpcTestServiceGetItem).UnlimitedTimes().
-				Return(&grpctest.Item{})
+	socket := filepath.Join(t.TempDir(), "grpcmock.sock")
 
-			s.ExpectServerStream(grpcTestServiceListItems)
+	s := grpcmock.NewServer(
+		grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
+		grpcmock.WithUnixSocket(socket),
+		grpcmock.WithShutdownTimeout(50*time.Millisecond),
+		func(s *grpcmock.Server) {
+			s.ExpectServerStream(grpcTestServiceListItems).
+				ReturnStream().
+				Send(&grpctest.Item{Id: 42}).
+				WaitFor(5 * time.Second)
 		},
 	)
 
-	// 1st request is ok.
-	_, err := getItem(d, 42)
+	received := make(chan struct{})
 
-	assert.NoError(t, err)
+	go func() {
+		_ = grpcmock.InvokeServerStream(context.Background(), // nolint: errcheck
+			fmt.Sprintf("unix://%s/%s", socket, grpcTestServiceListItems),
+			&grpctest.ListItemsRequest{},
+			func(s grpc.ClientStream) error {
+				if err := s.RecvMsg(&grpctest.Item{}); err != nil {
+					return err
+				}
 
-	expectedErr := `there are remaining expectations that were not met:
-- ServerStream /grpctest.ItemService/ListItems
-`
-	assert.EqualError(t, s.ExpectationsWereMet(), expectedErr)
+				close(received)
 
-	// 2nd request is ok.
-	_, err = getItem(d, 42)
+				// Keep the stream open until the server is stopped.
+				return s.RecvMsg(&grpctest.Item{})
+			},
+			grpcmock.WithInsecure(),
+		)
+	}()
+
+	// Wait until the stream is in-flight.
+	<-received
+
+	err := s.Close()
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The socket file is removed even if the server is forced to stop.
+	_, err = os.Stat(socket)
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestServer_RecordedCalls_Deadline(t *testing.T) {
+	t.Parallel()
+
+	s, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).Twice().
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	const timeout = 2 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	expectedDeadline, _ := ctx.Deadline()
+
+	err := grpcmock.InvokeUnary(ctx, grpcTestServiceGetItem, &grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
 
 	assert.NoError(t, err)
 
-	expectedErr = `there are remaining expectations that were not met:
-- ServerStream /grpctest.ItemService/ListItems
-`
-	assert.EqualError(t, s.ExpectationsWereMet(), expectedErr)
+	// Without deadline.
+	err = grpcmock.InvokeUnary(context.Background(), grpcTestServiceGetItem, &grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
+
+	assert.NoError(t, err)
+	assert.Len(t, s.Calls, 2)
+
+	assert.Equal(t, "/grpctest.ItemService/GetItem", s.Calls[0].Method)
+	assert.NotNil(t, s.Calls[0].Deadline)
+	assert.WithinDuration(t, expectedDeadline, *s.Calls[0].Deadline, 100*time.Millisecond)
+
+	assert.Equal(t, "/grpctest.ItemService/GetItem", s.Calls[1].Method)
+	assert.Nil(t, s.Calls[1].Deadline)
 }
 
-func TestServer_ExpectationsWereMet_UnlimitedRequest(t *testing.T) {
+func TestServer_RecordedCalls_Compressor(t *testing.T) {
 	t.Parallel()
 
-	s, d := mockItemServiceServer(grpcmock.NoOpT(),
-		func(s *grpcmock.Server) {
-			s.ExpectUnary(grpcTestServiceGetItem).UnlimitedTimes().
-				Return(&grpctest.Item{})
-		},
+	s, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).Twice().
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	err := grpcmock.InvokeUnary(context.Background(), grpcTestServiceGetItem, &grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+		grpcmock.WithGzip(),
 	)
 
-	_, err := getItem(d, 42)
+	assert.NoError(t, err)
+
+	// Without compression.
+	err = grpcmock.InvokeUnary(context.Background(), grpcTestServiceGetItem, &grpctest.GetItemRequest{Id: 42}, &grpctest.Item{},
+		grpcmock.WithContextDialer(d),
+		grpcmock.WithInsecure(),
+	)
 
 	assert.NoError(t, err)
+	assert.Len(t, s.Calls, 2)
+
+	assert.Equal(t, "gzip", s.Calls[0].Compressor)
+	assert.Empty(t, s.Calls[1].Compressor)
+}
+
+func TestServer_ConcurrentInvokes(t *testing.T) {
+	t.Parallel()
+
+	const numCalls = 50
+
+	s, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.WithPlanner(grpcPlanner.FirstMatch())
+
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithPayload(&grpctest.GetItemRequest{Id: 1}).
+			Times(numCalls).
+			Return(&grpctest.Item{Id: 1})
+
+		s.ExpectUnary(grpcTestServiceGetItem).
+			WithPayload(&grpctest.GetItemRequest{Id: 2}).
+			Times(numCalls).
+			Return(&grpctest.Item{Id: 2})
+	})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < numCalls; i++ {
+		for _, id := range []int32{1, 2} {
+			wg.Add(1)
+
+			go func(id int32) {
+				defer wg.Done()
+
+				actual, err := getItem(d, id)
+
+				assert.NoError(t, err)
+				grpcAssert.EqualMessage(t, &grpctest.Item{Id: id}, actual)
+
+				// Read the expectations while the other requests are being handled.
+				_ = s.ExpectationsWereMet() // nolint: errcheck
+			}(id)
+		}
+	}
+
+	wg.Wait()
+
 	assert.NoError(t, s.ExpectationsWereMet())
+	assert.True(t, s.AssertNumberOfCalls(t, grpcTestServiceGetItem, 2*numCalls))
+	assert.Len(t, s.Calls, 2*numCalls)
 }
 
-func TestServer_ResetExpectations(t *testing.T) {
+func TestServer_ResetRecordings(t *testing.T) {
 	t.Parallel()
 
-	s := grpcmock.MockUnstartedServer(
-		grpcmock.RegisterService(grpctest.RegisterItemServiceServer),
-		func(s *grpcmock.Server) {
-			s.ExpectUnary(grpcTestServiceGetItem).
-				WithPayload(&grpctest.GetItemRequest{Id: 1}).
-				Return(&grpctest.Item{Id: 1, Name: "Foobar"})
+	s, d := mockItemServiceServer(t, func(s *grpcmock.Server) {
+		s.ExpectUnary(grpcTestServiceGetItem).UnlimitedTimes().
+			Return(&grpctest.Item{Id: 42})
+	})
+
+	_, err := getItem(d, 42)
+
+	assert.NoError(t, err)
+	assert.Len(t, s.Requests, 1)
+
+	s.ResetRecordings()
+
+	assert.Empty(t, s.Requests)
+	assert.Empty(t, s.Calls)
+
+	// Expectations are kept.
+	_, err = getItem(d, 42)
+
+	assert.NoError(t, err)
+	assert.Len(t, s.Requests, 1)
+}
+
+func TestServer_Reset(t *testing.T) {
+	t.Parallel()
+
+	s, d := mockItemServiceServer(t)
+
+	testCases := []struct {
+		scenario string
+		id       int32
+	}{
+		{
+			scenario: "first",
+			id:       1,
 		},
-	)(t)
+		{
+			scenario: "second",
+			id:       2,
+		},
+	}
 
-	s.ResetExpectations()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			s.Reset()
 
-	assert.NoError(t, s.ExpectationsWereMet())
+			// Start from a clean slate.
+			assert.Empty(t, s.Requests)
+			assert.NoError(t, s.ExpectationsWereMet())
+
+			s.ExpectUnary(grpcTestServiceGetItem).
+				WithPayload(&grpctest.GetItemRequest{Id: tc.id}).
+				Return(&grpctest.Item{Id: tc.id})
+
+			// Leave an unmet expectation behind.
+			s.ExpectUnary(grpcTestServiceGetItem)
+
+			actual, err := getItem(d, tc.id)
+
+			grpcAssert.EqualMessage(t, &grpctest.Item{Id: tc.id}, actual)
+			assert.NoError(t, err)
+			assert.Len(t, s.Requests, 1)
+			s.AssertNumberOfCalls(t, grpcTestServiceGetItem, 1)
+		})
+	}
+
+	s.Reset()
 }
 
 func TestFindServerMethod(t *testing.T) {
@@ -728,6 +3565,56 @@ func TestFindServerMethod(t *testing.T) {
 	}
 }
 
+type fakeT struct {
+	grpcmock.T
+
+	errors []string
+}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+// recordingT records the errors and the cleanups, so they could be run on demand.
+type recordingT struct {
+	mu       sync.Mutex
+	errors   []string
+	cleanups []func()
+}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) FailNow() {}
+
+func (t *recordingT) Cleanup(fn func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cleanups = append(t.cleanups, fn)
+}
+
+func (t *recordingT) runCleanups() {
+	t.mu.Lock()
+	cleanups := t.cleanups
+	t.mu.Unlock()
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
+
+func (t *recordingT) recordedErrors() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]string(nil), t.errors...)
+}
+
 func mockItemServiceServer(t grpcmock.T, m ...grpcmock.ServerOption) (*grpcmock.Server, grpcmock.ContextDialer) {
 	opts := []grpcmock.ServerOption{grpcmock.RegisterService(grpctest.RegisterItemServiceServer)}
 	opts = append(opts, m...)
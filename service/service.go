@@ -2,6 +2,8 @@ package service
 
 import (
 	"fmt"
+
+	grpcReflect "github.com/nhatthm/grpcmock/reflect"
 )
 
 const (
@@ -32,6 +34,30 @@ func (m Method) FullName() string {
 	return fmt.Sprintf("/%s/%s", m.ServiceName, m.MethodName)
 }
 
+// NewInput returns a pointer to a new empty input of the method, for example a *grpctest.GetItemRequest. It returns nil
+// if the input type is not known.
+//
+//    in := method.NewInput().(*grpctest.GetItemRequest)
+func (m Method) NewInput() interface{} {
+	if m.Input == nil {
+		return nil
+	}
+
+	return grpcReflect.New(m.Input)
+}
+
+// NewOutput returns a pointer to a new empty output of the method, for example a *grpctest.Item. It returns nil if the
+// output type is not known.
+//
+//    out := method.NewOutput().(*grpctest.Item)
+func (m Method) NewOutput() interface{} {
+	if m.Output == nil {
+		return nil
+	}
+
+	return grpcReflect.New(m.Output)
+}
+
 // ToType defines the method type by checking if it's a client or server strean.
 func ToType(isClientStream, isServerStream bool) Type {
 	if isClientStream && isServerStream {
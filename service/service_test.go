@@ -4,8 +4,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/nhatthm/grpcmock/service"
+	"github.com/nhatthm/grpcmock/test/grpctest"
 )
 
 func TestServiceMethod_FullName(t *testing.T) {
@@ -22,6 +24,69 @@ func TestServiceMethod_FullName(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestServiceMethod_NewInputOutput(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario       string
+		method         service.Method
+		expectedInput  interface{}
+		expectedOutput interface{}
+	}{
+		{
+			scenario: "pointer prototypes",
+			method: service.Method{
+				Input:  &grpctest.GetItemRequest{Id: 42},
+				Output: &grpctest.Item{Id: 42},
+			},
+			expectedInput:  &grpctest.GetItemRequest{},
+			expectedOutput: &grpctest.Item{},
+		},
+		{
+			scenario: "value prototypes",
+			method: service.Method{
+				Input:  grpctest.ListItemsRequest{},
+				Output: grpctest.Item{},
+			},
+			expectedInput:  &grpctest.ListItemsRequest{},
+			expectedOutput: &grpctest.Item{},
+		},
+		{
+			scenario: "unknown types",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			in := tc.method.NewInput()
+			out := tc.method.NewOutput()
+
+			assert.IsType(t, tc.expectedInput, in)
+			assert.IsType(t, tc.expectedOutput, out)
+
+			if tc.expectedInput != nil {
+				assert.True(t, proto.Equal(tc.expectedInput.(proto.Message), in.(proto.Message))) // nolint: errcheck
+				assert.True(t, proto.Equal(tc.expectedOutput.(proto.Message), out.(proto.Message))) // nolint: errcheck
+			}
+		})
+	}
+}
+
+func TestServiceMethod_NewInput_Fresh(t *testing.T) {
+	t.Parallel()
+
+	m := service.Method{Input: &grpctest.GetItemRequest{}}
+
+	first := m.NewInput().(*grpctest.GetItemRequest) // nolint: errcheck
+	first.Id = 42
+
+	assert.Equal(t, int32(0), m.NewInput().(*grpctest.GetItemRequest).GetId()) // nolint: errcheck
+	assert.NotSame(t, m.Input, m.NewInput())
+}
+
 func TestToMethodType(t *testing.T) {
 	t.Parallel()
 
@@ -1,36 +1,45 @@
 package stream
 
+import (
+	"errors"
+	"io"
+)
+
 // SendReceiver is an interface wrapper around grpc.ClientStream and grpc.ServerStream.
 type SendReceiver interface {
 	Sender
 	Receiver
 }
 
-// SendAndRecvAll sends and receives messages until getting io.EOF.
+// SendAndRecvAll sends all the messages from the input and receives all the messages into the output concurrently. The
+// stream is closed for sending once all the messages are sent, and the messages are received until getting io.EOF.
+//
+// The first error of either direction is returned. When the stream is broken while sending, SendMsg() returns io.EOF
+// and the actual error is the one of the receiving side.
 func SendAndRecvAll(sr SendReceiver, in interface{}, out interface{}) error {
-	errCh := make(chan error)
+	recvErr := make(chan error, 1)
 
 	go func() {
-		defer close(errCh)
-
-		if err := RecvAll(sr, out); err != nil {
-			errCh <- err
+		recvErr <- RecvAll(sr, out)
+	}()
 
-			return
+	if err := sendAllAndClose(sr, in); err != nil {
+		if errors.Is(err, io.EOF) {
+			if rErr := <-recvErr; rErr != nil {
+				return rErr
+			}
 		}
-	}()
 
-	if err := SendAll(sr, in); err != nil {
 		return err
 	}
 
-	if err := CloseSend(sr); err != nil {
-		return err
-	}
+	return <-recvErr
+}
 
-	if err := <-errCh; err != nil {
+func sendAllAndClose(sr SendReceiver, in interface{}) error {
+	if err := SendAll(sr, in); err != nil {
 		return err
 	}
 
-	return nil
+	return CloseSend(sr)
 }
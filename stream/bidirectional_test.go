@@ -53,6 +53,42 @@ func TestSendAndRecvAll_RecvError(t *testing.T) {
 	assert.EqualError(t, err, expected)
 }
 
+func TestSendAndRecvAll_SendEOF_RecvError(t *testing.T) {
+	t.Parallel()
+
+	s := grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+		s.On("RecvMsg", mock.Anything).
+			Return(errors.New("recv error"))
+
+		s.On("SendMsg", mock.Anything).
+			Return(io.EOF)
+	})(t)
+
+	result := make([]*grpctest.Item, 0)
+	err := stream.SendAndRecvAll(s, []*grpctest.Item{{Id: 42}}, &result)
+
+	expected := "recv error"
+
+	assert.EqualError(t, err, expected)
+}
+
+func TestSendAndRecvAll_SendEOF_RecvSuccess(t *testing.T) {
+	t.Parallel()
+
+	s := grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+		s.On("RecvMsg", mock.Anything).
+			Return(io.EOF)
+
+		s.On("SendMsg", mock.Anything).
+			Return(io.EOF)
+	})(t)
+
+	result := make([]*grpctest.Item, 0)
+	err := stream.SendAndRecvAll(s, []*grpctest.Item{{Id: 42}}, &result)
+
+	assert.ErrorIs(t, err, io.EOF)
+}
+
 func TestSendAndRecvAll_CloseSendError(t *testing.T) {
 	t.Parallel()
 
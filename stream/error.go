@@ -1,11 +1,74 @@
 package stream
 
+import (
+	"errors"
+	"strings"
+)
+
 // ErrInvalidProtoMessage indicates that the object is not a proto message.
 const ErrInvalidProtoMessage err = "not a proto message"
 
+// ErrStreamTypeMismatch indicates that the type of the messages does not match the stream.
+const ErrStreamTypeMismatch err = "stream type mismatch"
+
+// ErrSendTypeMismatch indicates that the type of a message to send does not match the input of the method.
+const ErrSendTypeMismatch err = "send type mismatch"
+
+// ErrNilMessage indicates that the message to send is nil.
+const ErrNilMessage err = "message is nil"
+
 type err string
 
 // Error returns the error string.
 func (e err) Error() string {
 	return string(e)
 }
+
+// joinedError holds all the errors occurred while working with the stream.
+type joinedError struct {
+	errs []error
+}
+
+// Error returns the error string.
+func (e *joinedError) Error() string {
+	msgs := make([]string, 0, len(e.errs))
+
+	for _, err := range e.errs {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns all the errors.
+func (e *joinedError) Unwrap() []error {
+	return e.errs
+}
+
+// Is reports whether any of the errors matches the target.
+func (e *joinedError) Is(target error) bool {
+	for _, err := range e.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// joinErrors joins the errors, nil errors are discarded. It returns nil if there is no error.
+func joinErrors(errs ...error) error {
+	result := make([]error, 0, len(errs))
+
+	for _, err := range errs {
+		if err != nil {
+			result = append(result, err)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	return &joinedError{errs: result}
+}
@@ -1,16 +1,20 @@
 package stream_test
 
 import (
+	"context"
 	"errors"
 	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
 	grpcAssert "github.com/nhatthm/grpcmock/assert"
 	grpcMock "github.com/nhatthm/grpcmock/mock/grpc"
+	"github.com/nhatthm/grpcmock/service"
 	"github.com/nhatthm/grpcmock/stream"
 	"github.com/nhatthm/grpcmock/test"
 	"github.com/nhatthm/grpcmock/test/grpctest"
@@ -72,6 +76,24 @@ func TestRecvAll(t *testing.T) {
 			expectedError:  `recv error`,
 			expectedOutput: &[]grpctest.Item{},
 		},
+		{
+			scenario: "recv error after some messages",
+			mockStream: grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+				s.On("RecvMsg", &grpctest.Item{}).Once().
+					Run(func(args mock.Arguments) {
+						out := args.Get(0).(*grpctest.Item) // nolint: errcheck
+
+						out.Id = 41
+					}).
+					Return(nil)
+
+				s.On("RecvMsg", &grpctest.Item{}).
+					Return(errors.New("recv error"))
+			}),
+			output:         &[]*grpctest.Item{},
+			expectedError:  `recv error`,
+			expectedOutput: &[]*grpctest.Item{{Id: 41}},
+		},
 		{
 			scenario:   "success with a slice of struct",
 			mockStream: grpcMock.MockClientStream(sendItems),
@@ -126,3 +148,266 @@ func TestRecvAll(t *testing.T) {
 		})
 	}
 }
+
+func TestRecvAllWithContext(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		mockStream    func(cancel context.CancelFunc) grpcMock.ClientStreamMocker
+		expectedError error
+	}{
+		{
+			scenario: "context is done before receiving",
+			mockStream: func(cancel context.CancelFunc) grpcMock.ClientStreamMocker {
+				return grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+					s.On("RecvMsg", &grpctest.Item{}).Once().
+						Run(func(args mock.Arguments) {
+							args.Get(0).(*grpctest.Item).Id = 42 // nolint: errcheck
+
+							cancel()
+						}).
+						Return(nil)
+				})
+			},
+			expectedError: context.Canceled,
+		},
+		{
+			scenario: "context is done while receiving",
+			mockStream: func(cancel context.CancelFunc) grpcMock.ClientStreamMocker {
+				return grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+					s.On("RecvMsg", &grpctest.Item{}).Once().
+						Run(func(args mock.Arguments) {
+							args.Get(0).(*grpctest.Item).Id = 42 // nolint: errcheck
+						}).
+						Return(nil)
+
+					s.On("RecvMsg", &grpctest.Item{}).Once().
+						Run(func(mock.Arguments) {
+							cancel()
+						}).
+						Return(status.Error(codes.Canceled, "context canceled"))
+				})
+			},
+			expectedError: context.Canceled,
+		},
+		{
+			scenario: "stream error",
+			mockStream: func(cancel context.CancelFunc) grpcMock.ClientStreamMocker {
+				return grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+					s.On("RecvMsg", &grpctest.Item{}).Once().
+						Run(func(args mock.Arguments) {
+							args.Get(0).(*grpctest.Item).Id = 42 // nolint: errcheck
+						}).
+						Return(nil)
+
+					// The stream is cancelled when it ends with an error.
+					s.On("RecvMsg", &grpctest.Item{}).Once().
+						Run(func(mock.Arguments) {
+							cancel()
+						}).
+						Return(status.Error(codes.Aborted, "aborted"))
+				})
+			},
+			expectedError: status.Error(codes.Aborted, "aborted"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var out []*grpctest.Item
+
+			err := stream.RecvAllWithContext(ctx, tc.mockStream(cancel)(t), &out)
+
+			grpcAssert.JSONEq(t, []*grpctest.Item{{Id: 42}}, out)
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestRecvAllOf(t *testing.T) {
+	t.Parallel()
+
+	method := service.Method{
+		ServiceName: "grpctest.ItemService",
+		MethodName:  "ListItems",
+		MethodType:  service.TypeServerStream,
+		Input:       &grpctest.ListItemsRequest{},
+		Output:      &grpctest.Item{},
+	}
+
+	sendItem := func(s *grpcMock.ClientStream) {
+		s.On("RecvMsg", &grpctest.Item{}).Once().
+			Run(func(args mock.Arguments) {
+				out := args.Get(0).(*grpctest.Item) // nolint: errcheck
+
+				out.Id = 42
+			}).
+			Return(nil)
+
+		s.On("RecvMsg", &grpctest.Item{}).
+			Return(io.EOF)
+	}
+
+	testCases := []struct {
+		scenario       string
+		mockStream     grpcMock.ClientStreamMocker
+		method         service.Method
+		output         interface{}
+		expectedOutput interface{}
+		expectedError  string
+	}{
+		{
+			scenario:      "output is not a slice",
+			mockStream:    grpcMock.NoMockClientStream,
+			method:        method,
+			output:        &grpctest.Item{},
+			expectedError: `not a slice: *grpctest.Item`,
+		},
+		{
+			scenario:      "type mismatch",
+			mockStream:    grpcMock.NoMockClientStream,
+			method:        method,
+			output:        &[]*grpctest.GetItemRequest{},
+			expectedError: `stream type mismatch: output of /grpctest.ItemService/ListItems, got grpctest.GetItemRequest, want grpctest.Item`,
+		},
+		{
+			scenario:       "slice of struct",
+			mockStream:     grpcMock.MockClientStream(sendItem),
+			method:         method,
+			output:         &[]grpctest.Item{},
+			expectedOutput: &[]grpctest.Item{{Id: 42}},
+		},
+		{
+			scenario:       "slice of pointer",
+			mockStream:     grpcMock.MockClientStream(sendItem),
+			method:         method,
+			output:         &[]*grpctest.Item{},
+			expectedOutput: &[]*grpctest.Item{{Id: 42}},
+		},
+		{
+			scenario:       "no output type",
+			mockStream:     grpcMock.MockClientStream(sendItem),
+			method:         service.Method{ServiceName: "grpctest.ItemService", MethodName: "ListItems"},
+			output:         &[]*grpctest.Item{},
+			expectedOutput: &[]*grpctest.Item{{Id: 42}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			err := stream.RecvAllOf(tc.mockStream(t), tc.method, tc.output)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				grpcAssert.JSONEq(t, tc.expectedOutput, tc.output)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestRecvAllOf_TypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	method := service.Method{MethodName: "ListItems", Output: &grpctest.Item{}}
+	err := stream.RecvAllOf(grpcMock.NoMockClientStream(t), method, &[]grpctest.ListItemsRequest{})
+
+	assert.ErrorIs(t, err, stream.ErrStreamTypeMismatch)
+}
+
+func TestRecvAllT(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario       string
+		mockStream     grpcMock.ClientStreamMocker
+		expectedOutput []*grpctest.Item
+		expectedError  string
+	}{
+		{
+			scenario: "recv error",
+			mockStream: grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+				s.On("RecvMsg", mock.Anything).
+					Return(errors.New("recv error"))
+			}),
+			expectedOutput: []*grpctest.Item{},
+			expectedError:  `recv error`,
+		},
+		{
+			scenario: "recv error after some messages",
+			mockStream: grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+				s.On("RecvMsg", &grpctest.Item{}).Once().
+					Run(func(args mock.Arguments) {
+						out := args.Get(0).(*grpctest.Item) // nolint: errcheck
+
+						out.Id = 41
+					}).
+					Return(nil)
+
+				s.On("RecvMsg", &grpctest.Item{}).
+					Return(errors.New("recv error"))
+			}),
+			expectedOutput: []*grpctest.Item{{Id: 41}},
+			expectedError:  `recv error`,
+		},
+		{
+			scenario: "success",
+			mockStream: grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+				for _, i := range test.DefaultItems() {
+					i := i
+
+					s.On("RecvMsg", &grpctest.Item{}).Once().
+						Run(func(args mock.Arguments) {
+							out := args.Get(0).(*grpctest.Item) // nolint: errcheck
+
+							proto.Merge(out, i)
+						}).
+						Return(nil)
+				}
+
+				s.On("RecvMsg", &grpctest.Item{}).
+					Return(io.EOF)
+			}),
+			expectedOutput: []*grpctest.Item{
+				{
+					Id:     41,
+					Locale: "en-US",
+					Name:   "Item #41",
+				},
+				{
+					Id:     42,
+					Locale: "en-US",
+					Name:   "Item #42",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := stream.RecvAllT[grpctest.Item](tc.mockStream(t))
+
+			grpcAssert.JSONEq(t, tc.expectedOutput, result)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
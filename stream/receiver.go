@@ -1,11 +1,17 @@
 package stream
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"reflect"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	grpcReflect "github.com/nhatthm/grpcmock/reflect"
+	"github.com/nhatthm/grpcmock/service"
 )
 
 // Receiver is an interface wrapper around grpc.ClientStream and grpc.ServerStream.
@@ -13,8 +19,17 @@ type Receiver interface {
 	RecvMsg(m interface{}) error
 }
 
-// RecvAll reads all messages using a receiver until io.EOF.
+// RecvAll reads all messages using a receiver until io.EOF. If there is an error, the messages received before the
+// error are still put into the output.
 func RecvAll(r Receiver, out interface{}) error {
+	return RecvAllWithContext(context.Background(), r, out)
+}
+
+// RecvAllWithContext reads all messages using a receiver until io.EOF, like RecvAll(). It stops when the context is done
+// and returns the context error instead of the status error that the receiver reports for the cancellation.
+//
+//    err := stream.RecvAllWithContext(s.Context(), s, &items)
+func RecvAllWithContext(ctx context.Context, r Receiver, out interface{}) error {
 	outType, err := grpcReflect.UnwrapPtrSliceType(out)
 	if err != nil {
 		return err
@@ -22,18 +37,69 @@ func RecvAll(r Receiver, out interface{}) error {
 
 	newOut := reflect.MakeSlice(outType, 0, 0)
 
-	newOut, err = recvAllMessages(r, newOut, outType.Elem())
+	newOut, err = recvAllMessages(ctx, r, newOut, outType.Elem())
+
+	reflect.ValueOf(out).Elem().Set(newOut)
+
+	return err
+}
+
+// RecvAllOf reads all messages using a receiver until io.EOF, like RecvAll(). Before receiving, it checks that the
+// element type of the output matches the output type of the method, and returns ErrStreamTypeMismatch if it does not.
+// The check is skipped if the method does not declare its output type.
+//
+//    var items []*grpctest.Item
+//
+//    err := stream.RecvAllOf(s, method, &items)
+func RecvAllOf(r Receiver, method service.Method, out interface{}) error {
+	outType, err := grpcReflect.UnwrapPtrSliceType(out)
 	if err != nil {
 		return err
 	}
 
-	reflect.ValueOf(out).Elem().Set(newOut)
+	if method.Output != nil {
+		got, want := grpcReflect.UnwrapType(outType.Elem()), grpcReflect.UnwrapType(method.Output)
+
+		if got != want {
+			return fmt.Errorf("%w: output of %s, got %s, want %s", ErrStreamTypeMismatch, method.FullName(), got, want)
+		}
+	}
+
+	return RecvAll(r, out)
+}
+
+// RecvAllT reads all messages of type T using a receiver until io.EOF. Unlike RecvAll(), the output type is checked at
+// compile time. The messages are kept as *T because a proto message must not be copied. If there is an error, the
+// messages received before the error are still returned.
+//
+//    items, err := stream.RecvAllT[grpctest.Item](s)
+func RecvAllT[T any](r Receiver) ([]*T, error) {
+	out := make([]*T, 0)
+
+	for {
+		msg := new(T)
+		err := r.RecvMsg(msg)
 
-	return nil
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return out, err
+		}
+
+		out = append(out, msg)
+	}
+
+	return out, nil
 }
 
-func recvAllMessages(r Receiver, out reflect.Value, msgType reflect.Type) (reflect.Value, error) {
+func recvAllMessages(ctx context.Context, r Receiver, out reflect.Value, msgType reflect.Type) (reflect.Value, error) {
 	for {
+		if err := ctx.Err(); err != nil {
+			return out, err
+		}
+
 		msg := grpcReflect.New(msgType)
 		err := r.RecvMsg(msg)
 
@@ -42,7 +108,7 @@ func recvAllMessages(r Receiver, out reflect.Value, msgType reflect.Type) (refle
 		}
 
 		if err != nil {
-			return reflect.Value{}, err
+			return out, contextError(ctx, err)
 		}
 
 		out = appendMessage(out, msg)
@@ -63,6 +129,20 @@ func newSliceMessageValue(t reflect.Type, v reflect.Value) reflect.Value {
 	return result
 }
 
+// contextError returns the context error if the receiver fails because the context is done. The stream could also be
+// cancelled when it ends with an error, so the other errors are returned as is.
+func contextError(ctx context.Context, err error) error {
+	if code := status.Code(err); code != codes.Canceled && code != codes.DeadlineExceeded {
+		return err
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	return err
+}
+
 func appendMessage(s reflect.Value, v interface{}) reflect.Value {
 	return reflect.Append(s, newSliceMessageValue(s.Type().Elem(), grpcReflect.UnwrapValue(v)))
 }
@@ -1,10 +1,15 @@
 package stream
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"time"
+
+	"google.golang.org/protobuf/proto"
 
 	grpcReflect "github.com/nhatthm/grpcmock/reflect"
+	"github.com/nhatthm/grpcmock/service"
 )
 
 // Sender is an interface wrapper around grpc.ClientStream and grpc.ServerStream.
@@ -28,7 +33,10 @@ func SendAll(s Sender, in interface{}) error {
 	valueOf := reflect.ValueOf(in)
 
 	for i := 0; i < valueOf.Len(); i++ {
-		msg := grpcReflect.NewValue(valueOf.Index(i).Interface())
+		msg, err := newSendMessage(valueOf.Index(i).Interface())
+		if err != nil {
+			return fmt.Errorf("could not send message #%d: %w", i, err)
+		}
 
 		if err := s.SendMsg(msg); err != nil {
 			return err
@@ -38,6 +46,127 @@ func SendAll(s Sender, in interface{}) error {
 	return nil
 }
 
+// SendAllOf sends all the messages from a given input, like SendAll(). Before sending, it checks that every message
+// matches the input type of the method, and returns ErrSendTypeMismatch if one does not, so nothing is sent. The check
+// is skipped if the method does not declare its input type.
+//
+//    err := stream.SendAllOf(s, method, []*grpctest.Item{{Id: 41}, {Id: 42}})
+func SendAllOf(s Sender, method service.Method, in interface{}) error {
+	if !grpcReflect.IsSlice(in) {
+		return fmt.Errorf("%w: %T", grpcReflect.ErrIsNotSlice, in)
+	}
+
+	if method.Input != nil {
+		want := grpcReflect.UnwrapType(method.Input)
+		valueOf := reflect.ValueOf(in)
+
+		for i := 0; i < valueOf.Len(); i++ {
+			msg := valueOf.Index(i).Interface()
+
+			// A nil message is reported by SendAll().
+			if msg == nil {
+				continue
+			}
+
+			if got := grpcReflect.UnwrapType(msg); got != want {
+				return fmt.Errorf("%w: message #%d of %s, got %s, want %s", ErrSendTypeMismatch, i, method.FullName(), got, want)
+			}
+		}
+	}
+
+	return SendAll(s, in)
+}
+
+// SendAllStrict sends all the messages from a given input. Unlike SendAll(), it does not stop at the first error but
+// tries to send all the messages, and then returns all the errors.
+func SendAllStrict(s Sender, in interface{}) error {
+	if !grpcReflect.IsSlice(in) {
+		return fmt.Errorf("%w: %T", grpcReflect.ErrIsNotSlice, in)
+	}
+
+	valueOf := reflect.ValueOf(in)
+	errs := make([]error, 0)
+
+	for i := 0; i < valueOf.Len(); i++ {
+		msg, err := newSendMessage(valueOf.Index(i).Interface())
+		if err == nil {
+			err = s.SendMsg(msg)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not send message #%d: %w", i, err))
+		}
+	}
+
+	return joinErrors(errs...)
+}
+
+// SendAllT sends all the messages of type T. Unlike SendAll(), the input type is checked at compile time. If T is not a
+// proto.Message, a pointer to each element is sent, so both []grpctest.Item and []*grpctest.Item work.
+//
+//    err := stream.SendAllT(s, []*grpctest.Item{{Id: 42}})
+func SendAllT[T any](s Sender, in []T) error {
+	for i := range in {
+		var msg interface{} = &in[i]
+
+		if m, ok := interface{}(in[i]).(proto.Message); ok {
+			msg = m
+		}
+
+		if err := s.SendMsg(msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SendAllWithDelay sends all the messages from a given input and waits for a duration between each message. It stops
+// when the context is done.
+func SendAllWithDelay(ctx context.Context, s Sender, in interface{}, delay time.Duration) error {
+	if !grpcReflect.IsSlice(in) {
+		return fmt.Errorf("%w: %T", grpcReflect.ErrIsNotSlice, in)
+	}
+
+	valueOf := reflect.ValueOf(in)
+
+	for i := 0; i < valueOf.Len(); i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+
+			case <-time.After(delay):
+			}
+		}
+
+		msg, err := newSendMessage(valueOf.Index(i).Interface())
+		if err != nil {
+			return fmt.Errorf("could not send message #%d: %w", i, err)
+		}
+
+		if err := s.SendMsg(msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newSendMessage prepares an element of the input for sending. The proto messages, including the elements of a
+// []proto.Message, are sent as is, the other values are copied to a new pointer.
+func newSendMessage(v interface{}) (interface{}, error) {
+	if grpcReflect.IsNil(v) {
+		return nil, ErrNilMessage
+	}
+
+	if msg, ok := v.(proto.Message); ok {
+		return msg, nil
+	}
+
+	return grpcReflect.NewValue(v), nil
+}
+
 // CloseSend closes the send direction of the stream.
 func CloseSend(s Sender) error {
 	if s, ok := s.(SendCloser); ok {
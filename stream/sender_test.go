@@ -1,13 +1,17 @@
 package stream_test
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/proto"
 
 	grpcMock "github.com/nhatthm/grpcmock/mock/grpc"
+	"github.com/nhatthm/grpcmock/service"
 	"github.com/nhatthm/grpcmock/stream"
 	"github.com/nhatthm/grpcmock/test"
 	"github.com/nhatthm/grpcmock/test/grpctest"
@@ -52,6 +56,37 @@ func TestSendAll(t *testing.T) {
 			}),
 			input: test.DefaultItems(),
 		},
+		{
+			scenario: "success with a slice of proto.Message",
+			mockStream: grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+				s.On("SendMsg", &grpctest.Item{Id: 1}).Once().
+					Return(nil)
+
+				s.On("SendMsg", &grpctest.Item{Id: 2}).Once().
+					Return(nil)
+			}),
+			input: []proto.Message{&grpctest.Item{Id: 1}, &grpctest.Item{Id: 2}},
+		},
+		{
+			scenario: "success with a slice of mixed values and pointers",
+			mockStream: grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+				s.On("SendMsg", &grpctest.Item{Id: 1}).Once().
+					Return(nil)
+
+				s.On("SendMsg", &grpctest.Item{Id: 2}).Once().
+					Return(nil)
+			}),
+			input: []interface{}{grpctest.Item{Id: 1}, &grpctest.Item{Id: 2}},
+		},
+		{
+			scenario: "nil message",
+			mockStream: grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+				s.On("SendMsg", &grpctest.Item{Id: 1}).Once().
+					Return(nil)
+			}),
+			input:         []proto.Message{&grpctest.Item{Id: 1}, (*grpctest.Item)(nil)},
+			expectedError: `could not send message #1: message is nil`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -69,3 +104,295 @@ func TestSendAll(t *testing.T) {
 		})
 	}
 }
+
+func TestSendAll_ProtoMessageIsNotCopied(t *testing.T) {
+	t.Parallel()
+
+	item := &grpctest.Item{Id: 42}
+
+	s := grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+		s.On("SendMsg", mock.MatchedBy(func(msg interface{}) bool {
+			return msg == item
+		})).Once().
+			Return(nil)
+	})(t)
+
+	err := stream.SendAll(s, []proto.Message{item})
+
+	assert.NoError(t, err)
+}
+
+func TestSendAllOf(t *testing.T) {
+	t.Parallel()
+
+	method := service.Method{
+		ServiceName: "grpctest.ItemService",
+		MethodName:  "CreateItems",
+		MethodType:  service.TypeClientStream,
+		Input:       &grpctest.Item{},
+		Output:      &grpctest.CreateItemsResponse{},
+	}
+
+	sendItems := func(s *grpcMock.ClientStream) {
+		s.On("SendMsg", &grpctest.Item{Id: 1}).Once().
+			Return(nil)
+
+		s.On("SendMsg", &grpctest.Item{Id: 2}).Once().
+			Return(nil)
+	}
+
+	testCases := []struct {
+		scenario      string
+		mockStream    grpcMock.ClientStreamMocker
+		method        service.Method
+		input         interface{}
+		expectedError string
+	}{
+		{
+			scenario:      "input is not a slice",
+			mockStream:    grpcMock.NoMockClientStream,
+			method:        method,
+			input:         &grpctest.Item{},
+			expectedError: `not a slice: *grpctest.Item`,
+		},
+		{
+			scenario:      "type mismatch",
+			mockStream:    grpcMock.NoMockClientStream,
+			method:        method,
+			input:         []*grpctest.GetItemRequest{{Id: 1}},
+			expectedError: `send type mismatch: message #0 of /grpctest.ItemService/CreateItems, got grpctest.GetItemRequest, want grpctest.Item`,
+		},
+		{
+			scenario:      "one of the messages mismatches",
+			mockStream:    grpcMock.NoMockClientStream,
+			method:        method,
+			input:         []proto.Message{&grpctest.Item{Id: 1}, &grpctest.GetItemRequest{Id: 2}},
+			expectedError: `send type mismatch: message #1 of /grpctest.ItemService/CreateItems, got grpctest.GetItemRequest, want grpctest.Item`,
+		},
+		{
+			scenario:   "slice of pointer",
+			mockStream: grpcMock.MockClientStream(sendItems),
+			method:     method,
+			input:      []*grpctest.Item{{Id: 1}, {Id: 2}},
+		},
+		{
+			scenario:   "slice of mixed values and pointers",
+			mockStream: grpcMock.MockClientStream(sendItems),
+			method:     method,
+			input:      []interface{}{grpctest.Item{Id: 1}, &grpctest.Item{Id: 2}},
+		},
+		{
+			scenario:   "no input type",
+			mockStream: grpcMock.MockClientStream(sendItems),
+			method:     service.Method{ServiceName: "grpctest.ItemService", MethodName: "CreateItems"},
+			input:      []*grpctest.Item{{Id: 1}, {Id: 2}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			err := stream.SendAllOf(tc.mockStream(t), tc.method, tc.input)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestSendAllOf_TypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	method := service.Method{MethodName: "CreateItems", Input: &grpctest.Item{}}
+	err := stream.SendAllOf(grpcMock.NoMockClientStream(t), method, []grpctest.GetItemRequest{{Id: 1}})
+
+	assert.ErrorIs(t, err, stream.ErrSendTypeMismatch)
+}
+
+func TestSendAllStrict(t *testing.T) {
+	t.Parallel()
+
+	t.Run("input is not a slice", func(t *testing.T) {
+		t.Parallel()
+
+		err := stream.SendAllStrict(grpcMock.NoMockClientStream(t), &grpctest.Item{})
+
+		assert.EqualError(t, err, `not a slice: *grpctest.Item`)
+	})
+
+	t.Run("second send fails", func(t *testing.T) {
+		t.Parallel()
+
+		sendErr := errors.New("send error")
+
+		s := grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+			s.On("SendMsg", &grpctest.Item{Id: 1}).Once().
+				Return(nil)
+
+			s.On("SendMsg", &grpctest.Item{Id: 2}).Once().
+				Return(sendErr)
+
+			s.On("SendMsg", &grpctest.Item{Id: 3}).Once().
+				Return(nil)
+		})(t)
+
+		err := stream.SendAllStrict(s, []*grpctest.Item{{Id: 1}, {Id: 2}, {Id: 3}})
+
+		assert.EqualError(t, err, `could not send message #1: send error`)
+		assert.ErrorIs(t, err, sendErr)
+	})
+
+	t.Run("all sends fail", func(t *testing.T) {
+		t.Parallel()
+
+		s := grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+			s.On("SendMsg", mock.Anything).Twice().
+				Return(errors.New("send error"))
+		})(t)
+
+		err := stream.SendAllStrict(s, []*grpctest.Item{{Id: 1}, {Id: 2}})
+
+		expected := "could not send message #0: send error\ncould not send message #1: send error"
+
+		assert.EqualError(t, err, expected)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		s := grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+			for _, i := range test.DefaultItems() {
+				s.On("SendMsg", i).Once().
+					Return(nil)
+			}
+		})(t)
+
+		err := stream.SendAllStrict(s, test.DefaultItems())
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestSendAllT(t *testing.T) {
+	t.Parallel()
+
+	t.Run("send error", func(t *testing.T) {
+		t.Parallel()
+
+		s := grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+			s.On("SendMsg", mock.Anything).
+				Return(errors.New("send error"))
+		})(t)
+
+		err := stream.SendAllT(s, test.DefaultItems())
+
+		assert.EqualError(t, err, `send error`)
+	})
+
+	t.Run("success with a slice of pointer", func(t *testing.T) {
+		t.Parallel()
+
+		s := grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+			for _, i := range test.DefaultItems() {
+				s.On("SendMsg", i).Once().
+					Return(nil)
+			}
+		})(t)
+
+		err := stream.SendAllT(s, test.DefaultItems())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("success with a slice of struct", func(t *testing.T) {
+		t.Parallel()
+
+		s := grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+			s.On("SendMsg", &grpctest.Item{Id: 41}).Once().
+				Return(nil)
+
+			s.On("SendMsg", &grpctest.Item{Id: 42}).Once().
+				Return(nil)
+		})(t)
+
+		err := stream.SendAllT(s, []grpctest.Item{{Id: 41}, {Id: 42}})
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestSendAllWithDelay(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario      string
+		mockStream    grpcMock.ClientStreamMocker
+		input         interface{}
+		expectedError string
+	}{
+		{
+			scenario:      "input is not a slice",
+			mockStream:    grpcMock.NoMockClientStream,
+			input:         &grpctest.Item{},
+			expectedError: `not a slice: *grpctest.Item`,
+		},
+		{
+			scenario: "send error",
+			mockStream: grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+				s.On("SendMsg", mock.Anything).
+					Return(errors.New("send error"))
+			}),
+			input:         test.DefaultItems(),
+			expectedError: `send error`,
+		},
+		{
+			scenario: "success",
+			mockStream: grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+				for _, i := range test.DefaultItems() {
+					s.On("SendMsg", i).Once().
+						Return(nil)
+				}
+			}),
+			input: test.DefaultItems(),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			err := stream.SendAllWithDelay(context.Background(), tc.mockStream(t), tc.input, time.Millisecond)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestSendAllWithDelay_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	items := test.DefaultItems()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := grpcMock.MockClientStream(func(s *grpcMock.ClientStream) {
+		s.On("SendMsg", items[0]).Once().
+			Run(func(mock.Arguments) {
+				cancel()
+			}).
+			Return(nil)
+	})(t)
+
+	err := stream.SendAllWithDelay(ctx, s, items, time.Hour)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}